@@ -17,21 +17,22 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"sync"
 	"syscall"
-	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/transparency-dev/tessera"
 	taws "github.com/transparency-dev/tessera/storage/aws"
 	aws_as "github.com/transparency-dev/tessera/storage/aws/antispam"
 	"github.com/transparency-dev/tesseract"
+	"github.com/transparency-dev/tesseract/internal/config"
+	"github.com/transparency-dev/tesseract/internal/signer"
 	"github.com/transparency-dev/tesseract/storage"
 	"github.com/transparency-dev/tesseract/storage/aws"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -39,48 +40,7 @@ import (
 	"k8s.io/klog/v2"
 )
 
-func init() {
-	flag.Var(&notAfterStart, "not_after_start", "Start of the range of acceptable NotAfter values, inclusive. Leaving this unset or empty implies no lower bound to the range. RFC3339 UTC format, e.g: 2024-01-02T15:04:05Z.")
-	flag.Var(&notAfterLimit, "not_after_limit", "Cut off point of notAfter dates - only notAfter dates strictly *before* notAfterLimit will be accepted. Leaving this unset or empty means no upper bound on the accepted range. RFC3339 UTC format, e.g: 2024-01-02T15:04:05Z.")
-}
-
-// Global flags that affect all log instances.
-var (
-	notAfterStart timestampFlag
-	notAfterLimit timestampFlag
-
-	// Functionality flags
-	httpEndpoint             = flag.String("http_endpoint", "localhost:6962", "Endpoint for HTTP (host:port).")
-	maskInternalErrors       = flag.Bool("mask_internal_errors", false, "Don't return error strings with Internal Server Error HTTP responses.")
-	origin                   = flag.String("origin", "", "Origin of the log, for checkpoints and the monitoring prefix.")
-	rootsPemFile             = flag.String("roots_pem_file", "", "Path to the file containing root certificates that are acceptable to the log. The certs are served through get-roots endpoint.")
-	rejectExpired            = flag.Bool("reject_expired", false, "If true then the certificate validity period will be checked against the current time during the validation of submissions. This will cause expired certificates to be rejected.")
-	rejectUnexpired          = flag.Bool("reject_unexpired", false, "If true then TesseraCT rejects certificates that are either currently valid or not yet valid.")
-	extKeyUsages             = flag.String("ext_key_usages", "", "If set, will restrict the set of such usages that the server will accept. By default all are accepted. The values specified must be ones known to the x509 package.")
-	rejectExtensions         = flag.String("reject_extension", "", "A list of X.509 extension OIDs, in dotted string form (e.g. '2.3.4.5') which, if present, should cause submissions to be rejected.")
-	enablePublicationAwaiter = flag.Bool("enable_publication_awaiter", false, "If true then the certificate is integrated into log before returning the response.")
-
-	// Performance flags
-	httpDeadline              = flag.Duration("http_deadline", time.Second*10, "Deadline for HTTP requests.")
-	inMemoryAntispamCacheSize = flag.Uint("inmemory_antispam_cache_size", 256<<10, "Maximum number of entries to keep in the in-memory antispam cache.")
-	checkpointInterval        = flag.Duration("checkpoint_interval", 1500*time.Millisecond, "Interval between checkpoint publishing")
-	batchMaxSize              = flag.Uint("batch_max_size", tessera.DefaultBatchMaxSize, "Maximum number of entries to process in a single Tessera sequencing batch.")
-	batchMaxAge               = flag.Duration("batch_max_age", tessera.DefaultBatchMaxAge, "Maximum age of entries in a single Tessera sequencing batch.")
-	pushbackMaxOutstanding    = flag.Uint("pushback_max_outstanding", tessera.DefaultPushbackMaxOutstanding, "Maximum number of number of in-flight add requests - i.e. the number of entries with sequence numbers assigned, but which are not yet integrated into the log.")
-
-	// Infrastructure setup flags
-	bucket                     = flag.String("bucket", "", "Name of the S3 bucket to store the log in.")
-	dbName                     = flag.String("db_name", "", "AuroraDB name")
-	antispamDBName             = flag.String("antispam_db_name", "", "AuroraDB antispam name")
-	dbHost                     = flag.String("db_host", "", "AuroraDB host")
-	dbPort                     = flag.Int("db_port", 3306, "AuroraDB port")
-	dbUser                     = flag.String("db_user", "", "AuroraDB user")
-	dbPassword                 = flag.String("db_password", "", "AuroraDB password")
-	dbMaxConns                 = flag.Int("db_max_conns", 0, "Maximum connections to the database, defaults to 0, i.e unlimited")
-	dbMaxIdle                  = flag.Int("db_max_idle_conns", 2, "Maximum idle database connections in the connection pool, defaults to 2")
-	signerPublicKeySecretName  = flag.String("signer_public_key_secret_name", "", "Public key secret name for checkpoints and SCTs signer")
-	signerPrivateKeySecretName = flag.String("signer_private_key_secret_name", "", "Private key secret name for checkpoints and SCTs signer")
-)
+var flags = config.RegisterFlags(flag.CommandLine, config.AWS)
 
 // nolint:staticcheck
 func main() {
@@ -88,22 +48,45 @@ func main() {
 	flag.Parse()
 	ctx := context.Background()
 
-	signer, err := NewSecretsManagerSigner(ctx, *signerPublicKeySecretName, *signerPrivateKeySecretName)
+	cfg, err := flags.Config()
 	if err != nil {
-		klog.Exitf("Can't create AWS Secrets Manager signer: %v", err)
+		klog.Exitf("Can't load config: %v", err)
 	}
-
-	chainValidationConfig := tesseract.ChainValidationConfig{
-		RootsPEMFile:     *rootsPemFile,
-		RejectExpired:    *rejectExpired,
-		RejectUnexpired:  *rejectUnexpired,
-		ExtKeyUsages:     *extKeyUsages,
-		RejectExtensions: *rejectExtensions,
-		NotAfterStart:    notAfterStart.t,
-		NotAfterLimit:    notAfterLimit.t,
+	if flags.PrintEffectiveConfig() {
+		printConfig(cfg)
+		return
 	}
 
-	logHandler, err := tesseract.NewLogHandler(ctx, *origin, signer, chainValidationConfig, newAWSStorage, *httpDeadline, *maskInternalErrors)
+	logs := make([]tesseract.LogConfig, 0, len(cfg.Logs))
+	for _, l := range cfg.Logs {
+		signer, err := newSigner(ctx, l.Signer)
+		if err != nil {
+			klog.Exitf("Can't create signer for log %q: %v", l.Origin, err)
+		}
+		logs = append(logs, tesseract.LogConfig{
+			Origin:                l.Origin,
+			Signer:                signer,
+			DeterministicSCTs:     l.DeterministicSCTs,
+			ChainValidationConfig: l.ChainValidationConfig(),
+			CreateStorage:         newAWSStorage(l),
+			CheckpointInterval:    l.Tessera.CheckpointInterval,
+		})
+	}
+
+	hOpts := cfg.LogHandlerOpts()
+	if rlc := cfg.RequestLogConfig(); rlc != nil {
+		rl, closeRequestLog, err := tesseract.NewJSONRequestLog(*rlc)
+		if err != nil {
+			klog.Exitf("Failed to create request log: %v", err)
+		}
+		defer func() {
+			if err := closeRequestLog(); err != nil {
+				klog.Warningf("Failed to close request log: %v", err)
+			}
+		}()
+		hOpts.RequestLog = rl
+	}
+	logHandler, shutdownLogs, err := tesseract.NewLogsHandler(ctx, logs, hOpts)
 	if err != nil {
 		klog.Exitf("Can't initialize CT HTTP Server: %v", err)
 	}
@@ -113,20 +96,25 @@ func main() {
 	http.Handle("/", otelhttp.NewHandler(logHandler, "/"))
 
 	// Bring up the HTTP server and serve until we get a signal not to.
-	srv := http.Server{Addr: *httpEndpoint}
+	srv := http.Server{Addr: cfg.HTTPEndpoint}
 	shutdownWG := new(sync.WaitGroup)
 	shutdownWG.Add(1)
 	go awaitSignal(func() {
 		defer shutdownWG.Done()
-		// Allow 60s for any pending requests to finish then terminate any stragglers
-		// TODO(phboneff): maybe wait for the sequencer queue to be empty?
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
+		// Allow pending requests to finish, then wait for the entries they
+		// added to be integrated and published, or terminate any stragglers.
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer cancel()
 		klog.Info("Shutting down HTTP server...")
 		if err := srv.Shutdown(ctx); err != nil {
 			klog.Errorf("srv.Shutdown(): %v", err)
 		}
 		klog.Info("HTTP server shutdown")
+		klog.Info("Shutting down logs...")
+		if err := shutdownLogs(ctx); err != nil {
+			klog.Errorf("Failed to shut logs down cleanly: %v", err)
+		}
+		klog.Info("Logs shutdown")
 	})
 
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
@@ -153,132 +141,97 @@ func awaitSignal(doneFn func()) {
 	doneFn()
 }
 
-func newAWSStorage(ctx context.Context, signer note.Signer) (*storage.CTStorage, error) {
-	awsCfg := storageConfigFromFlags()
-	driver, err := taws.New(ctx, awsCfg)
+// newSigner creates the signer configured by s: from secrets, a local key
+// file, or a PKCS #11 token.
+func newSigner(ctx context.Context, s config.Signer) (crypto.Signer, error) {
+	switch {
+	case s.PKCS11 != nil:
+		return signer.NewPKCS11Signer(s.PKCS11.PKCS11Config())
+	case s.PrivateKeyFile != "":
+		return signer.NewFileSigner(s.PrivateKeyFile, s.PublicKeyFile)
+	default:
+		return NewSecretsManagerSigner(ctx, s.PublicKeySecretName, s.PrivateKeySecretName)
+	}
+}
+
+// printConfig prints cfg to stdout, with secrets redacted.
+func printConfig(cfg *config.Config) {
+	b, err := cfg.Redacted().Marshal()
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize AWS Tessera storage driver: %v", err)
+		klog.Exitf("Can't print config: %v", err)
 	}
+	fmt.Print(string(b))
+}
 
-	var antispam tessera.Antispam
-	if *antispamDBName != "" {
-		antispam, err = aws_as.NewAntispam(ctx, antispamMySQLConfig().FormatDSN(), aws_as.AntispamOpts{})
+func newAWSStorage(l config.Log) storage.CreateStorage {
+	return func(ctx context.Context, signer note.Signer) (*storage.CTStorage, error) {
+		s := l.Storage.AWS
+		driver, err := taws.New(ctx, storageConfig(s))
 		if err != nil {
-			klog.Exitf("Failed to create new AWS antispam storage: %v", err)
+			return nil, fmt.Errorf("failed to initialize AWS Tessera storage driver: %v", err)
 		}
-	}
 
-	opts := tessera.NewAppendOptions().
-		WithCheckpointSigner(signer).
-		WithCTLayout().
-		WithAntispam(*inMemoryAntispamCacheSize, antispam).
-		WithCheckpointInterval(*checkpointInterval).
-		WithBatching(*batchMaxSize, *batchMaxAge).
-		WithPushback(*pushbackMaxOutstanding)
+		var antispam tessera.Antispam
+		if s.AntispamDBName != "" {
+			antispam, err = aws_as.NewAntispam(ctx, mySQLConfig(s, s.AntispamDBName).FormatDSN(), aws_as.AntispamOpts{})
+			if err != nil {
+				klog.Exitf("Failed to create new AWS antispam storage: %v", err)
+			}
+		}
 
-	appender, _, reader, err := tessera.NewAppender(ctx, driver, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize AWS Tessera storage: %v", err)
-	}
+		opts := tessera.NewAppendOptions().
+			WithCheckpointSigner(signer).
+			WithCTLayout().
+			WithAntispam(l.Tessera.InMemoryAntispamCacheSize, antispam).
+			WithCheckpointInterval(l.Tessera.CheckpointInterval).
+			WithBatching(l.Tessera.BatchMaxSize, l.Tessera.BatchMaxAge).
+			WithPushback(l.Tessera.PushbackMaxOutstanding)
 
-	issuerStorage, err := aws.NewIssuerStorage(ctx, *bucket, "fingerprints/", "application/pkix-cert")
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize AWS issuer storage: %v", err)
-	}
+		appender, shutdown, reader, err := tessera.NewAppender(ctx, driver, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize AWS Tessera storage: %v", err)
+		}
 
-	return storage.NewCTStorage(ctx, appender, issuerStorage, reader, *enablePublicationAwaiter)
-}
+		issuerStorage, err := aws.NewIssuerStorage(ctx, s.Bucket, "fingerprints/", "application/pkix-cert")
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize AWS issuer storage: %v", err)
+		}
 
-type timestampFlag struct {
-	t *time.Time
-}
+		ctStorage, err := storage.NewCTStorage(ctx, appender, shutdown, issuerStorage, reader, l.Tessera.EnablePublicationAwaiter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CT storage: %v", err)
+		}
+		if err := ctStorage.SetTimestampCacheSize(int(l.TimestampCacheSize)); err != nil {
+			return nil, fmt.Errorf("failed to set timestamp cache size: %v", err)
+		}
 
-func (t *timestampFlag) String() string {
-	if t.t != nil {
-		return t.t.Format(time.RFC3339)
-	}
-	return ""
-}
+		if l.LeafIndexDBPath != "" {
+			if err := ctStorage.FollowLeafIndex(ctx, l.LeafIndexDBPath); err != nil {
+				return nil, fmt.Errorf("failed to initialize leaf index: %v", err)
+			}
+		}
 
-func (t *timestampFlag) Set(w string) error {
-	if w == "" {
-		return nil
-	} else if !strings.HasSuffix(w, "Z") {
-		return fmt.Errorf("timestamps MUST be in UTC, got %v", w)
+		return ctStorage, nil
 	}
-	tt, err := time.Parse(time.RFC3339, w)
-	if err != nil {
-		return fmt.Errorf("can't parse %q as RFC3339 timestamp: %v", w, err)
-	}
-	t.t = &tt
-	return nil
 }
 
-// storageConfigFromFlags returns an aws.Config struct populated with values
-// provided via flags.
-func storageConfigFromFlags() taws.Config {
-	if *bucket == "" {
-		klog.Exit("--bucket must be set")
-	}
-	if *dbName == "" {
-		klog.Exit("--db_name must be set")
-	}
-	if *dbHost == "" {
-		klog.Exit("--db_host must be set")
-	}
-	if *dbPort == 0 {
-		klog.Exit("--db_port must be set")
-	}
-	if *dbUser == "" {
-		klog.Exit("--db_user must be set")
-	}
-	// Empty password isn't an option with AuroraDB MySQL.
-	if *dbPassword == "" {
-		klog.Exit("--db_password must be set")
-	}
-
-	c := mysql.Config{
-		User:                    *dbUser,
-		Passwd:                  *dbPassword,
-		Net:                     "tcp",
-		Addr:                    fmt.Sprintf("%s:%d", *dbHost, *dbPort),
-		DBName:                  *dbName,
-		AllowCleartextPasswords: true,
-		AllowNativePasswords:    true,
-	}
-
+func storageConfig(s *config.AWSStorage) taws.Config {
 	return taws.Config{
-		Bucket:       *bucket,
-		DSN:          c.FormatDSN(),
-		MaxOpenConns: *dbMaxConns,
-		MaxIdleConns: *dbMaxIdle,
+		Bucket:       s.Bucket,
+		DSN:          mySQLConfig(s, s.DBName).FormatDSN(),
+		MaxOpenConns: s.DBMaxConns,
+		MaxIdleConns: s.DBMaxIdleConns,
 	}
 }
 
-func antispamMySQLConfig() *mysql.Config {
-	if *antispamDBName == "" {
-		klog.Exit("--antispam_db_name must be set")
-	}
-	if *dbHost == "" {
-		klog.Exit("--db_host must be set")
-	}
-	if *dbPort == 0 {
-		klog.Exit("--db_port must be set")
-	}
-	if *dbUser == "" {
-		klog.Exit("--db_user must be set")
-	}
-	// Empty passord isn't an option with AuroraDB MySQL.
-	if *dbPassword == "" {
-		klog.Exit("--db_password must be set")
-	}
-
+// mySQLConfig returns the config to connect to the AuroraDB database dbName.
+func mySQLConfig(s *config.AWSStorage, dbName string) *mysql.Config {
 	return &mysql.Config{
-		User:                    *dbUser,
-		Passwd:                  *dbPassword,
+		User:                    s.DBUser,
+		Passwd:                  s.DBPassword,
 		Net:                     "tcp",
-		Addr:                    fmt.Sprintf("%s:%d", *dbHost, *dbPort),
-		DBName:                  *antispamDBName,
+		Addr:                    fmt.Sprintf("%s:%d", s.DBHost, s.DBPort),
+		DBName:                  dbName,
 		AllowCleartextPasswords: true,
 		AllowNativePasswords:    true,
 	}
@@ -17,50 +17,20 @@ package main
 import (
 	"context"
 	"crypto"
-	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"io"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/transparency-dev/tesseract/internal/signer"
 )
 
-// TODO: Move ECDSAWithSHA256Signer to internal signer package.
-// ECDSAWithSHA256Signer implements crypto.Signer using AWS Secrets Manager.
-// Only crypto.SHA256 and ECDSA are supported.
-type ECDSAWithSHA256Signer struct {
-	publicKey  *ecdsa.PublicKey
-	privateKey *ecdsa.PrivateKey
-}
-
-// Public returns the public key stored in the Signer object.
-func (s *ECDSAWithSHA256Signer) Public() crypto.PublicKey {
-	return s.publicKey
-}
-
-// Sign signs digest with the private key stored in AWS Secrets Manager.
-func (s *ECDSAWithSHA256Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
-	// Verify hash function and digest bytes length.
-	if opts == nil {
-		return nil, errors.New("opts cannot be nil")
-	}
-	if opts.HashFunc() != crypto.SHA256 {
-		return nil, fmt.Errorf("unsupported hash func: %v", opts.HashFunc())
-	}
-	if len(digest) != opts.HashFunc().Size() {
-		return nil, fmt.Errorf("digest bytes length %d does not match hash function bytes length %d", len(digest), opts.HashFunc().Size())
-	}
-
-	return ecdsa.SignASN1(rand, s.privateKey, digest)
-}
-
-// NewSecretsManagerSigner creates a new signer that uses the ECDSA P-256 key pair in
+// NewSecretsManagerSigner creates a new signer that uses the ECDSA P-256 or RSA key pair in
 // AWS Secrets Manager for signing digests.
-func NewSecretsManagerSigner(ctx context.Context, publicKeySecretName, privateKeySecretName string) (*ECDSAWithSHA256Signer, error) {
+func NewSecretsManagerSigner(ctx context.Context, publicKeySecretName, privateKeySecretName string) (crypto.Signer, error) {
 	sdkConfig, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load default AWS configuration: %v", err)
@@ -84,21 +54,20 @@ func NewSecretsManagerSigner(ctx context.Context, publicKeySecretName, privateKe
 	if err != nil {
 		return nil, err
 	}
-	var ecdsaPublicKey *ecdsa.PublicKey
-	ecdsaPublicKey, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("the public key stored in Secret Manager is not an ECDSA key")
-	}
 
 	// Private Key
-	var ecdsaPrivateKey *ecdsa.PrivateKey
+	var privateKey crypto.PrivateKey
 	pemBlock, err = secretPEM(ctx, client, privateKeySecretName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get private key secret PEM (%s): %w", privateKeySecretName, err)
 	}
 	switch pemBlock.Type {
 	case "EC PRIVATE KEY":
-		ecdsaPrivateKey, err = x509.ParseECPrivateKey(pemBlock.Bytes)
+		privateKey, err = x509.ParseECPrivateKey(pemBlock.Bytes)
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(pemBlock.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(pemBlock.Bytes)
 	default:
 		return nil, fmt.Errorf("unsupported PEM type: %s", pemBlock.Type)
 	}
@@ -106,15 +75,8 @@ func NewSecretsManagerSigner(ctx context.Context, publicKeySecretName, privateKe
 		return nil, err
 	}
 
-	// Verify the correctness of the signer key pair
-	if !ecdsaPrivateKey.PublicKey.Equal(ecdsaPublicKey) {
-		return nil, errors.New("signer key pair doesn't match")
-	}
-
-	return &ECDSAWithSHA256Signer{
-		publicKey:  ecdsaPublicKey,
-		privateKey: ecdsaPrivateKey,
-	}, nil
+	// NewSHA256Signer verifies the correctness of the signer key pair.
+	return signer.NewSHA256Signer(publicKey, privateKey)
 }
 
 func secretPEM(ctx context.Context, client *secretsmanager.Client, secretName string) (*pem.Block, error) {
@@ -0,0 +1,78 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ccadb-import is a command-line tool which converts a CCADB CSV or JSON
+// report into a PEM roots file, suitable for TesseraCT's roots_pem_file.
+package main
+
+import (
+	"bytes"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/transparency-dev/tesseract/internal/x509util"
+	"k8s.io/klog/v2"
+)
+
+var (
+	report      = flag.String("report", "", "Path to a CCADB CSV report, or JSON report with a .json extension.")
+	trustBits   = flag.String("trust_bits", "", "Comma separated list of trust bits or EKUs, e.g. 'Websites' or 'Server Authentication'. If set, only roots trusted for at least one of them are imported.")
+	output      = flag.String("output", "", "Path to the PEM file to write roots to. Roots are written to stdout if empty.")
+	allowErrors = flag.Bool("allow_errors", false, "If true then records which can't be imported, such as unparsable or duplicate ones, are reported but don't cause a failure.")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	if *report == "" {
+		klog.Exit("--report must be set")
+	}
+	var opts x509util.CCADBOptions
+	if *trustBits != "" {
+		opts.TrustBits = strings.Split(*trustBits, ",")
+	}
+
+	res, err := x509util.LoadCCADBFile(*report, opts)
+	if err != nil {
+		klog.Exitf("Failed to import CCADB report: %v", err)
+	}
+	for _, e := range res.Errors {
+		klog.Warningf("Skipped %v", e)
+	}
+	klog.Infof("Imported %d roots, skipped %d untrusted records and %d invalid records", len(res.Pool.RawCertificates()), res.Untrusted, len(res.Errors))
+	if len(res.Errors) > 0 && !*allowErrors {
+		klog.Exitf("%d records could not be imported, set --allow_errors to ignore them", len(res.Errors))
+	}
+
+	var b bytes.Buffer
+	for _, cert := range res.Pool.RawCertificates() {
+		fmt.Fprintf(&b, "# %s\n", cert.Subject)
+		if err := pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); err != nil {
+			klog.Exitf("Failed to encode certificate: %v", err)
+		}
+	}
+	if *output == "" {
+		if _, err := os.Stdout.Write(b.Bytes()); err != nil {
+			klog.Exitf("Failed to write roots: %v", err)
+		}
+		return
+	}
+	if err := os.WriteFile(*output, b.Bytes(), 0o644); err != nil {
+		klog.Exitf("Failed to write roots: %v", err)
+	}
+}
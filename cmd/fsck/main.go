@@ -0,0 +1,211 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// fsck is a command-line tool to check the consistency of a static-ct-api
+// log offline.
+//
+// It recomputes all the tiles of the log and its root hash from its entry
+// bundles, checks that entries' leaf_index extensions match their position,
+// and that all the issuers they reference are stored. Findings are written to
+// stdout as JSON lines, and the tool exits with a non-zero status if any is
+// found.
+//
+// With --state_file, progress is saved regularly, and later runs resume from
+// where the previous one stopped.
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/client"
+	"github.com/transparency-dev/tesseract/internal/client/gcp"
+	"github.com/transparency-dev/tesseract/internal/fsck"
+	"golang.org/x/mod/sumdb/note"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logURL       = flag.String("log_url", "", "Log storage root URL, e.g. https://log.server/and/path/, file:///path/to/log/ or gs://bucket/")
+	origin       = flag.String("origin", os.Getenv("CT_LOG_ORIGIN"), "Origin of the log, for checkpoints. This is defaulted to the environment variable CT_LOG_ORIGIN")
+	logPubKey    = flag.String("log_public_key", os.Getenv("CT_LOG_PUBLIC_KEY"), "Base64 encoded DER public key of the log. This is defaulted to the environment variable CT_LOG_PUBLIC_KEY")
+	bearerToken  = flag.String("bearer_token", "", "The bearer token for auth, for HTTP log URLs")
+	stateFile    = flag.String("state_file", "", "Path to a file to save progress to, and to resume from if it exists. If unset, the whole log is checked")
+	saveInterval = flag.Duration("save_interval", 10*time.Second, "Minimum interval between two saves of progress to --state_file")
+	parallelism  = flag.Uint("parallelism", 8, "Number of entry bundles to fetch concurrently")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	v, err := logSigVerifier(*origin, *logPubKey)
+	if err != nil {
+		klog.Exitf("Failed to create verifier: %v", err)
+	}
+	f, err := newFetcher(ctx, *logURL)
+	if err != nil {
+		klog.Exitf("Failed to create fetcher: %v", err)
+	}
+
+	st := &fsck.State{}
+	if *stateFile != "" {
+		if st, err = loadState(*stateFile); err != nil {
+			klog.Exitf("Failed to load state: %v", err)
+		}
+	}
+
+	findings := 0
+	enc := json.NewEncoder(os.Stdout)
+	report := func(f fsck.Finding) {
+		findings++
+		if err := enc.Encode(f); err != nil {
+			klog.Exitf("Failed to write finding: %v", err)
+		}
+	}
+	lastSave := time.Now()
+	save := func(st *fsck.State) error {
+		if *stateFile == "" || time.Since(lastSave) < *saveInterval {
+			return nil
+		}
+		lastSave = time.Now()
+		return saveState(*stateFile, st)
+	}
+
+	c := fsck.NewChecker(f, *origin, v, *parallelism, report)
+	if err := c.Check(ctx, st, save); err != nil {
+		klog.Exitf("Failed to check log: %v", err)
+	}
+	if *stateFile != "" {
+		if err := saveState(*stateFile, st); err != nil {
+			klog.Exitf("Failed to save state: %v", err)
+		}
+	}
+	if findings > 0 {
+		klog.Exitf("Found %d problems in the log up to entry %d", findings, st.Next)
+	}
+	klog.Infof("No problems found in the log up to entry %d", st.Next)
+}
+
+// logSigVerifier creates a note.Verifier for the Static CT API log by taking
+// an origin string and a base64-encoded public key.
+func logSigVerifier(origin, b64PubKey string) (note.Verifier, error) {
+	if origin == "" {
+		return nil, errors.New("origin cannot be empty")
+	}
+	if b64PubKey == "" {
+		return nil, errors.New("log public key cannot be empty")
+	}
+
+	derBytes, err := base64.StdEncoding.DecodeString(b64PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding public key: %s", err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(derBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key: %v", err)
+	}
+	return client.NewRFC6962Verifier(origin, pub)
+}
+
+// newFetcher returns a fetcher for the log rooted at u.
+func newFetcher(ctx context.Context, u string) (fsck.Fetcher, error) {
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	rURL, err := url.Parse(u)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log URL %q: %v", u, err)
+	}
+
+	switch rURL.Scheme {
+	case "http", "https":
+		c, err := client.NewHTTPFetcher(rURL, http.DefaultClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP fetcher for %q: %v", u, err)
+		}
+		if *bearerToken != "" {
+			c.SetAuthorizationHeader(fmt.Sprintf("Bearer %s", *bearerToken))
+		}
+		return c, nil
+	case "file":
+		return client.FileFetcher{Root: rURL.Path}, nil
+	case "gs":
+		c, err := gcp.NewGSFetcher(ctx, rURL.Host, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS fetcher for %q: %v", u, err)
+		}
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q on log URL", rURL.Scheme)
+	}
+}
+
+// loadState reads the state saved in path, if any.
+func loadState(path string) (*fsck.State, error) {
+	st := &fsck.State{}
+	d, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		klog.Infof("No state found in %q, checking the log from the beginning", path)
+		return st, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", path, err)
+	}
+	if err := json.Unmarshal(d, st); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %v", path, err)
+	}
+	klog.Infof("Resuming from entry %d", st.Next)
+	return st, nil
+}
+
+// saveState atomically writes st to path.
+func saveState(path string, st *fsck.State) error {
+	d, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(d); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %q: %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %q: %v", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename %q to %q: %v", tmp.Name(), path, err)
+	}
+	return nil
+}
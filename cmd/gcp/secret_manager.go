@@ -17,51 +17,21 @@ package main
 import (
 	"context"
 	"crypto"
-	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"hash/crc32"
-	"io"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/transparency-dev/tesseract/internal/signer"
 	"k8s.io/klog/v2"
 )
 
-// TODO: Move ECDSAWithSHA256Signer to internal signer package.
-// ECDSAWithSHA256Signer implements crypto.Signer using Google Cloud Secret Manager.
-// Only crypto.SHA256 and ECDSA are supported.
-type ECDSAWithSHA256Signer struct {
-	publicKey  *ecdsa.PublicKey
-	privateKey *ecdsa.PrivateKey
-}
-
-// Public returns the public key stored in the Signer object.
-func (s *ECDSAWithSHA256Signer) Public() crypto.PublicKey {
-	return s.publicKey
-}
-
-// Sign signs digest with the private key stored in Google Cloud Secret Manager.
-func (s *ECDSAWithSHA256Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
-	// Verify hash function and digest bytes length.
-	if opts == nil {
-		return nil, errors.New("opts cannot be nil")
-	}
-	if opts.HashFunc() != crypto.SHA256 {
-		return nil, fmt.Errorf("unsupported hash func: %v", opts.HashFunc())
-	}
-	if len(digest) != opts.HashFunc().Size() {
-		return nil, fmt.Errorf("digest bytes length %d does not match hash function bytes length %d", len(digest), opts.HashFunc().Size())
-	}
-
-	return ecdsa.SignASN1(rand, s.privateKey, digest)
-}
-
-// NewSecretManagerSigner creates a new signer that uses the ECDSA P-256 key pair in
+// NewSecretManagerSigner creates a new signer that uses the ECDSA P-256 or RSA key pair in
 // Google Cloud Secret Manager for signing digests.
-func NewSecretManagerSigner(ctx context.Context, publicKeySecretName, privateKeySecretName string) (*ECDSAWithSHA256Signer, error) {
+func NewSecretManagerSigner(ctx context.Context, publicKeySecretName, privateKeySecretName string) (crypto.Signer, error) {
 	client, err := secretmanager.NewClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create secret manager client: %w", err)
@@ -87,21 +57,20 @@ func NewSecretManagerSigner(ctx context.Context, publicKeySecretName, privateKey
 	if err != nil {
 		return nil, err
 	}
-	var ecdsaPublicKey *ecdsa.PublicKey
-	ecdsaPublicKey, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("the public key stored in Secret Manager is not an ECDSA key")
-	}
 
 	// Private Key
-	var ecdsaPrivateKey *ecdsa.PrivateKey
+	var privateKey crypto.PrivateKey
 	pemBlock, err = secretPEM(ctx, client, privateKeySecretName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get private key secret PEM (%s): %w", privateKeySecretName, err)
 	}
 	switch pemBlock.Type {
 	case "EC PRIVATE KEY":
-		ecdsaPrivateKey, err = x509.ParseECPrivateKey(pemBlock.Bytes)
+		privateKey, err = x509.ParseECPrivateKey(pemBlock.Bytes)
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(pemBlock.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(pemBlock.Bytes)
 	default:
 		return nil, fmt.Errorf("unsupported PEM type: %s", pemBlock.Type)
 	}
@@ -109,15 +78,8 @@ func NewSecretManagerSigner(ctx context.Context, publicKeySecretName, privateKey
 		return nil, err
 	}
 
-	// Verify the correctness of the signer key pair
-	if !ecdsaPrivateKey.PublicKey.Equal(ecdsaPublicKey) {
-		return nil, errors.New("signer key pair doesn't match")
-	}
-
-	return &ECDSAWithSHA256Signer{
-		publicKey:  ecdsaPublicKey,
-		privateKey: ecdsaPrivateKey,
-	}, nil
+	// NewSHA256Signer verifies the correctness of the signer key pair.
+	return signer.NewSHA256Signer(publicKey, privateKey)
 }
 
 func secretPEM(ctx context.Context, client *secretmanager.Client, secretName string) (*pem.Block, error) {
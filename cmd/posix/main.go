@@ -0,0 +1,214 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The posix binary runs the CT personality on a local filesystem.
+package main
+
+import (
+	"context"
+	"crypto"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/transparency-dev/tessera"
+	tposix "github.com/transparency-dev/tessera/storage/posix"
+	posix_as "github.com/transparency-dev/tessera/storage/posix/antispam"
+	"github.com/transparency-dev/tesseract"
+	"github.com/transparency-dev/tesseract/internal/config"
+	"github.com/transparency-dev/tesseract/internal/signer"
+	"github.com/transparency-dev/tesseract/storage"
+	"github.com/transparency-dev/tesseract/storage/posix"
+	"golang.org/x/mod/sumdb/note"
+	"k8s.io/klog/v2"
+)
+
+var flags = config.RegisterFlags(flag.CommandLine, config.POSIX)
+
+// nolint:staticcheck
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx := context.Background()
+
+	cfg, err := flags.Config()
+	if err != nil {
+		klog.Exitf("Can't load config: %v", err)
+	}
+	if flags.PrintEffectiveConfig() {
+		printConfig(cfg)
+		return
+	}
+
+	logs := make([]tesseract.LogConfig, 0, len(cfg.Logs))
+	for _, l := range cfg.Logs {
+		signer, err := newSigner(l.Signer)
+		if err != nil {
+			klog.Exitf("Can't create signer for log %q: %v", l.Origin, err)
+		}
+		logs = append(logs, tesseract.LogConfig{
+			Origin:                l.Origin,
+			Signer:                signer,
+			DeterministicSCTs:     l.DeterministicSCTs,
+			ChainValidationConfig: l.ChainValidationConfig(),
+			CreateStorage:         newPOSIXStorage(l),
+			CheckpointInterval:    l.Tessera.CheckpointInterval,
+		})
+	}
+
+	hOpts := cfg.LogHandlerOpts()
+	if rlc := cfg.RequestLogConfig(); rlc != nil {
+		rl, closeRequestLog, err := tesseract.NewJSONRequestLog(*rlc)
+		if err != nil {
+			klog.Exitf("Failed to create request log: %v", err)
+		}
+		defer func() {
+			if err := closeRequestLog(); err != nil {
+				klog.Warningf("Failed to close request log: %v", err)
+			}
+		}()
+		hOpts.RequestLog = rl
+	}
+	logHandler, shutdownLogs, err := tesseract.NewLogsHandler(ctx, logs, hOpts)
+	if err != nil {
+		klog.Exitf("Can't initialize CT HTTP Server: %v", err)
+	}
+
+	klog.CopyStandardLogTo("WARNING")
+	klog.Info("**** CT HTTP Server Starting ****")
+	http.Handle("/", logHandler)
+
+	// Bring up the HTTP server and serve until we get a signal not to.
+	srv := http.Server{Addr: cfg.HTTPEndpoint}
+	shutdownWG := new(sync.WaitGroup)
+	shutdownWG.Add(1)
+	go awaitSignal(func() {
+		defer shutdownWG.Done()
+		// Allow pending requests to finish, then wait for the entries they
+		// added to be integrated and published, or terminate any stragglers.
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		klog.Info("Shutting down HTTP server...")
+		if err := srv.Shutdown(ctx); err != nil {
+			klog.Errorf("srv.Shutdown(): %v", err)
+		}
+		klog.Info("HTTP server shutdown")
+		klog.Info("Shutting down logs...")
+		if err := shutdownLogs(ctx); err != nil {
+			klog.Errorf("Failed to shut logs down cleanly: %v", err)
+		}
+		klog.Info("Logs shutdown")
+	})
+
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		klog.Warningf("Server exited: %v", err)
+	}
+	// Wait will only block if the function passed to awaitSignal was called,
+	// in which case it'll block until the HTTP server has gracefully shutdown
+	shutdownWG.Wait()
+	klog.Flush()
+}
+
+// awaitSignal waits for standard termination signals, then runs the given
+// function; it should be run as a separate goroutine.
+func awaitSignal(doneFn func()) {
+	// Arrange notification for the standard set of signals used to terminate a server
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	// Now block main and wait for a signal
+	sig := <-sigs
+	klog.Warningf("Signal received: %v", sig)
+	klog.Flush()
+
+	doneFn()
+}
+
+// newSigner creates the signer configured by s: from a local key file, or a
+// PKCS #11 token.
+func newSigner(s config.Signer) (crypto.Signer, error) {
+	if s.PKCS11 != nil {
+		return signer.NewPKCS11Signer(s.PKCS11.PKCS11Config())
+	}
+	return signer.NewFileSigner(s.PrivateKeyFile, s.PublicKeyFile)
+}
+
+// printConfig prints cfg to stdout, with secrets redacted.
+func printConfig(cfg *config.Config) {
+	b, err := cfg.Redacted().Marshal()
+	if err != nil {
+		klog.Exitf("Can't print config: %v", err)
+	}
+	fmt.Print(string(b))
+}
+
+func newPOSIXStorage(l config.Log) storage.CreateStorage {
+	return func(ctx context.Context, signer note.Signer) (*storage.CTStorage, error) {
+		s := l.Storage.POSIX
+		driver, err := tposix.New(ctx, s.StorageDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize POSIX Tessera storage driver: %v", err)
+		}
+
+		var antispam tessera.Antispam
+		if s.AntispamDBPath != "" {
+			antispam, err = posix_as.NewAntispam(ctx, s.AntispamDBPath, posix_as.AntispamOpts{})
+			if err != nil {
+				klog.Exitf("Failed to create new POSIX antispam storage: %v", err)
+			}
+		}
+
+		opts := tessera.NewAppendOptions().
+			WithCheckpointSigner(signer).
+			WithCTLayout().
+			WithAntispam(l.Tessera.InMemoryAntispamCacheSize, antispam).
+			WithCheckpointInterval(l.Tessera.CheckpointInterval).
+			WithBatching(l.Tessera.BatchMaxSize, l.Tessera.BatchMaxAge).
+			WithPushback(l.Tessera.PushbackMaxOutstanding)
+
+		appender, shutdown, reader, err := tessera.NewAppender(ctx, driver, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize POSIX Tessera appender: %v", err)
+		}
+
+		// Store issuers next to the log, under the path mandated by
+		// https://c2sp.org/static-ct-api, so that the storage directory can be
+		// served as is by any static file server.
+		issuerStorage, err := posix.NewIssuerStorage(filepath.Join(s.StorageDir, "issuer"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize POSIX issuer storage: %v", err)
+		}
+
+		ctStorage, err := storage.NewCTStorage(ctx, appender, shutdown, issuerStorage, reader, l.Tessera.EnablePublicationAwaiter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CT storage: %v", err)
+		}
+		if err := ctStorage.SetTimestampCacheSize(int(l.TimestampCacheSize)); err != nil {
+			return nil, fmt.Errorf("failed to set timestamp cache size: %v", err)
+		}
+
+		if l.LeafIndexDBPath != "" {
+			if err := ctStorage.FollowLeafIndex(ctx, l.LeafIndexDBPath); err != nil {
+				return nil, fmt.Errorf("failed to initialize leaf index: %v", err)
+			}
+		}
+
+		return ctStorage, nil
+	}
+}
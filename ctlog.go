@@ -21,21 +21,32 @@ import (
 	"encoding/asn1"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/transparency-dev/tesseract/internal/ct"
-	"github.com/transparency-dev/tesseract/internal/x509util"
+	"github.com/transparency-dev/tesseract/internal/rotatingfile"
 	"github.com/transparency-dev/tesseract/storage"
+	"k8s.io/klog/v2"
 )
 
 // ChainValidationConfig contains parameters to configure chain validation.
 type ChainValidationConfig struct {
 	// RootsPEMFile is the path to the file containing root certificates that
 	// are acceptable to the log. The certs are served through get-roots
-	// endpoint.
+	// endpoint. Only one of RootsPEMFile and RootsManifestFile can be set.
 	RootsPEMFile string
+	// RootsManifestFile is the path to a YAML or JSON manifest listing root
+	// certificates that are acceptable to the log, each with an optional time
+	// window during which it is accepted, and labels. Only roots accepted at
+	// the current time are served through the get-roots endpoint.
+	RootsManifestFile string
 	// RejectExpired controls if true then the certificate validity period will be
 	// checked against the current time during the validation of submissions.
 	// This will cause expired certificates to be rejected.
@@ -60,6 +71,34 @@ type ChainValidationConfig struct {
 	// exclusive.
 	// Leaving this unset implies no upper bound to the range.
 	NotAfterLimit *time.Time
+	// LenientChainOrdering controls whether submitted chains with extra or
+	// misordered intermediates are accepted, as long as a path to a root can
+	// be built from submitted certificates. This path is logged.
+	LenientChainOrdering bool
+	// CompleteChains controls whether submitted chains with missing
+	// intermediates are completed with issuers previously seen by the log.
+	// Completed chains are logged. Issuers stored by the log are loaded in the
+	// background if its storage can list them.
+	CompleteChains bool
+	// PolicyFile is the path to a YAML or JSON file holding CEL rules that
+	// chains must satisfy to be accepted, once a path to a root has been
+	// built. Tests listed in the file are run when it is loaded. Leaving
+	// this unset accepts all chains passing the other checks.
+	PolicyFile string
+	// WeakKeys controls the detection of weak and compromised keys in
+	// submitted leaves: RSA keys shorter than 2048 bits, RSA moduli
+	// vulnerable to ROCA, and keys listed in DebianWeakKeysFile or
+	// CompromisedKeysFile. "flag" counts them with the
+	// tesseract.chain.weak_key metric, and "reject" also rejects them.
+	// Leaving this unset disables detection.
+	WeakKeys string
+	// DebianWeakKeysFile is the path to a blocklist of RSA moduli generated
+	// by the vulnerable Debian OpenSSL package, in the format of the
+	// openssl-blacklist package.
+	DebianWeakKeysFile string
+	// CompromisedKeysFile is the path to a file listing the hex encoded
+	// SHA-256 of the SubjectPublicKeyInfo of compromised keys, one per line.
+	CompromisedKeysFile string
 }
 
 // systemTimeSource implements ct.TimeSource.
@@ -73,34 +112,40 @@ func (s systemTimeSource) Now() time.Time {
 var sysTimeSource = systemTimeSource{}
 
 // newChainValidator checks that a chain validation config is valid,
-// parses it, and loads resources to validate chains.
-func newChainValidator(cfg ChainValidationConfig) (ct.ChainValidator, error) {
+// parses it, and loads resources to validate chains of the log identified by
+// origin. It also returns the log's roots, so that they can be reloaded.
+func newChainValidator(origin string, cfg ChainValidationConfig) (ct.ChainValidator, *ct.Roots, error) {
 	// Load the trusted roots.
-	if cfg.RootsPEMFile == "" {
-		return nil, errors.New("empty rootsPemFile")
+	var roots *ct.Roots
+	var err error
+	switch {
+	case cfg.RootsPEMFile != "" && cfg.RootsManifestFile != "":
+		return nil, nil, errors.New("only one of rootsPemFile and rootsManifestFile can be set")
+	case cfg.RootsManifestFile != "":
+		roots, err = ct.LoadRootsManifest(origin, cfg.RootsManifestFile)
+	default:
+		roots, err = ct.LoadRoots(origin, cfg.RootsPEMFile)
 	}
-	roots := x509util.NewPEMCertPool()
-	if err := roots.AppendCertsFromPEMFile(cfg.RootsPEMFile); err != nil {
-		return nil, fmt.Errorf("failed to read trusted roots: %v", err)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if cfg.RejectExpired && cfg.RejectUnexpired {
-		return nil, errors.New("configuration would reject all certificates")
+		return nil, nil, errors.New("configuration would reject all certificates")
 	}
 
 	// Validate the time interval.
 	if cfg.NotAfterStart != nil && cfg.NotAfterLimit != nil && (cfg.NotAfterLimit).Before(*cfg.NotAfterStart) {
-		return nil, fmt.Errorf("'Not After' limit %q before start %q", cfg.NotAfterLimit.Format(time.RFC3339), cfg.NotAfterStart.Format(time.RFC3339))
+		return nil, nil, fmt.Errorf("'Not After' limit %q before start %q", cfg.NotAfterLimit.Format(time.RFC3339), cfg.NotAfterStart.Format(time.RFC3339))
 	}
 
-	var err error
 	var extKeyUsages []x509.ExtKeyUsage
 	// Filter which extended key usages are allowed.
 	if cfg.ExtKeyUsages != "" {
 		lExtKeyUsages := strings.Split(cfg.ExtKeyUsages, ",")
 		extKeyUsages, err = ct.ParseExtKeyUsages(lExtKeyUsages)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse ExtKeyUsages: %v", err)
+			return nil, nil, fmt.Errorf("failed to parse ExtKeyUsages: %v", err)
 		}
 	}
 
@@ -110,40 +155,342 @@ func newChainValidator(cfg ChainValidationConfig) (ct.ChainValidator, error) {
 		lRejectExtensions := strings.Split(cfg.RejectExtensions, ",")
 		rejectExtIds, err = ct.ParseOIDs(lRejectExtensions)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse RejectExtensions: %v", err)
+			return nil, nil, fmt.Errorf("failed to parse RejectExtensions: %v", err)
+		}
+	}
+
+	var knownIssuers *ct.IssuerPool
+	if cfg.CompleteChains {
+		knownIssuers = ct.NewIssuerPool()
+	}
+
+	var policy *ct.Policy
+	if cfg.PolicyFile != "" {
+		policy, err = ct.LoadPolicy(cfg.PolicyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load policy: %v", err)
+		}
+	}
+
+	var weakKeys *ct.WeakKeyDetector
+	switch cfg.WeakKeys {
+	case "":
+		if cfg.DebianWeakKeysFile != "" || cfg.CompromisedKeysFile != "" {
+			return nil, nil, errors.New("weak keys files are set, but WeakKeys is not")
+		}
+	case "flag", "reject":
+		weakKeys, err = ct.NewWeakKeyDetector(cfg.WeakKeys == "reject", cfg.DebianWeakKeysFile, cfg.CompromisedKeysFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load weak keys: %v", err)
 		}
+	default:
+		return nil, nil, fmt.Errorf("unknown WeakKeys mode %q, want flag or reject", cfg.WeakKeys)
 	}
 
-	cv := ct.NewChainValidator(roots, cfg.RejectExpired, cfg.RejectUnexpired, cfg.NotAfterStart, cfg.NotAfterLimit, extKeyUsages, rejectExtIds)
-	return &cv, nil
+	cv := ct.NewChainValidator(roots, ct.ChainValidatorOptions{
+		TimeSource:      sysTimeSource,
+		RejectExpired:   cfg.RejectExpired,
+		RejectUnexpired: cfg.RejectUnexpired,
+		NotAfterStart:   cfg.NotAfterStart,
+		NotAfterLimit:   cfg.NotAfterLimit,
+		ExtKeyUsages:    extKeyUsages,
+		RejectExtIds:    rejectExtIds,
+		LenientOrdering: cfg.LenientChainOrdering,
+		KnownIssuers:    knownIssuers,
+		Policy:          policy,
+		WeakKeys:        weakKeys,
+	})
+	return &cv, roots, nil
 }
 
-// NewLogHandler creates a Tessera based CT log pluged into HTTP handlers.
-// The HTTP server handlers implement https://c2sp.org/static-ct-api write
-// endpoints.
-func NewLogHandler(ctx context.Context, origin string, signer crypto.Signer, cfg ChainValidationConfig, cs storage.CreateStorage, httpDeadline time.Duration, maskInternalErrors bool) (http.Handler, error) {
-	cv, err := newChainValidator(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("newCertValidationOpts(): %v", err)
+// RequestLog is notified of the progress of each request handled by a log,
+// for structured logging.
+type RequestLog = ct.RequestLog
+
+// RequestLogConfig configures a JSON request log.
+type RequestLogConfig struct {
+	// Path is the file to write records to, or "-" for stdout.
+	Path string
+	// MaxSizeBytes is the size after which the file is rotated. Zero disables
+	// rotation.
+	MaxSizeBytes int64
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// SampleRate is the fraction of requests which are logged, between 0
+	// and 1.
+	SampleRate float64
+	// RedactNames omits the subject and issuer names of submitted
+	// certificates from records.
+	RedactNames bool
+}
+
+// NewJSONRequestLog returns a RequestLog writing a newline-delimited JSON
+// record per request, with the origin of the log, the fingerprints of the
+// submitted chain, the names of its leaf certificate, the index and
+// timestamp assigned to its entry, whether it was a duplicate, and the status
+// and latency of the response.
+//
+// The returned function closes the underlying file.
+func NewJSONRequestLog(cfg RequestLogConfig) (RequestLog, func() error, error) {
+	opts := ct.JSONRequestLogOptions{
+		SampleRate:  cfg.SampleRate,
+		RedactNames: cfg.RedactNames,
+	}
+	if cfg.Path == "-" {
+		return ct.NewJSONRequestLog(os.Stdout, opts), func() error { return nil }, nil
 	}
-	log, err := ct.NewLog(ctx, origin, signer, cv, cs, sysTimeSource)
+	f, err := rotatingfile.New(cfg.Path, cfg.MaxSizeBytes, cfg.MaxBackups)
 	if err != nil {
-		return nil, fmt.Errorf("newLog(): %v", err)
+		return nil, nil, fmt.Errorf("failed to open request log file: %v", err)
+	}
+	return ct.NewJSONRequestLog(f, opts), f.Close, nil
+}
+
+// LogHandlerOpts configures the HTTP handlers of logs.
+type LogHandlerOpts struct {
+	// HTTPDeadline is a timeout for HTTP requests.
+	HTTPDeadline time.Duration
+	// MaskInternalErrors indicates if internal server errors should be masked
+	// or returned to the user containing the full error message.
+	MaskInternalErrors bool
+	// RequestLog is notified of the progress of requests, for structured
+	// logging. If nil, requests are logged at a high klog verbosity.
+	RequestLog RequestLog
+	// EnableReadPath controls whether the https://c2sp.org/static-ct-api read
+	// path (checkpoint, tiles, entry bundles and issuers) is served from the log
+	// storage, in addition to write endpoints.
+	EnableReadPath bool
+	// EnableRFC6962ReadAPI controls whether RFC 6962 read endpoints
+	// (get-sth, get-sth-consistency, get-proof-by-hash, get-entries and
+	// get-entry-and-proof) are served, synthesized from the log storage.
+	EnableRFC6962ReadAPI bool
+	// EnableLeafIndexAPI controls whether entries can be looked up by their
+	// Merkle leaf hash. This requires the log storage to have a leaf index.
+	EnableLeafIndexAPI bool
+	// EnableValidateChain controls whether the non-standard validate-chain
+	// endpoint is served, to check chains without logging them.
+	EnableValidateChain bool
+	// RootsPollInterval is the interval at which roots files are polled: they
+	// are reloaded on every tick, whether they changed or not, rather than
+	// watched for changes. Zero disables periodic reloads.
+	RootsPollInterval time.Duration
+	// ReloadRootsOnSIGHUP controls whether roots files are reloaded when the
+	// process receives a SIGHUP.
+	ReloadRootsOnSIGHUP bool
+	// ReadinessMaxPushback is how long the storage of a log may push back on
+	// new entries without interruption before the log is reported as not
+	// ready. Zero disables this check.
+	ReadinessMaxPushback time.Duration
+}
+
+// LogConfig configures one of the logs served by NewLogsHandler.
+type LogConfig struct {
+	// Origin of the log, for checkpoints and the URL prefix of its endpoints.
+	Origin string
+	// Signer signs the log's checkpoints and SCTs.
+	Signer crypto.Signer
+	// DeterministicSCTs controls whether SCTs are signed deterministically,
+	// such that resubmissions of a leaf with the same timestamp get
+	// byte-identical SCTs. Signer must support deterministic signatures, as
+	// in-memory ECDSA (RFC 6979) and RSA keys do.
+	DeterministicSCTs bool
+	// ChainValidationConfig configures which chains the log accepts.
+	ChainValidationConfig ChainValidationConfig
+	// CreateStorage instantiates the log's storage.
+	CreateStorage storage.CreateStorage
+	// CheckpointInterval is the interval at which the log storage publishes
+	// checkpoints. If set, the log is reported as not ready when its latest
+	// checkpoint is older than a few intervals.
+	CheckpointInterval time.Duration
+}
+
+// staleCheckpointIntervals is the number of checkpoint intervals after which
+// a checkpoint is considered to be stale.
+const staleCheckpointIntervals = 3
+
+// NewLogHandler creates a Tessera based CT log pluged into HTTP handlers.
+// The HTTP server handlers implement https://c2sp.org/static-ct-api write
+// endpoints, and optionally read endpoints.
+//
+// The returned function shuts the log down, see NewLogsHandler.
+func NewLogHandler(ctx context.Context, origin string, signer crypto.Signer, cfg ChainValidationConfig, cs storage.CreateStorage, hOpts LogHandlerOpts) (http.Handler, func(context.Context) error, error) {
+	return NewLogsHandler(ctx, []LogConfig{{Origin: origin, Signer: signer, ChainValidationConfig: cfg, CreateStorage: cs}}, hOpts)
+}
+
+// NewLogsHandler creates multiple Tessera based CT logs, such as temporal
+// shards, plugged into a single HTTP handler.
+//
+// Each log has its own signer, chain validation config and storage, and its
+// endpoints are served under a URL prefix derived from its origin. Origins
+// must be unique.
+//
+// The returned function shuts all the logs down: they stop accepting new
+// entries, and it waits until the entries they have already accepted are
+// integrated and committed to by a published checkpoint, or until its context
+// is done. It should be called once the HTTP server has stopped serving
+// requests, and before ctx is cancelled.
+//
+// The handler also serves a liveness endpoint at /healthz, and a readiness
+// endpoint at /readyz which checks the storage, checkpoints and signer of all
+// the logs.
+func NewLogsHandler(ctx context.Context, logs []LogConfig, hOpts LogHandlerOpts) (http.Handler, func(context.Context) error, error) {
+	if len(logs) == 0 {
+		return nil, nil, errors.New("no log to serve")
 	}
 
 	opts := &ct.HandlerOptions{
-		Deadline:           httpDeadline,
-		RequestLog:         &ct.DefaultRequestLog{},
-		MaskInternalErrors: maskInternalErrors,
+		Deadline:           hOpts.HTTPDeadline,
+		RequestLog:         hOpts.RequestLog,
+		MaskInternalErrors: hOpts.MaskInternalErrors,
 		TimeSource:         sysTimeSource,
 	}
 
-	handlers := ct.NewPathHandlers(ctx, opts, log)
+	if opts.RequestLog == nil {
+		opts.RequestLog = &ct.DefaultRequestLog{}
+	}
+
 	mux := http.NewServeMux()
-	// Register handlers for all the configured logs.
-	for path, handler := range handlers {
+	paths := make(map[string]string)
+	roots := make([]*ct.Roots, 0, len(logs))
+	shutdowns := make(map[string]func(context.Context) error, len(logs))
+	probes := make(map[string]ct.ReadinessProbe, len(logs))
+	// Register handlers for all the configured logs. If one of them can't be
+	// served, the ones already created are shut down.
+	for _, l := range logs {
+		sl, err := newServedLog(ctx, l, opts, hOpts)
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("log %q: %v", l.Origin, err), shutdownLogs(ctx, shutdowns))
+		}
+		for path := range sl.handlers {
+			if o, ok := paths[path]; ok {
+				err := fmt.Errorf("log %q: path %q is already served by log %q", l.Origin, path, o)
+				if sErr := sl.shutdown(ctx); sErr != nil {
+					err = errors.Join(err, fmt.Errorf("log %q: %v", l.Origin, sErr))
+				}
+				return nil, nil, errors.Join(err, shutdownLogs(ctx, shutdowns))
+			}
+		}
+		for path, handler := range sl.handlers {
+			paths[path] = l.Origin
+			mux.Handle(path, handler)
+		}
+		roots = append(roots, sl.roots)
+		shutdowns[l.Origin] = sl.shutdown
+		probes[l.Origin] = sl.probe
+	}
+	for path, handler := range ct.NewHealthHandlers(probes, hOpts.MaskInternalErrors) {
 		mux.Handle(path, handler)
 	}
 
-	return mux, nil
+	if hOpts.RootsPollInterval > 0 || hOpts.ReloadRootsOnSIGHUP {
+		go reloadRoots(ctx, roots, hOpts.RootsPollInterval, hOpts.ReloadRootsOnSIGHUP)
+	}
+
+	return mux, func(ctx context.Context) error { return shutdownLogs(ctx, shutdowns) }, nil
+}
+
+// shutdownLogs shuts down logs keyed by origin concurrently, and waits for
+// all of them to be done.
+func shutdownLogs(ctx context.Context, shutdowns map[string]func(context.Context) error) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for origin, shutdown := range shutdowns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if err := shutdown(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("log %q: %v", origin, err))
+				mu.Unlock()
+				return
+			}
+			klog.Infof("Log %q shut down after %s", origin, time.Since(start))
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// reloadRoots reloads roots every interval if it's positive, and whenever the
+// process receives a SIGHUP if onSIGHUP is true, until ctx is done.
+func reloadRoots(ctx context.Context, roots []*ct.Roots, interval time.Duration, onSIGHUP bool) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	var hup chan os.Signal
+	if onSIGHUP {
+		hup = make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-hup:
+			klog.Info("Received SIGHUP, reloading roots")
+		}
+		for _, r := range roots {
+			if _, err := r.Reload(ctx); err != nil {
+				klog.Errorf("Failed to reload roots, keeping the current ones: %v", err)
+			}
+		}
+	}
+}
+
+// servedLog holds what's needed to serve a log.
+type servedLog struct {
+	// handlers are the HTTP handlers of the log, keyed by path.
+	handlers map[string]http.Handler
+	roots    *ct.Roots
+	shutdown func(context.Context) error
+	probe    ct.ReadinessProbe
+}
+
+// newServedLog creates a log, and everything needed to serve it.
+func newServedLog(ctx context.Context, l LogConfig, opts *ct.HandlerOptions, hOpts LogHandlerOpts) (*servedLog, error) {
+	cv, roots, err := newChainValidator(l.Origin, l.ChainValidationConfig)
+	if err != nil {
+		return nil, fmt.Errorf("newChainValidator(): %v", err)
+	}
+	log, err := ct.NewLog(ctx, l.Origin, l.Signer, l.DeterministicSCTs, cv, l.CreateStorage, sysTimeSource)
+	if err != nil {
+		return nil, fmt.Errorf("newLog(): %v", err)
+	}
+
+	handlers := ct.NewPathHandlers(ctx, opts, log)
+	if hOpts.EnableReadPath {
+		maps.Copy(handlers, ct.NewReadPathHandlers(ctx, opts, log))
+	}
+	if hOpts.EnableRFC6962ReadAPI {
+		maps.Copy(handlers, ct.NewRFC6962PathHandlers(ctx, opts, log))
+	}
+	if hOpts.EnableLeafIndexAPI {
+		maps.Copy(handlers, ct.NewLeafIndexPathHandlers(ctx, opts, log))
+	}
+	if hOpts.EnableValidateChain {
+		maps.Copy(handlers, ct.NewValidateChainPathHandlers(ctx, opts, log))
+	}
+
+	hs := make(map[string]http.Handler, len(handlers))
+	for path, handler := range handlers {
+		hs[path] = handler
+	}
+	return &servedLog{
+		handlers: hs,
+		roots:    roots,
+		shutdown: log.Shutdown,
+		probe: ct.NewReadinessProbe(log, ct.ReadinessOptions{
+			MaxCheckpointAge: staleCheckpointIntervals * l.CheckpointInterval,
+			MaxPushback:      hOpts.ReadinessMaxPushback,
+			TimeSource:       sysTimeSource,
+		}),
+	}, nil
 }
@@ -15,9 +15,27 @@
 package tesseract
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tessera/ctonly"
+	tposix "github.com/transparency-dev/tessera/storage/posix"
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/storage"
+	"github.com/transparency-dev/tesseract/storage/posix"
+	"golang.org/x/mod/sumdb/note"
 )
 
 func TestNewCertValidationOpts(t *testing.T) {
@@ -33,6 +51,21 @@ func TestNewCertValidationOpts(t *testing.T) {
 			desc:    "empty-rootsPemFile",
 			wantErr: "empty rootsPemFile",
 		},
+		{
+			desc:    "rootsPemFile-and-rootsManifestFile",
+			wantErr: "only one of rootsPemFile and rootsManifestFile can be set",
+			cvCfg: ChainValidationConfig{
+				RootsPEMFile:      "./internal/testdata/fake-ca.cert",
+				RootsManifestFile: "./internal/testdata/roots.yaml",
+			},
+		},
+		{
+			desc:    "missing-roots-manifest",
+			wantErr: "failed to read roots manifest",
+			cvCfg: ChainValidationConfig{
+				RootsManifestFile: "./internal/testdata/bogus.yaml",
+			},
+		},
 		{
 			desc:    "missing-root-cert",
 			wantErr: "failed to read trusted roots",
@@ -132,7 +165,7 @@ func TestNewCertValidationOpts(t *testing.T) {
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
-			vc, err := newChainValidator(tc.cvCfg)
+			vc, _, err := newChainValidator("example.com/log", tc.cvCfg)
 			if len(tc.wantErr) == 0 && err != nil {
 				t.Errorf("ValidateLogConfig()=%v, want nil", err)
 			}
@@ -145,3 +178,215 @@ func TestNewCertValidationOpts(t *testing.T) {
 		})
 	}
 }
+
+// newTestLogConfig returns the config of a log stored on a POSIX filesystem
+// under dir.
+func newTestLogConfig(t *testing.T, origin, rootsPEMFile, dir string) LogConfig {
+	t.Helper()
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	cs := func(ctx context.Context, signer note.Signer) (*storage.CTStorage, error) {
+		driver, err := tposix.New(ctx, filepath.Join(dir, "log"))
+		if err != nil {
+			return nil, err
+		}
+		appender, shutdown, reader, err := tessera.NewAppender(ctx, driver, tessera.NewAppendOptions().WithCheckpointSigner(signer).WithCTLayout())
+		if err != nil {
+			return nil, err
+		}
+		issuerStorage, err := posix.NewIssuerStorage(filepath.Join(dir, "issuer"))
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewCTStorage(ctx, appender, shutdown, issuerStorage, reader, false)
+	}
+	return LogConfig{
+		Origin:                origin,
+		Signer:                signer,
+		ChainValidationConfig: ChainValidationConfig{RootsPEMFile: rootsPEMFile},
+		CreateStorage:         cs,
+	}
+}
+
+func TestNewLogsHandler(t *testing.T) {
+	logs := []LogConfig{
+		newTestLogConfig(t, "example.com/2025h1", "./internal/testdata/fake-ca.cert", t.TempDir()),
+		newTestLogConfig(t, "example.com/2025h2", "./internal/testdata/test_root_ca_cert.pem", t.TempDir()),
+	}
+	handler, shutdown, err := NewLogsHandler(t.Context(), logs, LogHandlerOpts{HTTPDeadline: time.Second})
+	if err != nil {
+		t.Fatalf("NewLogsHandler(): %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	defer func() {
+		if err := shutdown(t.Context()); err != nil {
+			t.Errorf("shutdown(): %v", err)
+		}
+	}()
+
+	// Each log serves its own roots.
+	var roots []string
+	for _, l := range logs {
+		resp, err := http.Get(server.URL + "/" + l.Origin + rfc6962.GetRootsPath)
+		if err != nil {
+			t.Fatalf("get-roots(%q): %v", l.Origin, err)
+		}
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("get-roots(%q): got status %d, want %d", l.Origin, got, want)
+		}
+		var rsp rfc6962.GetRootsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&rsp); err != nil {
+			t.Fatalf("get-roots(%q): failed to decode response: %v", l.Origin, err)
+		}
+		_ = resp.Body.Close()
+		if got, want := len(rsp.Certificates), 1; got != want {
+			t.Fatalf("get-roots(%q): got %d roots, want %d", l.Origin, got, want)
+		}
+		roots = append(roots, rsp.Certificates[0])
+	}
+	if roots[0] == roots[1] {
+		t.Errorf("logs %q and %q serve the same roots", logs[0].Origin, logs[1].Origin)
+	}
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("healthz: %v", err)
+	}
+	_ = resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("healthz: got status %d, want %d", got, want)
+	}
+
+	resp, err = http.Get(server.URL + "/example.com/2025h3" + rfc6962.GetRootsPath)
+	if err != nil {
+		t.Fatalf("get-roots(unknown log): %v", err)
+	}
+	_ = resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+		t.Errorf("get-roots(unknown log): got status %d, want %d", got, want)
+	}
+}
+
+// getRootsCount returns the number of roots served by the log at url.
+func getRootsCount(t *testing.T, url string) int {
+	t.Helper()
+	resp, err := http.Get(url + rfc6962.GetRootsPath)
+	if err != nil {
+		t.Fatalf("get-roots: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var rsp rfc6962.GetRootsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rsp); err != nil {
+		t.Fatalf("get-roots: failed to decode response: %v", err)
+	}
+	return len(rsp.Certificates)
+}
+
+func TestNewLogsHandlerReloadsRoots(t *testing.T) {
+	dir := t.TempDir()
+	rootsPEMFile := filepath.Join(dir, "roots.pem")
+	fakeCA, err := os.ReadFile("./internal/testdata/fake-ca.cert")
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	testRoot, err := os.ReadFile("./internal/testdata/test_root_ca_cert.pem")
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	if err := os.WriteFile(rootsPEMFile, fakeCA, 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	logs := []LogConfig{newTestLogConfig(t, "example.com/log", rootsPEMFile, dir)}
+	handler, _, err := NewLogsHandler(t.Context(), logs, LogHandlerOpts{HTTPDeadline: time.Second, RootsPollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewLogsHandler(): %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	url := server.URL + "/example.com/log"
+
+	if got, want := getRootsCount(t, url), 1; got != want {
+		t.Fatalf("get-roots: got %d roots, want %d", got, want)
+	}
+	if err := os.WriteFile(rootsPEMFile, append(fakeCA, testRoot...), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); getRootsCount(t, url) != 2; {
+		if time.Now().After(deadline) {
+			t.Fatal("Roots were not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewLogsHandlerErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		logs    []LogConfig
+		wantErr string
+		// wantCreated is the number of logs created before the error.
+		wantCreated int
+	}{
+		{
+			desc:    "no-logs",
+			wantErr: "no log to serve",
+		},
+		{
+			desc: "duplicate-origin",
+			logs: []LogConfig{
+				newTestLogConfig(t, "example.com/log", "./internal/testdata/fake-ca.cert", t.TempDir()),
+				newTestLogConfig(t, "example.com/log/", "./internal/testdata/fake-ca.cert", t.TempDir()),
+			},
+			wantErr:     "is already served by log",
+			wantCreated: 2,
+		},
+		{
+			desc: "invalid-log-config",
+			logs: []LogConfig{
+				newTestLogConfig(t, "example.com/log", "", t.TempDir()),
+			},
+			wantErr: "empty rootsPemFile",
+		},
+		{
+			desc: "invalid-second-log-config",
+			logs: []LogConfig{
+				newTestLogConfig(t, "example.com/log1", "./internal/testdata/fake-ca.cert", t.TempDir()),
+				newTestLogConfig(t, "example.com/log2", "", t.TempDir()),
+			},
+			wantErr:     "empty rootsPemFile",
+			wantCreated: 1,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			// Record the storage of the logs which get created.
+			var created []*storage.CTStorage
+			for i, l := range tc.logs {
+				tc.logs[i].CreateStorage = func(ctx context.Context, signer note.Signer) (*storage.CTStorage, error) {
+					s, err := l.CreateStorage(ctx, signer)
+					if err == nil {
+						created = append(created, s)
+					}
+					return s, err
+				}
+			}
+
+			_, _, err := NewLogsHandler(t.Context(), tc.logs, LogHandlerOpts{})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("NewLogsHandler()=%v, want err containing %q", err, tc.wantErr)
+			}
+			if got := len(created); got != tc.wantCreated {
+				t.Errorf("%d logs created, want %d", got, tc.wantCreated)
+			}
+			// Logs which were created are shut down.
+			for i, s := range created {
+				if _, _, err := s.Add(t.Context(), &ctonly.Entry{}); !errors.Is(err, storage.ErrShuttingDown) {
+					t.Errorf("log %d Add()=%v, want %v", i, err, storage.ErrShuttingDown)
+				}
+			}
+		})
+	}
+}
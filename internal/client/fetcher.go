@@ -106,6 +106,11 @@ func (h HTTPFetcher) ReadEntryBundle(ctx context.Context, i uint64, p uint8) ([]
 	return h.fetch(ctx, ctEntriesPath(i, p))
 }
 
+// ReadIssuer returns the issuer certificate stored under its hex encoded sha256.
+func (h HTTPFetcher) ReadIssuer(ctx context.Context, key []byte) ([]byte, error) {
+	return h.fetch(ctx, ctIssuerPath(key))
+}
+
 // FileFetcher knows how to fetch log artifacts from a filesystem rooted at Root.
 type FileFetcher struct {
 	Root string
@@ -123,6 +128,15 @@ func (f FileFetcher) ReadEntryBundle(_ context.Context, i uint64, p uint8) ([]by
 	return os.ReadFile(path.Join(f.Root, ctEntriesPath(i, p)))
 }
 
+// ReadIssuer returns the issuer certificate stored under its hex encoded sha256.
+func (f FileFetcher) ReadIssuer(_ context.Context, key []byte) ([]byte, error) {
+	return os.ReadFile(path.Join(f.Root, ctIssuerPath(key)))
+}
+
 func ctEntriesPath(n uint64, p uint8) string {
 	return fmt.Sprintf("tile/data/%s", layout.NWithSuffix(0, n, p))
 }
+
+func ctIssuerPath(key []byte) string {
+	return fmt.Sprintf("issuer/%s", key)
+}
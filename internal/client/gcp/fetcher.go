@@ -16,13 +16,20 @@ package gcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path"
 
 	gcs "cloud.google.com/go/storage"
 	"github.com/transparency-dev/tessera/api/layout"
 )
 
+// issuerPrefix is the prefix under which TesseraCT stores issuers in GCS
+// buckets, see cmd/gcp.
+const issuerPrefix = "fingerprints"
+
 // NewGSFetcher creates a new GSFetcher for the Google Cloud Storage bucket, using
 // the provided GCS client.
 //
@@ -51,6 +58,10 @@ type GSFetcher struct {
 func (f GSFetcher) fetch(ctx context.Context, p string) ([]byte, error) {
 	r, err := f.c.Bucket(f.bucket).Object(p).NewReader(ctx)
 	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			// Need to return ErrNotExist here, by contract.
+			return nil, fmt.Errorf("getObject: object %q not found in bucket %q: %w", p, f.bucket, os.ErrNotExist)
+		}
 		return nil, fmt.Errorf("getObject: failed to create reader for object %q in bucket %q: %w", p, f.bucket, err)
 	}
 
@@ -72,3 +83,8 @@ func (f GSFetcher) ReadTile(ctx context.Context, l, i uint64, p uint8) ([]byte,
 func (f GSFetcher) ReadEntryBundle(ctx context.Context, i uint64, p uint8) ([]byte, error) {
 	return f.fetch(ctx, fmt.Sprintf("tile/data/%s", layout.NWithSuffix(0, i, p)))
 }
+
+// ReadIssuer returns the issuer certificate stored under its hex encoded sha256.
+func (f GSFetcher) ReadIssuer(ctx context.Context, key []byte) ([]byte, error) {
+	return f.fetch(ctx, path.Join(issuerPrefix, string(key)))
+}
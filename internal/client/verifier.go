@@ -0,0 +1,136 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/types/tls"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// rfc6962SignatureType is the note signature type of RFC 6962 checkpoint
+// signatures, see https://c2sp.org/static-ct-api.
+const rfc6962SignatureType = 0x05
+
+// rfc6962NoteSignature is the signature of a https://c2sp.org/static-ct-api
+// checkpoint, following its key hash.
+type rfc6962NoteSignature struct {
+	Timestamp uint64
+	Signature tls.DigitallySigned
+}
+
+// rfc6962Verifier implements note.Verifier for https://c2sp.org/static-ct-api
+// checkpoints.
+type rfc6962Verifier struct {
+	origin    string
+	keyHash   uint32
+	publicKey crypto.PublicKey
+}
+
+// NewRFC6962Verifier returns a note verifier for https://c2sp.org/static-ct-api
+// checkpoints signed by the log with the given origin and ECDSA or RSA public
+// key.
+func NewRFC6962Verifier(origin string, publicKey crypto.PublicKey) (note.Verifier, error) {
+	if origin == "" {
+		return nil, errors.New("empty origin")
+	}
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %v", err)
+	}
+	logID := sha256.Sum256(der)
+
+	h := sha256.New()
+	h.Write([]byte(origin))
+	h.Write([]byte{0x0A, rfc6962SignatureType})
+	h.Write(logID[:])
+	return &rfc6962Verifier{
+		origin:    origin,
+		keyHash:   binary.BigEndian.Uint32(h.Sum(nil)),
+		publicKey: publicKey,
+	}, nil
+}
+
+// Name returns the origin of the log.
+func (v *rfc6962Verifier) Name() string {
+	return v.origin
+}
+
+// KeyHash returns the key hash of the log's checkpoint signatures.
+func (v *rfc6962Verifier) KeyHash() uint32 {
+	return v.keyHash
+}
+
+// Verify checks that sig is a valid RFC 6962 tree head signature of the
+// checkpoint in msg.
+func (v *rfc6962Verifier) Verify(msg, sig []byte) bool {
+	var ns rfc6962NoteSignature
+	if rest, err := tls.Unmarshal(sig, &ns); err != nil || len(rest) > 0 {
+		return false
+	}
+	if ns.Signature.Algorithm.Hash != tls.SHA256 {
+		return false
+	}
+	if ns.Signature.Algorithm.Signature != tls.SignatureAlgorithmFromPubKey(v.publicKey) {
+		return false
+	}
+
+	// Checkpoint extension lines are not covered by RFC 6962 signatures, so
+	// checkpoints with extension lines are rejected rather than returned
+	// with unauthenticated content.
+	cp := &log.Checkpoint{}
+	if rest, err := cp.Unmarshal(msg); err != nil || len(rest) > 0 {
+		return false
+	}
+	if cp.Origin != v.origin || len(cp.Hash) != sha256.Size {
+		return false
+	}
+	ths := rfc6962.TreeHeadSignature{
+		Version:       rfc6962.V1,
+		SignatureType: rfc6962.TreeHashSignatureType,
+		Timestamp:     ns.Timestamp,
+		TreeSize:      cp.Size,
+	}
+	copy(ths.SHA256RootHash[:], cp.Hash)
+	input, err := tls.Marshal(ths)
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256(input)
+
+	switch pub := v.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(pub, digest[:], ns.Signature.Signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], ns.Signature.Signature) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/transparency-dev/formats/log"
+	tdnote "github.com/transparency-dev/formats/note"
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/types/tls"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// testCpSigner signs checkpoints with RFC 6962 tree head signatures.
+type testCpSigner struct {
+	note.Verifier
+	signer crypto.Signer
+	// alg overrides the signature algorithm advertised in signatures.
+	alg tls.SignatureAlgorithm
+}
+
+func (s *testCpSigner) Sign(msg []byte) ([]byte, error) {
+	cp := &log.Checkpoint{}
+	if _, err := cp.Unmarshal(msg); err != nil {
+		return nil, err
+	}
+	ths := rfc6962.TreeHeadSignature{
+		Version:       rfc6962.V1,
+		SignatureType: rfc6962.TreeHashSignatureType,
+		Timestamp:     1234,
+		TreeSize:      cp.Size,
+	}
+	copy(ths.SHA256RootHash[:], cp.Hash)
+	input, err := tls.Marshal(ths)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(input)
+	sig, err := s.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	alg := s.alg
+	if alg == tls.Anonymous {
+		alg = tls.SignatureAlgorithmFromPubKey(s.signer.Public())
+	}
+	return tls.Marshal(rfc6962NoteSignature{
+		Timestamp: ths.Timestamp,
+		Signature: tls.DigitallySigned{
+			Algorithm: tls.SignatureAndHashAlgorithm{Hash: tls.SHA256, Signature: alg},
+			Signature: sig,
+		},
+	})
+}
+
+func TestRFC6962Verifier(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	cp := log.Checkpoint{Origin: testOrigin, Size: 42, Hash: make([]byte, sha256.Size)}
+
+	for _, tc := range []struct {
+		desc   string
+		signer crypto.Signer
+		key    crypto.PublicKey
+		alg    tls.SignatureAlgorithm
+		body   string
+		want   bool
+	}{
+		{
+			desc:   "ecdsa",
+			signer: ecdsaKey,
+			key:    ecdsaKey.Public(),
+			want:   true,
+		},
+		{
+			desc:   "rsa",
+			signer: rsaKey,
+			key:    rsaKey.Public(),
+			want:   true,
+		},
+		{
+			desc:   "wrong-key",
+			signer: otherKey,
+			key:    ecdsaKey.Public(),
+		},
+		{
+			desc:   "wrong-algorithm",
+			signer: rsaKey,
+			key:    rsaKey.Public(),
+			alg:    tls.ECDSA,
+		},
+		{
+			// The signature over the checkpoint is valid, but doesn't cover
+			// its extension lines.
+			desc:   "extension-lines",
+			signer: ecdsaKey,
+			key:    ecdsaKey.Public(),
+			body:   string(cp.Marshal()) + "extension\n",
+		},
+		{
+			desc:   "wrong-origin",
+			signer: ecdsaKey,
+			key:    ecdsaKey.Public(),
+			body:   string((&log.Checkpoint{Origin: "other.example.com/log", Size: cp.Size, Hash: cp.Hash}).Marshal()),
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			v, err := NewRFC6962Verifier(testOrigin, tc.key)
+			if err != nil {
+				t.Fatalf("NewRFC6962Verifier(): %v", err)
+			}
+			body := tc.body
+			if body == "" {
+				body = string(cp.Marshal())
+			}
+			msg, err := note.Sign(&note.Note{Text: body}, &testCpSigner{Verifier: v, signer: tc.signer, alg: tc.alg})
+			if err != nil {
+				t.Fatalf("note.Sign(): %v", err)
+			}
+			_, err = note.Open(msg, note.VerifierList(v))
+			if got := err == nil; got != tc.want {
+				t.Errorf("note.Open()=%v, want success %t", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRFC6962VerifierKeyHash(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	v, err := NewRFC6962Verifier(testOrigin, key.Public())
+	if err != nil {
+		t.Fatalf("NewRFC6962Verifier(): %v", err)
+	}
+	vkey, err := tdnote.RFC6962VerifierString(testOrigin, key.Public())
+	if err != nil {
+		t.Fatalf("RFC6962VerifierString(): %v", err)
+	}
+	if want := fmt.Sprintf("%s+%08x+", testOrigin, v.KeyHash()); vkey[:len(want)] != want {
+		t.Errorf("Got verifier key %q, want prefix %q", vkey, want)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	if _, err := NewRFC6962Verifier(testOrigin, edKey.Public()); err == nil {
+		t.Error("NewRFC6962Verifier(ed25519)=nil, want err")
+	}
+}
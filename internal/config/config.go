@@ -0,0 +1,630 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config defines the configuration of TesseraCT servers.
+//
+// A configuration can be loaded from a YAML or JSON file, and overridden by
+// command line flags. The same schema is used by all TesseraCT binaries, with
+// a storage block specific to each backend.
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tesseract"
+	"github.com/transparency-dev/tesseract/internal/signer"
+	"github.com/transparency-dev/tesseract/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Backend identifies a TesseraCT storage backend.
+type Backend string
+
+const (
+	GCP   Backend = "gcp"
+	AWS   Backend = "aws"
+	POSIX Backend = "posix"
+)
+
+// Default values, used when a field is not set or set to its zero value.
+const (
+	DefaultHTTPEndpoint              = "localhost:6962"
+	DefaultHTTPDeadline              = 10 * time.Second
+	DefaultShutdownTimeout           = 60 * time.Second
+	DefaultReadinessMaxPushback      = time.Minute
+	MinRootsPollInterval             = 10 * time.Second
+	DefaultCheckpointInterval        = 1500 * time.Millisecond
+	DefaultInMemoryAntispamCacheSize = 256 << 10
+	DefaultAWSDBPort                 = 3306
+	DefaultAWSDBMaxIdleConns         = 2
+	DefaultRequestLogMaxSizeMB       = 100
+	DefaultRequestLogMaxBackups      = 5
+	DefaultRequestLogSampleRate      = 1.0
+)
+
+// Config is the configuration of a TesseraCT server.
+type Config struct {
+	// HTTPEndpoint is the endpoint to serve HTTP requests on (host:port).
+	HTTPEndpoint string `yaml:"http_endpoint,omitempty"`
+	// HTTPDeadline is the deadline for HTTP requests.
+	HTTPDeadline time.Duration `yaml:"http_deadline,omitempty"`
+	// ShutdownTimeout bounds how long the server waits on shutdown for
+	// pending requests to finish, and for accepted entries to be integrated
+	// and published.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout,omitempty"`
+	// ReadinessMaxPushback is how long the storage of a log may push back on
+	// new entries without interruption before the server is reported as not
+	// ready.
+	ReadinessMaxPushback time.Duration `yaml:"readiness_max_pushback,omitempty"`
+	// MaskInternalErrors masks error strings in Internal Server Error HTTP
+	// responses.
+	MaskInternalErrors bool `yaml:"mask_internal_errors,omitempty"`
+	// EnableReadPath serves the static-ct-api read path from the logs storage.
+	EnableReadPath bool `yaml:"enable_read_path,omitempty"`
+	// EnableRFC6962ReadAPI serves RFC 6962 read endpoints, synthesized from
+	// the logs storage.
+	EnableRFC6962ReadAPI bool `yaml:"enable_rfc6962_read_api,omitempty"`
+	// EnableValidateChain serves the non-standard validate-chain endpoint,
+	// to check chains without logging them.
+	EnableValidateChain bool `yaml:"enable_validate_chain,omitempty"`
+	// RootsPollInterval is the interval at which roots files are polled, and
+	// reloaded. It must be at least MinRootsPollInterval. Zero disables
+	// periodic reloads. Roots are always reloaded on SIGHUP.
+	RootsPollInterval time.Duration `yaml:"roots_poll_interval,omitempty"`
+	// RequestLog configures structured request logs.
+	RequestLog RequestLog `yaml:"request_log,omitempty"`
+	// OTel configures OpenTelemetry exporters, where supported.
+	OTel OTel `yaml:"otel,omitempty"`
+	// Logs lists the logs served by the server.
+	Logs []Log `yaml:"logs"`
+}
+
+// RequestLog configures structured request logs, written as newline-delimited
+// JSON.
+type RequestLog struct {
+	// Path is the file to write request records to, or "-" for stdout.
+	// Request logs are disabled if empty.
+	Path string `yaml:"path,omitempty"`
+	// MaxSizeMB is the size in megabytes after which the file is rotated.
+	MaxSizeMB uint `yaml:"max_size_mb,omitempty"`
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups uint `yaml:"max_backups,omitempty"`
+	// SampleRate is the fraction of requests which are logged, in (0, 1].
+	SampleRate float64 `yaml:"sample_rate,omitempty"`
+	// RedactNames omits the subject and issuer names of submitted
+	// certificates from records.
+	RedactNames bool `yaml:"redact_names,omitempty"`
+}
+
+// OTel configures OpenTelemetry exporters.
+type OTel struct {
+	// TraceFraction is the fraction of span traces to sample.
+	TraceFraction float64 `yaml:"trace_fraction,omitempty"`
+	// ProjectID is the GCP project ID for OpenTelemetry exporters. This is
+	// only required for local runs.
+	ProjectID string `yaml:"project_id,omitempty"`
+}
+
+// Log is the configuration of a single log.
+type Log struct {
+	// Origin of the log, for checkpoints and the URL prefix of its endpoints.
+	Origin string `yaml:"origin"`
+	// Signer configures the key signing checkpoints and SCTs.
+	Signer Signer `yaml:"signer"`
+	// DeterministicSCTs controls whether SCTs are signed deterministically,
+	// such that resubmissions of a leaf with the same timestamp get
+	// byte-identical SCTs.
+	DeterministicSCTs bool `yaml:"deterministic_scts,omitempty"`
+	// ChainValidation configures which chains the log accepts.
+	ChainValidation ChainValidation `yaml:"chain_validation"`
+	// Tessera configures the Tessera library.
+	Tessera Tessera `yaml:"tessera,omitempty"`
+	// Storage configures where the log is stored.
+	Storage Storage `yaml:"storage"`
+	// LeafIndexDBPath is the path to the Badger database mapping Merkle leaf
+	// hashes to entry indices. Leaf hash lookups are disabled if empty.
+	LeafIndexDBPath string `yaml:"leaf_index_db_path,omitempty"`
+	// TimestampCacheSize is the maximum number of entry timestamps cached in
+	// memory to answer duplicate submissions.
+	TimestampCacheSize uint `yaml:"timestamp_cache_size,omitempty"`
+}
+
+// Signer configures where to load the key signing checkpoints and SCTs from.
+// Exactly one of secrets, PrivateKeyFile and PKCS11 must be set.
+type Signer struct {
+	// PublicKeySecretName is the name of the public key secret, for GCP and AWS.
+	PublicKeySecretName string `yaml:"public_key_secret_name,omitempty"`
+	// PrivateKeySecretName is the name of the private key secret, for GCP and AWS.
+	PrivateKeySecretName string `yaml:"private_key_secret_name,omitempty"`
+	// PrivateKeyFile is the path to a PEM encoded private key.
+	PrivateKeyFile string `yaml:"private_key_file,omitempty"`
+	// PublicKeyFile is the path to a PEM encoded public key, which must match
+	// PrivateKeyFile if set.
+	PublicKeyFile string `yaml:"public_key_file,omitempty"`
+	// PKCS11 configures a key held by a PKCS #11 token.
+	PKCS11 *PKCS11Signer `yaml:"pkcs11,omitempty"`
+}
+
+// PKCS11Signer configures a key held by a PKCS #11 token, such as an HSM.
+type PKCS11Signer struct {
+	// ModulePath is the path to the PKCS #11 library of the token.
+	ModulePath string `yaml:"module_path"`
+	// TokenLabel is the label of the token holding the key.
+	TokenLabel string `yaml:"token_label"`
+	// PIN is the user PIN of the token.
+	PIN string `yaml:"pin,omitempty"`
+	// KeyLabel is the label of the key pair.
+	KeyLabel string `yaml:"key_label,omitempty"`
+	// KeyID is the hex encoded ID of the key pair.
+	KeyID string `yaml:"key_id,omitempty"`
+}
+
+// ChainValidation mirrors tesseract.ChainValidationConfig.
+type ChainValidation struct {
+	RootsPEMFile         string     `yaml:"roots_pem_file,omitempty"`
+	RootsManifestFile    string     `yaml:"roots_manifest_file,omitempty"`
+	RejectExpired        bool       `yaml:"reject_expired,omitempty"`
+	RejectUnexpired      bool       `yaml:"reject_unexpired,omitempty"`
+	ExtKeyUsages         string     `yaml:"ext_key_usages,omitempty"`
+	RejectExtensions     string     `yaml:"reject_extensions,omitempty"`
+	NotAfterStart        *time.Time `yaml:"not_after_start,omitempty"`
+	NotAfterLimit        *time.Time `yaml:"not_after_limit,omitempty"`
+	LenientChainOrdering bool       `yaml:"lenient_chain_ordering,omitempty"`
+	CompleteChains       bool       `yaml:"complete_chains,omitempty"`
+	PolicyFile           string     `yaml:"policy_file,omitempty"`
+	WeakKeys             string     `yaml:"weak_keys,omitempty"`
+	DebianWeakKeysFile   string     `yaml:"debian_weak_keys_file,omitempty"`
+	CompromisedKeysFile  string     `yaml:"compromised_keys_file,omitempty"`
+}
+
+// Tessera configures the Tessera library.
+type Tessera struct {
+	CheckpointInterval        time.Duration `yaml:"checkpoint_interval,omitempty"`
+	BatchMaxSize              uint          `yaml:"batch_max_size,omitempty"`
+	BatchMaxAge               time.Duration `yaml:"batch_max_age,omitempty"`
+	PushbackMaxOutstanding    uint          `yaml:"pushback_max_outstanding,omitempty"`
+	InMemoryAntispamCacheSize uint          `yaml:"inmemory_antispam_cache_size,omitempty"`
+	// EnablePublicationAwaiter integrates certificates into the log before
+	// returning SCTs.
+	EnablePublicationAwaiter bool `yaml:"enable_publication_awaiter,omitempty"`
+}
+
+// Storage configures where a log is stored. Exactly one block must be set,
+// matching the backend of the binary loading the config.
+type Storage struct {
+	GCP   *GCPStorage   `yaml:"gcp,omitempty"`
+	AWS   *AWSStorage   `yaml:"aws,omitempty"`
+	POSIX *POSIXStorage `yaml:"posix,omitempty"`
+}
+
+// GCPStorage stores a log in GCS and Spanner.
+type GCPStorage struct {
+	Bucket                string `yaml:"bucket"`
+	SpannerDBPath         string `yaml:"spanner_db_path"`
+	SpannerAntispamDBPath string `yaml:"spanner_antispam_db_path,omitempty"`
+}
+
+// AWSStorage stores a log in S3 and AuroraDB.
+type AWSStorage struct {
+	Bucket         string `yaml:"bucket"`
+	DBName         string `yaml:"db_name"`
+	AntispamDBName string `yaml:"antispam_db_name,omitempty"`
+	DBHost         string `yaml:"db_host"`
+	DBPort         int    `yaml:"db_port,omitempty"`
+	DBUser         string `yaml:"db_user"`
+	DBPassword     string `yaml:"db_password"`
+	DBMaxConns     int    `yaml:"db_max_conns,omitempty"`
+	DBMaxIdleConns int    `yaml:"db_max_idle_conns,omitempty"`
+}
+
+// POSIXStorage stores a log on a local filesystem.
+type POSIXStorage struct {
+	StorageDir     string `yaml:"storage_dir"`
+	AntispamDBPath string `yaml:"antispam_db_path,omitempty"`
+}
+
+// envVar matches ${VAR} references to environment variables.
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolate replaces ${VAR} references in b with the value of the
+// corresponding environment variables. Unset variables are an error.
+func interpolate(b []byte) ([]byte, error) {
+	var errs []error
+	out := envVar.ReplaceAllFunc(b, func(m []byte) []byte {
+		name := string(envVar.FindSubmatch(m)[1])
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("environment variable %q is not set", name))
+		}
+		return []byte(v)
+	})
+	return out, errors.Join(errs...)
+}
+
+// Parse parses a YAML or JSON configuration, after interpolating environment
+// variables. Unknown fields are an error.
+//
+// Defaults are not applied, and the configuration is not validated.
+func Parse(b []byte) (*Config, error) {
+	b, err := interpolate(b)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	cfg := &Config{}
+	if err := dec.Decode(cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty config")
+		}
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return nil, errors.New("config must contain a single document")
+	}
+	return cfg, nil
+}
+
+// Load reads and parses the configuration file at path.
+//
+// Defaults are not applied, and the configuration is not validated.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	return Parse(b)
+}
+
+// SetDefaults sets fields left to their zero value to their default value.
+func (c *Config) SetDefaults() {
+	if c.HTTPEndpoint == "" {
+		c.HTTPEndpoint = DefaultHTTPEndpoint
+	}
+	if c.HTTPDeadline == 0 {
+		c.HTTPDeadline = DefaultHTTPDeadline
+	}
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if c.ReadinessMaxPushback == 0 {
+		c.ReadinessMaxPushback = DefaultReadinessMaxPushback
+	}
+	if r := &c.RequestLog; r.Path != "" {
+		if r.MaxSizeMB == 0 {
+			r.MaxSizeMB = DefaultRequestLogMaxSizeMB
+		}
+		if r.MaxBackups == 0 {
+			r.MaxBackups = DefaultRequestLogMaxBackups
+		}
+		if r.SampleRate == 0 {
+			r.SampleRate = DefaultRequestLogSampleRate
+		}
+	}
+	for i := range c.Logs {
+		if c.Logs[i].TimestampCacheSize == 0 {
+			c.Logs[i].TimestampCacheSize = storage.DefaultTimestampCacheSize
+		}
+		t := &c.Logs[i].Tessera
+		if t.CheckpointInterval == 0 {
+			t.CheckpointInterval = DefaultCheckpointInterval
+		}
+		if t.BatchMaxSize == 0 {
+			t.BatchMaxSize = tessera.DefaultBatchMaxSize
+		}
+		if t.BatchMaxAge == 0 {
+			t.BatchMaxAge = tessera.DefaultBatchMaxAge
+		}
+		if t.PushbackMaxOutstanding == 0 {
+			t.PushbackMaxOutstanding = tessera.DefaultPushbackMaxOutstanding
+		}
+		if t.InMemoryAntispamCacheSize == 0 {
+			t.InMemoryAntispamCacheSize = DefaultInMemoryAntispamCacheSize
+		}
+		if s := c.Logs[i].Storage.AWS; s != nil {
+			if s.DBPort == 0 {
+				s.DBPort = DefaultAWSDBPort
+			}
+			if s.DBMaxIdleConns == 0 {
+				s.DBMaxIdleConns = DefaultAWSDBMaxIdleConns
+			}
+		}
+	}
+}
+
+// Validate checks that the configuration can be used by a binary running the
+// given backend.
+func (c *Config) Validate(backend Backend) error {
+	if len(c.Logs) == 0 {
+		return errors.New("no log configured")
+	}
+	if c.RootsPollInterval < 0 {
+		return fmt.Errorf("negative roots_poll_interval %v", c.RootsPollInterval)
+	}
+	if c.RootsPollInterval > 0 && c.RootsPollInterval < MinRootsPollInterval {
+		return fmt.Errorf("roots_poll_interval %v is less than %v", c.RootsPollInterval, MinRootsPollInterval)
+	}
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("negative shutdown_timeout %v", c.ShutdownTimeout)
+	}
+	if c.ReadinessMaxPushback < 0 {
+		return fmt.Errorf("negative readiness_max_pushback %v", c.ReadinessMaxPushback)
+	}
+	if r := c.RequestLog; r.Path != "" && (r.SampleRate <= 0 || r.SampleRate > 1) {
+		return fmt.Errorf("request_log: sample_rate %v is not in (0, 1]", r.SampleRate)
+	}
+	origins := make(map[string]bool)
+	paths := make(map[string]bool)
+	var errs []error
+	for i, l := range c.Logs {
+		if err := l.validate(backend); err != nil {
+			errs = append(errs, fmt.Errorf("logs[%d] (%q): %v", i, l.Origin, err))
+		}
+		if origins[l.Origin] {
+			errs = append(errs, fmt.Errorf("logs[%d]: duplicate origin %q", i, l.Origin))
+		}
+		origins[l.Origin] = true
+		// Local databases and directories can't be shared between logs.
+		for _, p := range l.localPaths() {
+			if paths[p] {
+				errs = append(errs, fmt.Errorf("logs[%d] (%q): path %q is used by another log", i, l.Origin, p))
+			}
+			paths[p] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l Log) validate(backend Backend) error {
+	var errs []error
+	if l.Origin == "" {
+		errs = append(errs, errors.New("missing origin"))
+	}
+
+	switch backend {
+	case GCP, AWS, POSIX:
+		if err := l.Signer.validate(backend); err != nil {
+			errs = append(errs, fmt.Errorf("signer: %v", err))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown backend %q", backend))
+	}
+
+	cv := l.ChainValidation
+	if (cv.RootsPEMFile == "") == (cv.RootsManifestFile == "") {
+		errs = append(errs, errors.New("chain_validation: exactly one of roots_pem_file and roots_manifest_file must be set"))
+	}
+	for name, t := range map[string]*time.Time{"not_after_start": cv.NotAfterStart, "not_after_limit": cv.NotAfterLimit} {
+		if t != nil && t.Location() != time.UTC {
+			errs = append(errs, fmt.Errorf("chain_validation: %s must be in UTC, got %v", name, t))
+		}
+	}
+	switch cv.WeakKeys {
+	case "", "flag", "reject":
+	default:
+		errs = append(errs, fmt.Errorf("chain_validation: weak_keys must be flag or reject, got %q", cv.WeakKeys))
+	}
+	if cv.NotAfterStart != nil && cv.NotAfterLimit != nil && cv.NotAfterLimit.Before(*cv.NotAfterStart) {
+		errs = append(errs, fmt.Errorf("chain_validation: not_after_limit %v before not_after_start %v", cv.NotAfterLimit, cv.NotAfterStart))
+	}
+	if cv.RejectExpired && cv.RejectUnexpired {
+		errs = append(errs, errors.New("chain_validation: reject_expired and reject_unexpired would reject all certificates"))
+	}
+
+	if err := l.Storage.validate(backend); err != nil {
+		errs = append(errs, fmt.Errorf("storage: %v", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (s Signer) validate(backend Backend) error {
+	secrets := s.PublicKeySecretName != "" || s.PrivateKeySecretName != ""
+	if secrets && backend == POSIX {
+		return fmt.Errorf("secrets are not supported by the %s backend", backend)
+	}
+	n := 0
+	for _, set := range []bool{secrets, s.PrivateKeyFile != "", s.PKCS11 != nil} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of secrets, private_key_file and pkcs11 must be set")
+	}
+
+	switch {
+	case secrets:
+		if s.PublicKeySecretName == "" || s.PrivateKeySecretName == "" {
+			return errors.New("missing public_key_secret_name or private_key_secret_name")
+		}
+	case s.PKCS11 != nil:
+		p := s.PKCS11
+		if p.ModulePath == "" || p.TokenLabel == "" {
+			return errors.New("pkcs11: missing module_path or token_label")
+		}
+		if p.KeyLabel == "" && p.KeyID == "" {
+			return errors.New("pkcs11: missing key_label or key_id")
+		}
+		if _, err := hex.DecodeString(p.KeyID); err != nil {
+			return fmt.Errorf("pkcs11: invalid key_id: %v", err)
+		}
+	}
+	if s.PublicKeyFile != "" && s.PrivateKeyFile == "" {
+		return errors.New("public_key_file requires private_key_file")
+	}
+	return nil
+}
+
+func (s Storage) validate(backend Backend) error {
+	set := map[Backend]bool{GCP: s.GCP != nil, AWS: s.AWS != nil, POSIX: s.POSIX != nil}
+	for b, ok := range set {
+		if ok && b != backend {
+			return fmt.Errorf("%s storage is not supported by the %s backend", b, backend)
+		}
+	}
+	if !set[backend] {
+		return fmt.Errorf("missing %s block", backend)
+	}
+
+	var errs []error
+	missing := func(field, v string) {
+		if v == "" {
+			errs = append(errs, fmt.Errorf("%s: missing %s", backend, field))
+		}
+	}
+	switch backend {
+	case GCP:
+		missing("bucket", s.GCP.Bucket)
+		missing("spanner_db_path", s.GCP.SpannerDBPath)
+	case AWS:
+		missing("bucket", s.AWS.Bucket)
+		missing("db_name", s.AWS.DBName)
+		missing("db_host", s.AWS.DBHost)
+		missing("db_user", s.AWS.DBUser)
+		// Empty password isn't an option with AuroraDB MySQL.
+		missing("db_password", s.AWS.DBPassword)
+		if s.AWS.DBPort == 0 {
+			errs = append(errs, fmt.Errorf("%s: missing db_port", backend))
+		}
+	case POSIX:
+		missing("storage_dir", s.POSIX.StorageDir)
+	}
+	return errors.Join(errs...)
+}
+
+// localPaths returns the local filesystem paths used by the log.
+func (l Log) localPaths() []string {
+	var ps []string
+	if l.LeafIndexDBPath != "" {
+		ps = append(ps, l.LeafIndexDBPath)
+	}
+	if s := l.Storage.POSIX; s != nil {
+		ps = append(ps, s.StorageDir)
+		if s.AntispamDBPath != "" {
+			ps = append(ps, s.AntispamDBPath)
+		}
+	}
+	return ps
+}
+
+// ChainValidationConfig returns the chain validation config of the log.
+func (l Log) ChainValidationConfig() tesseract.ChainValidationConfig {
+	return tesseract.ChainValidationConfig{
+		RootsPEMFile:         l.ChainValidation.RootsPEMFile,
+		RootsManifestFile:    l.ChainValidation.RootsManifestFile,
+		RejectExpired:        l.ChainValidation.RejectExpired,
+		RejectUnexpired:      l.ChainValidation.RejectUnexpired,
+		ExtKeyUsages:         l.ChainValidation.ExtKeyUsages,
+		RejectExtensions:     l.ChainValidation.RejectExtensions,
+		NotAfterStart:        l.ChainValidation.NotAfterStart,
+		NotAfterLimit:        l.ChainValidation.NotAfterLimit,
+		LenientChainOrdering: l.ChainValidation.LenientChainOrdering,
+		CompleteChains:       l.ChainValidation.CompleteChains,
+		PolicyFile:           l.ChainValidation.PolicyFile,
+		WeakKeys:             l.ChainValidation.WeakKeys,
+		DebianWeakKeysFile:   l.ChainValidation.DebianWeakKeysFile,
+		CompromisedKeysFile:  l.ChainValidation.CompromisedKeysFile,
+	}
+}
+
+// LogHandlerOpts returns the options of the HTTP handlers serving the logs.
+func (c *Config) LogHandlerOpts() tesseract.LogHandlerOpts {
+	opts := tesseract.LogHandlerOpts{
+		HTTPDeadline:         c.HTTPDeadline,
+		MaskInternalErrors:   c.MaskInternalErrors,
+		EnableReadPath:       c.EnableReadPath,
+		EnableRFC6962ReadAPI: c.EnableRFC6962ReadAPI,
+		EnableValidateChain:  c.EnableValidateChain,
+		RootsPollInterval:    c.RootsPollInterval,
+		ReloadRootsOnSIGHUP:  true,
+		ReadinessMaxPushback: c.ReadinessMaxPushback,
+	}
+	for _, l := range c.Logs {
+		if l.LeafIndexDBPath != "" {
+			opts.EnableLeafIndexAPI = true
+		}
+	}
+	return opts
+}
+
+// RequestLogConfig returns the config of the JSON request log, or nil if
+// request logs are disabled.
+func (c *Config) RequestLogConfig() *tesseract.RequestLogConfig {
+	if c.RequestLog.Path == "" {
+		return nil
+	}
+	return &tesseract.RequestLogConfig{
+		Path:         c.RequestLog.Path,
+		MaxSizeBytes: int64(c.RequestLog.MaxSizeMB) << 20,
+		MaxBackups:   int(c.RequestLog.MaxBackups),
+		SampleRate:   c.RequestLog.SampleRate,
+		RedactNames:  c.RequestLog.RedactNames,
+	}
+}
+
+// PKCS11Config returns the signer.PKCS11Config of a validated config.
+func (p *PKCS11Signer) PKCS11Config() signer.PKCS11Config {
+	// The key ID was checked by Validate.
+	keyID, _ := hex.DecodeString(p.KeyID)
+	return signer.PKCS11Config{
+		ModulePath: p.ModulePath,
+		TokenLabel: p.TokenLabel,
+		PIN:        p.PIN,
+		KeyLabel:   p.KeyLabel,
+		KeyID:      keyID,
+	}
+}
+
+// Redacted returns a copy of the configuration with secrets redacted, so
+// that it can be printed.
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.Logs = make([]Log, len(c.Logs))
+	for i, l := range c.Logs {
+		if l.Signer.PKCS11 != nil && l.Signer.PKCS11.PIN != "" {
+			p := *l.Signer.PKCS11
+			p.PIN = "REDACTED"
+			l.Signer.PKCS11 = &p
+		}
+		if l.Storage.AWS != nil && l.Storage.AWS.DBPassword != "" {
+			aws := *l.Storage.AWS
+			aws.DBPassword = "REDACTED"
+			l.Storage.AWS = &aws
+		}
+		r.Logs[i] = l
+	}
+	return &r
+}
+
+// Marshal returns the YAML encoding of the configuration.
+func (c *Config) Marshal() ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %v", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,430 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tesseract/storage"
+)
+
+const posixYAML = `
+http_endpoint: localhost:8080
+enable_read_path: true
+logs:
+  - origin: example.com/2025h1
+    signer:
+      private_key_file: ${KEY_DIR}/2025h1.pem
+    deterministic_scts: true
+    chain_validation:
+      roots_pem_file: /etc/tesseract/roots.pem
+      not_after_start: 2025-01-01T00:00:00Z
+      not_after_limit: 2025-07-01T00:00:00Z
+    tessera:
+      checkpoint_interval: 2s
+    storage:
+      posix:
+        storage_dir: /var/lib/tesseract/2025h1
+    leaf_index_db_path: /var/lib/tesseract/2025h1.idx
+    timestamp_cache_size: 1024
+  - origin: example.com/2025h2
+    signer:
+      private_key_file: ${KEY_DIR}/2025h2.pem
+    chain_validation:
+      roots_pem_file: /etc/tesseract/roots.pem
+      not_after_start: 2025-07-01T00:00:00Z
+      not_after_limit: 2026-01-01T00:00:00Z
+    storage:
+      posix:
+        storage_dir: /var/lib/tesseract/2025h2
+`
+
+func mustTime(t *testing.T, s string) *time.Time {
+	t.Helper()
+	tt, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("time.Parse(%q): %v", s, err)
+	}
+	return &tt
+}
+
+func TestParse(t *testing.T) {
+	t.Setenv("KEY_DIR", "/etc/keys")
+
+	cfg, err := Parse([]byte(posixYAML))
+	if err != nil {
+		t.Fatalf("Parse(): %v", err)
+	}
+	cfg.SetDefaults()
+	if err := cfg.Validate(POSIX); err != nil {
+		t.Fatalf("Validate(): %v", err)
+	}
+
+	want := &Config{
+		HTTPEndpoint:         "localhost:8080",
+		HTTPDeadline:         DefaultHTTPDeadline,
+		ShutdownTimeout:      DefaultShutdownTimeout,
+		ReadinessMaxPushback: DefaultReadinessMaxPushback,
+		EnableReadPath:       true,
+		Logs: []Log{
+			{
+				Origin:            "example.com/2025h1",
+				Signer:            Signer{PrivateKeyFile: "/etc/keys/2025h1.pem"},
+				DeterministicSCTs: true,
+				ChainValidation: ChainValidation{
+					RootsPEMFile:  "/etc/tesseract/roots.pem",
+					NotAfterStart: mustTime(t, "2025-01-01T00:00:00Z"),
+					NotAfterLimit: mustTime(t, "2025-07-01T00:00:00Z"),
+				},
+				Tessera: Tessera{
+					CheckpointInterval:        2 * time.Second,
+					BatchMaxSize:              tessera.DefaultBatchMaxSize,
+					BatchMaxAge:               tessera.DefaultBatchMaxAge,
+					PushbackMaxOutstanding:    tessera.DefaultPushbackMaxOutstanding,
+					InMemoryAntispamCacheSize: DefaultInMemoryAntispamCacheSize,
+				},
+				Storage:            Storage{POSIX: &POSIXStorage{StorageDir: "/var/lib/tesseract/2025h1"}},
+				LeafIndexDBPath:    "/var/lib/tesseract/2025h1.idx",
+				TimestampCacheSize: 1024,
+			},
+			{
+				Origin: "example.com/2025h2",
+				Signer: Signer{PrivateKeyFile: "/etc/keys/2025h2.pem"},
+				ChainValidation: ChainValidation{
+					RootsPEMFile:  "/etc/tesseract/roots.pem",
+					NotAfterStart: mustTime(t, "2025-07-01T00:00:00Z"),
+					NotAfterLimit: mustTime(t, "2026-01-01T00:00:00Z"),
+				},
+				Tessera: Tessera{
+					CheckpointInterval:        DefaultCheckpointInterval,
+					BatchMaxSize:              tessera.DefaultBatchMaxSize,
+					BatchMaxAge:               tessera.DefaultBatchMaxAge,
+					PushbackMaxOutstanding:    tessera.DefaultPushbackMaxOutstanding,
+					InMemoryAntispamCacheSize: DefaultInMemoryAntispamCacheSize,
+				},
+				Storage:            Storage{POSIX: &POSIXStorage{StorageDir: "/var/lib/tesseract/2025h2"}},
+				TimestampCacheSize: storage.DefaultTimestampCacheSize,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("Parse() diff (-want +got):\n%s", diff)
+	}
+
+	// The effective config can be parsed back.
+	b, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal(): %v", err)
+	}
+	got, err := Parse(b)
+	if err != nil {
+		t.Fatalf("Parse(Marshal()): %v", err)
+	}
+	if diff := cmp.Diff(cfg, got); diff != "" {
+		t.Errorf("Parse(Marshal()) diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"logs": [{
+			"origin": "example.com/log",
+			"signer": {"public_key_secret_name": "pub", "private_key_secret_name": "priv"},
+			"chain_validation": {"roots_pem_file": "roots.pem", "not_after_start": "2025-01-01T00:00:00Z"},
+			"storage": {"gcp": {"bucket": "bucket", "spanner_db_path": "projects/p/instances/i/databases/d"}}
+		}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse(): %v", err)
+	}
+	cfg.SetDefaults()
+	if err := cfg.Validate(GCP); err != nil {
+		t.Fatalf("Validate(): %v", err)
+	}
+	if got, want := cfg.Logs[0].ChainValidation.NotAfterStart, mustTime(t, "2025-01-01T00:00:00Z"); !got.Equal(*want) {
+		t.Errorf("got not_after_start %v, want %v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		config  string
+		wantErr string
+	}{
+		{
+			desc:    "empty",
+			config:  "",
+			wantErr: "empty config",
+		},
+		{
+			desc:    "unknown-field",
+			config:  "logs:\n  - origin: example.com\n    roots_pem_file: roots.pem\n",
+			wantErr: "field roots_pem_file not found",
+		},
+		{
+			desc:    "unset-env-var",
+			config:  "http_endpoint: ${TESSERACT_UNSET_VAR_FOR_TEST}\n",
+			wantErr: `environment variable "TESSERACT_UNSET_VAR_FOR_TEST" is not set`,
+		},
+		{
+			desc:    "multiple-documents",
+			config:  "http_endpoint: a\n---\nhttp_endpoint: b\n",
+			wantErr: "single document",
+		},
+		{
+			desc:    "invalid-duration",
+			config:  "http_deadline: ten seconds\n",
+			wantErr: "failed to parse config",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := Parse([]byte(tc.config))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Parse()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	posixLog := func(origin, dir string) Log {
+		return Log{
+			Origin:          origin,
+			Signer:          Signer{PrivateKeyFile: "key.pem"},
+			ChainValidation: ChainValidation{RootsPEMFile: "roots.pem"},
+			Storage:         Storage{POSIX: &POSIXStorage{StorageDir: dir}},
+		}
+	}
+	nonUTC := time.Date(2025, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	for _, tc := range []struct {
+		desc    string
+		backend Backend
+		modify  func(*Config)
+		wantErr string
+	}{
+		{
+			desc:    "ok",
+			backend: POSIX,
+			modify:  func(*Config) {},
+		},
+		{
+			desc:    "no-logs",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs = nil },
+			wantErr: "no log configured",
+		},
+		{
+			desc:    "duplicate-origin",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs = append(c.Logs, posixLog("example.com/a", "/b")) },
+			wantErr: "duplicate origin",
+		},
+		{
+			desc:    "short-roots-poll-interval",
+			backend: POSIX,
+			modify:  func(c *Config) { c.RootsPollInterval = time.Second },
+			wantErr: "roots_poll_interval 1s is less than 10s",
+		},
+		{
+			desc:    "negative-shutdown-timeout",
+			backend: POSIX,
+			modify:  func(c *Config) { c.ShutdownTimeout = -time.Second },
+			wantErr: "negative shutdown_timeout",
+		},
+		{
+			desc:    "negative-readiness-max-pushback",
+			backend: POSIX,
+			modify:  func(c *Config) { c.ReadinessMaxPushback = -time.Second },
+			wantErr: "negative readiness_max_pushback",
+		},
+		{
+			desc:    "request-log-sample-rate",
+			backend: POSIX,
+			modify: func(c *Config) {
+				c.RequestLog = RequestLog{Path: "-", SampleRate: 1.5}
+			},
+			wantErr: "sample_rate",
+		},
+		{
+			desc:    "shared-storage-dir",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs = append(c.Logs, posixLog("example.com/b", "/a")) },
+			wantErr: "is used by another log",
+		},
+		{
+			desc:    "missing-origin",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].Origin = "" },
+			wantErr: "missing origin",
+		},
+		{
+			desc:    "missing-roots",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].ChainValidation.RootsPEMFile = "" },
+			wantErr: "exactly one of roots_pem_file and roots_manifest_file must be set",
+		},
+		{
+			desc:    "roots-pem-and-manifest",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].ChainValidation.RootsManifestFile = "roots.yaml" },
+			wantErr: "exactly one of roots_pem_file and roots_manifest_file must be set",
+		},
+		{
+			desc:    "roots-manifest",
+			backend: POSIX,
+			modify: func(c *Config) {
+				c.Logs[0].ChainValidation.RootsPEMFile = ""
+				c.Logs[0].ChainValidation.RootsManifestFile = "roots.yaml"
+			},
+		},
+		{
+			desc:    "non-utc-timestamp",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].ChainValidation.NotAfterStart = &nonUTC },
+			wantErr: "not_after_start must be in UTC",
+		},
+		{
+			desc:    "weak-keys",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].ChainValidation.WeakKeys = "reject" },
+		},
+		{
+			desc:    "unknown-weak-keys-mode",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].ChainValidation.WeakKeys = "block" },
+			wantErr: "weak_keys must be flag or reject",
+		},
+		{
+			desc:    "reject-all",
+			backend: POSIX,
+			modify: func(c *Config) {
+				c.Logs[0].ChainValidation.RejectExpired = true
+				c.Logs[0].ChainValidation.RejectUnexpired = true
+			},
+			wantErr: "would reject all certificates",
+		},
+		{
+			desc:    "wrong-storage-backend",
+			backend: GCP,
+			modify: func(c *Config) {
+				c.Logs[0].Signer = Signer{PublicKeySecretName: "pub", PrivateKeySecretName: "priv"}
+			},
+			wantErr: "posix storage is not supported by the gcp backend",
+		},
+		{
+			desc:    "wrong-signer",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].Signer.PrivateKeySecretName = "priv" },
+			wantErr: "secrets are not supported",
+		},
+		{
+			desc:    "multiple-signers",
+			backend: POSIX,
+			modify: func(c *Config) {
+				c.Logs[0].Signer.PKCS11 = &PKCS11Signer{ModulePath: "softhsm2.so", TokenLabel: "t", KeyLabel: "k"}
+			},
+			wantErr: "exactly one of secrets, private_key_file and pkcs11 must be set",
+		},
+		{
+			desc:    "pkcs11-signer",
+			backend: GCP,
+			modify: func(c *Config) {
+				c.Logs[0].Signer = Signer{PKCS11: &PKCS11Signer{ModulePath: "softhsm2.so", TokenLabel: "t", KeyID: "0a0b"}}
+				c.Logs[0].Storage = Storage{GCP: &GCPStorage{Bucket: "b", SpannerDBPath: "db"}}
+			},
+		},
+		{
+			desc:    "pkcs11-missing-key",
+			backend: POSIX,
+			modify: func(c *Config) {
+				c.Logs[0].Signer = Signer{PKCS11: &PKCS11Signer{ModulePath: "softhsm2.so", TokenLabel: "t"}}
+			},
+			wantErr: "pkcs11: missing key_label or key_id",
+		},
+		{
+			desc:    "pkcs11-invalid-key-id",
+			backend: POSIX,
+			modify: func(c *Config) {
+				c.Logs[0].Signer = Signer{PKCS11: &PKCS11Signer{ModulePath: "softhsm2.so", TokenLabel: "t", KeyID: "key"}}
+			},
+			wantErr: "pkcs11: invalid key_id",
+		},
+		{
+			desc:    "public-key-file-without-private-key-file",
+			backend: GCP,
+			modify: func(c *Config) {
+				c.Logs[0].Signer = Signer{PublicKeySecretName: "pub", PrivateKeySecretName: "priv", PublicKeyFile: "pub.pem"}
+				c.Logs[0].Storage = Storage{GCP: &GCPStorage{Bucket: "b", SpannerDBPath: "db"}}
+			},
+			wantErr: "public_key_file requires private_key_file",
+		},
+		{
+			desc:    "missing-storage-field",
+			backend: POSIX,
+			modify:  func(c *Config) { c.Logs[0].Storage.POSIX.StorageDir = "" },
+			wantErr: "posix: missing storage_dir",
+		},
+		{
+			desc:    "missing-aws-password",
+			backend: AWS,
+			modify: func(c *Config) {
+				c.Logs[0].Signer = Signer{PublicKeySecretName: "pub", PrivateKeySecretName: "priv"}
+				c.Logs[0].Storage = Storage{AWS: &AWSStorage{Bucket: "b", DBName: "d", DBHost: "h", DBPort: 3306, DBUser: "u"}}
+			},
+			wantErr: "aws: missing db_password",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := &Config{Logs: []Log{posixLog("example.com/a", "/a")}}
+			tc.modify(cfg)
+			err := cfg.Validate(tc.backend)
+			if len(tc.wantErr) == 0 && err != nil {
+				t.Errorf("Validate()=%v, want nil", err)
+			}
+			if len(tc.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("Validate()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{Logs: []Log{{
+		Signer:  Signer{PKCS11: &PKCS11Signer{PIN: "1234"}},
+		Storage: Storage{AWS: &AWSStorage{DBPassword: "secret"}},
+	}}}
+	b, err := cfg.Redacted().Marshal()
+	if err != nil {
+		t.Fatalf("Marshal(): %v", err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("Redacted config contains the password:\n%s", b)
+	}
+	if strings.Contains(string(b), "1234") {
+		t.Errorf("Redacted config contains the PIN:\n%s", b)
+	}
+	if got, want := cfg.Logs[0].Signer.PKCS11.PIN, "1234"; got != want {
+		t.Errorf("Redacted() modified the original config: got PIN %q, want %q", got, want)
+	}
+	if got, want := cfg.Logs[0].Storage.AWS.DBPassword, "secret"; got != want {
+		t.Errorf("Redacted() modified the original config: got password %q, want %q", got, want)
+	}
+}
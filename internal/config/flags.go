@@ -0,0 +1,334 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tesseract/storage"
+)
+
+// Flags holds command line flags which can be used instead of, or to
+// override, a config file.
+type Flags struct {
+	fs      *flag.FlagSet
+	backend Backend
+
+	configFile           *string
+	printEffectiveConfig *bool
+
+	// server and log map flag names to functions applying their value to the
+	// server config, and to a log config.
+	server map[string]func(*Config)
+	log    map[string]func(*Log)
+}
+
+// RegisterFlags registers flags for the given backend on fs.
+func RegisterFlags(fs *flag.FlagSet, backend Backend) *Flags {
+	f := &Flags{
+		fs:      fs,
+		backend: backend,
+		server:  make(map[string]func(*Config)),
+		log:     make(map[string]func(*Log)),
+	}
+
+	f.configFile = fs.String("config", "", "Path to a YAML or JSON config file. Flags explicitly set on the command line override values from this file.")
+	f.printEffectiveConfig = fs.Bool("print_effective_config", false, "If true then TesseraCT prints its effective config, with secrets redacted, and exits.")
+
+	// Functionality flags
+	httpEndpoint := fs.String("http_endpoint", DefaultHTTPEndpoint, "Endpoint for HTTP (host:port).")
+	f.server["http_endpoint"] = func(c *Config) { c.HTTPEndpoint = *httpEndpoint }
+	maskInternalErrors := fs.Bool("mask_internal_errors", false, "Don't return error strings with Internal Server Error HTTP responses.")
+	f.server["mask_internal_errors"] = func(c *Config) { c.MaskInternalErrors = *maskInternalErrors }
+	enableReadPath := fs.Bool("enable_read_path", false, "If true then TesseraCT serves the static-ct-api read path (checkpoint, tiles, entry bundles and issuers) from its storage.")
+	f.server["enable_read_path"] = func(c *Config) { c.EnableReadPath = *enableReadPath }
+	enableRFC6962ReadAPI := fs.Bool("enable_rfc6962_read_api", false, "If true then TesseraCT serves RFC 6962 read endpoints (get-sth, get-sth-consistency, get-proof-by-hash, get-entries and get-entry-and-proof), synthesized from its storage.")
+	f.server["enable_rfc6962_read_api"] = func(c *Config) { c.EnableRFC6962ReadAPI = *enableRFC6962ReadAPI }
+	enableValidateChain := fs.Bool("enable_validate_chain", false, "If true then TesseraCT serves a non-standard validate-chain endpoint, checking chains without logging them.")
+	f.server["enable_validate_chain"] = func(c *Config) { c.EnableValidateChain = *enableValidateChain }
+	requestLogPath := fs.String("request_log_path", "", "File to write structured request logs to, as newline-delimited JSON, or - for stdout. Request logs are disabled if empty.")
+	f.server["request_log_path"] = func(c *Config) { c.RequestLog.Path = *requestLogPath }
+	requestLogMaxSizeMB := fs.Uint("request_log_max_size_mb", DefaultRequestLogMaxSizeMB, "Size in megabytes after which the request log file is rotated.")
+	f.server["request_log_max_size_mb"] = func(c *Config) { c.RequestLog.MaxSizeMB = *requestLogMaxSizeMB }
+	requestLogMaxBackups := fs.Uint("request_log_max_backups", DefaultRequestLogMaxBackups, "Number of rotated request log files to keep.")
+	f.server["request_log_max_backups"] = func(c *Config) { c.RequestLog.MaxBackups = *requestLogMaxBackups }
+	requestLogSampleRate := fs.Float64("request_log_sample_rate", DefaultRequestLogSampleRate, "Fraction of requests to write to the request log, in (0, 1].")
+	f.server["request_log_sample_rate"] = func(c *Config) { c.RequestLog.SampleRate = *requestLogSampleRate }
+	requestLogRedactNames := fs.Bool("request_log_redact_names", false, "If true then the subject and issuer names of submitted certificates are omitted from request logs.")
+	f.server["request_log_redact_names"] = func(c *Config) { c.RequestLog.RedactNames = *requestLogRedactNames }
+	rootsPollInterval := fs.Duration("roots_poll_interval", 0, "Interval at which roots files are polled, and reloaded. Must be at least 10s. Zero disables periodic reloads. Roots are always reloaded on SIGHUP.")
+	f.server["roots_poll_interval"] = func(c *Config) { c.RootsPollInterval = *rootsPollInterval }
+
+	origin := fs.String("origin", "", "Origin of the log, for checkpoints and the monitoring prefix.")
+	f.log["origin"] = func(l *Log) { l.Origin = *origin }
+	rootsPEMFile := fs.String("roots_pem_file", "", "Path to the file containing root certificates that are acceptable to the log. The certs are served through get-roots endpoint.")
+	f.log["roots_pem_file"] = func(l *Log) { l.ChainValidation.RootsPEMFile = *rootsPEMFile }
+	rootsManifestFile := fs.String("roots_manifest_file", "", "Path to a YAML or JSON manifest listing root certificates that are acceptable to the log, each with an optional accept_from and accept_until time window, and labels. Only currently accepted roots are served through get-roots endpoint. Mutually exclusive with roots_pem_file.")
+	f.log["roots_manifest_file"] = func(l *Log) { l.ChainValidation.RootsManifestFile = *rootsManifestFile }
+	rejectExpired := fs.Bool("reject_expired", false, "If true then the certificate validity period will be checked against the current time during the validation of submissions. This will cause expired certificates to be rejected.")
+	f.log["reject_expired"] = func(l *Log) { l.ChainValidation.RejectExpired = *rejectExpired }
+	rejectUnexpired := fs.Bool("reject_unexpired", false, "If true then TesseraCT rejects certificates that are either currently valid or not yet valid.")
+	f.log["reject_unexpired"] = func(l *Log) { l.ChainValidation.RejectUnexpired = *rejectUnexpired }
+	extKeyUsages := fs.String("ext_key_usages", "", "If set, will restrict the set of such usages that the server will accept. By default all are accepted. The values specified must be ones known to the x509 package.")
+	f.log["ext_key_usages"] = func(l *Log) { l.ChainValidation.ExtKeyUsages = *extKeyUsages }
+	rejectExtensions := fs.String("reject_extension", "", "A list of X.509 extension OIDs, in dotted string form (e.g. '2.3.4.5') which, if present, should cause submissions to be rejected.")
+	f.log["reject_extension"] = func(l *Log) { l.ChainValidation.RejectExtensions = *rejectExtensions }
+	var notAfterStart, notAfterLimit timestampFlag
+	fs.Var(&notAfterStart, "not_after_start", "Start of the range of acceptable NotAfter values, inclusive. Leaving this unset or empty implies no lower bound to the range. RFC3339 UTC format, e.g: 2024-01-02T15:04:05Z.")
+	f.log["not_after_start"] = func(l *Log) { l.ChainValidation.NotAfterStart = notAfterStart.t }
+	fs.Var(&notAfterLimit, "not_after_limit", "Cut off point of notAfter dates - only notAfter dates strictly *before* notAfterLimit will be accepted. Leaving this unset or empty means no upper bound on the accepted range. RFC3339 UTC format, e.g: 2024-01-02T15:04:05Z.")
+	f.log["not_after_limit"] = func(l *Log) { l.ChainValidation.NotAfterLimit = notAfterLimit.t }
+	lenientChainOrdering := fs.Bool("lenient_chain_ordering", false, "If true then submitted chains with extra or misordered intermediates are accepted, as long as a path to a root can be built from submitted certificates. This path is logged.")
+	f.log["lenient_chain_ordering"] = func(l *Log) { l.ChainValidation.LenientChainOrdering = *lenientChainOrdering }
+	completeChains := fs.Bool("complete_chains", false, "If true then submitted chains with missing intermediates are completed with issuers previously seen by the log, and the completed chains are logged.")
+	f.log["complete_chains"] = func(l *Log) { l.ChainValidation.CompleteChains = *completeChains }
+	policyFile := fs.String("policy_file", "", "Path to a YAML or JSON file holding CEL rules that chains must satisfy to be accepted, once a path to a root has been built. Tests listed in the file are run when it is loaded.")
+	f.log["policy_file"] = func(l *Log) { l.ChainValidation.PolicyFile = *policyFile }
+	weakKeys := fs.String("weak_keys", "", "Detection of weak and compromised keys in submitted leaves: RSA keys shorter than 2048 bits, ROCA-vulnerable RSA moduli, and keys listed in debian_weak_keys_file or compromised_keys_file. 'flag' counts them in metrics, 'reject' also rejects them. Empty disables detection.")
+	f.log["weak_keys"] = func(l *Log) { l.ChainValidation.WeakKeys = *weakKeys }
+	debianWeakKeysFile := fs.String("debian_weak_keys_file", "", "Path to a blocklist of RSA moduli generated by the vulnerable Debian OpenSSL package, in the openssl-blacklist format. Requires weak_keys.")
+	f.log["debian_weak_keys_file"] = func(l *Log) { l.ChainValidation.DebianWeakKeysFile = *debianWeakKeysFile }
+	compromisedKeysFile := fs.String("compromised_keys_file", "", "Path to a file listing the hex encoded SHA-256 of the SubjectPublicKeyInfo of compromised keys, one per line. Requires weak_keys.")
+	f.log["compromised_keys_file"] = func(l *Log) { l.ChainValidation.CompromisedKeysFile = *compromisedKeysFile }
+	enablePublicationAwaiter := fs.Bool("enable_publication_awaiter", false, "If true then the certificate is integrated into log before returning the response.")
+	f.log["enable_publication_awaiter"] = func(l *Log) { l.Tessera.EnablePublicationAwaiter = *enablePublicationAwaiter }
+	deterministicSCTs := fs.Bool("deterministic_scts", false, "If true, SCTs are signed deterministically (RFC 6979 for ECDSA), such that resubmissions of a leaf with the same timestamp get byte-identical SCTs. Not supported by PKCS #11 signers.")
+	f.log["deterministic_scts"] = func(l *Log) { l.DeterministicSCTs = *deterministicSCTs }
+	timestampCacheSize := fs.Uint("timestamp_cache_size", storage.DefaultTimestampCacheSize, "Maximum number of entry timestamps cached in memory to answer duplicate submissions.")
+	f.log["timestamp_cache_size"] = func(l *Log) { l.TimestampCacheSize = *timestampCacheSize }
+	leafIndexDBPath := fs.String("leaf_index_db_path", "", "Path to the Badger database directory mapping Merkle leaf hashes to entry indices, used for leaf hash lookups. Leaf hash lookups are disabled if empty.")
+	f.log["leaf_index_db_path"] = func(l *Log) { l.LeafIndexDBPath = *leafIndexDBPath }
+
+	// Performance flags
+	httpDeadline := fs.Duration("http_deadline", DefaultHTTPDeadline, "Deadline for HTTP requests.")
+	f.server["http_deadline"] = func(c *Config) { c.HTTPDeadline = *httpDeadline }
+	shutdownTimeout := fs.Duration("shutdown_timeout", DefaultShutdownTimeout, "Maximum time to wait on shutdown for pending requests to finish, and for accepted entries to be integrated and published.")
+	f.server["shutdown_timeout"] = func(c *Config) { c.ShutdownTimeout = *shutdownTimeout }
+	readinessMaxPushback := fs.Duration("readiness_max_pushback", DefaultReadinessMaxPushback, "How long the storage of a log may push back on new entries without interruption before the server is reported as not ready.")
+	f.server["readiness_max_pushback"] = func(c *Config) { c.ReadinessMaxPushback = *readinessMaxPushback }
+	inMemoryAntispamCacheSize := fs.Uint("inmemory_antispam_cache_size", DefaultInMemoryAntispamCacheSize, "Maximum number of entries to keep in the in-memory antispam cache.")
+	f.log["inmemory_antispam_cache_size"] = func(l *Log) { l.Tessera.InMemoryAntispamCacheSize = *inMemoryAntispamCacheSize }
+	checkpointInterval := fs.Duration("checkpoint_interval", DefaultCheckpointInterval, "Interval between checkpoint publishing")
+	f.log["checkpoint_interval"] = func(l *Log) { l.Tessera.CheckpointInterval = *checkpointInterval }
+	batchMaxSize := fs.Uint("batch_max_size", tessera.DefaultBatchMaxSize, "Maximum number of entries to process in a single Tessera sequencing batch.")
+	f.log["batch_max_size"] = func(l *Log) { l.Tessera.BatchMaxSize = *batchMaxSize }
+	batchMaxAge := fs.Duration("batch_max_age", tessera.DefaultBatchMaxAge, "Maximum age of entries in a single Tessera sequencing batch.")
+	f.log["batch_max_age"] = func(l *Log) { l.Tessera.BatchMaxAge = *batchMaxAge }
+	pushbackMaxOutstanding := fs.Uint("pushback_max_outstanding", tessera.DefaultPushbackMaxOutstanding, "Maximum number of number of in-flight add requests - i.e. the number of entries with sequence numbers assigned, but which are not yet integrated into the log.")
+	f.log["pushback_max_outstanding"] = func(l *Log) { l.Tessera.PushbackMaxOutstanding = *pushbackMaxOutstanding }
+
+	// Infrastructure setup flags
+	switch backend {
+	case GCP:
+		f.registerGCPFlags()
+	case AWS:
+		f.registerAWSFlags()
+	case POSIX:
+		f.registerPOSIXFlags()
+	}
+	f.registerLocalSignerFlags()
+
+	return f
+}
+
+func (f *Flags) registerGCPFlags() {
+	fs := f.fs
+	gcp := func(l *Log) *GCPStorage {
+		if l.Storage.GCP == nil {
+			l.Storage.GCP = &GCPStorage{}
+		}
+		return l.Storage.GCP
+	}
+	bucket := fs.String("bucket", "", "Name of the GCS bucket to store the log in.")
+	f.log["bucket"] = func(l *Log) { gcp(l).Bucket = *bucket }
+	spannerDB := fs.String("spanner_db_path", "", "Spanner database path: projects/{projectId}/instances/{instanceId}/databases/{databaseId}.")
+	f.log["spanner_db_path"] = func(l *Log) { gcp(l).SpannerDBPath = *spannerDB }
+	spannerAntispamDB := fs.String("spanner_antispam_db_path", "", "Spanner antispam deduplication database path projects/{projectId}/instances/{instanceId}/databases/{databaseId}.")
+	f.log["spanner_antispam_db_path"] = func(l *Log) { gcp(l).SpannerAntispamDBPath = *spannerAntispamDB }
+	f.registerSecretSignerFlags("Format: projects/{projectId}/secrets/{secretName}/versions/{secretVersion}.")
+	traceFraction := fs.Float64("trace_fraction", 0, "Fraction of open-telemetry span traces to sample")
+	f.server["trace_fraction"] = func(c *Config) { c.OTel.TraceFraction = *traceFraction }
+	otelProjectID := fs.String("otel_project_id", "", "GCP project ID for OpenTelemetry exporter. This is only required for local runs.")
+	f.server["otel_project_id"] = func(c *Config) { c.OTel.ProjectID = *otelProjectID }
+}
+
+func (f *Flags) registerAWSFlags() {
+	fs := f.fs
+	aws := func(l *Log) *AWSStorage {
+		if l.Storage.AWS == nil {
+			l.Storage.AWS = &AWSStorage{}
+		}
+		return l.Storage.AWS
+	}
+	bucket := fs.String("bucket", "", "Name of the S3 bucket to store the log in.")
+	f.log["bucket"] = func(l *Log) { aws(l).Bucket = *bucket }
+	dbName := fs.String("db_name", "", "AuroraDB name")
+	f.log["db_name"] = func(l *Log) { aws(l).DBName = *dbName }
+	antispamDBName := fs.String("antispam_db_name", "", "AuroraDB antispam name")
+	f.log["antispam_db_name"] = func(l *Log) { aws(l).AntispamDBName = *antispamDBName }
+	dbHost := fs.String("db_host", "", "AuroraDB host")
+	f.log["db_host"] = func(l *Log) { aws(l).DBHost = *dbHost }
+	dbPort := fs.Int("db_port", DefaultAWSDBPort, "AuroraDB port")
+	f.log["db_port"] = func(l *Log) { aws(l).DBPort = *dbPort }
+	dbUser := fs.String("db_user", "", "AuroraDB user")
+	f.log["db_user"] = func(l *Log) { aws(l).DBUser = *dbUser }
+	dbPassword := fs.String("db_password", "", "AuroraDB password")
+	f.log["db_password"] = func(l *Log) { aws(l).DBPassword = *dbPassword }
+	dbMaxConns := fs.Int("db_max_conns", 0, "Maximum connections to the database, defaults to 0, i.e unlimited")
+	f.log["db_max_conns"] = func(l *Log) { aws(l).DBMaxConns = *dbMaxConns }
+	dbMaxIdle := fs.Int("db_max_idle_conns", DefaultAWSDBMaxIdleConns, "Maximum idle database connections in the connection pool, defaults to 2")
+	f.log["db_max_idle_conns"] = func(l *Log) { aws(l).DBMaxIdleConns = *dbMaxIdle }
+	f.registerSecretSignerFlags("")
+}
+
+func (f *Flags) registerPOSIXFlags() {
+	fs := f.fs
+	posix := func(l *Log) *POSIXStorage {
+		if l.Storage.POSIX == nil {
+			l.Storage.POSIX = &POSIXStorage{}
+		}
+		return l.Storage.POSIX
+	}
+	storageDir := fs.String("storage_dir", "", "Root directory to store the log in. Issuers are stored under its issuer/ subdirectory.")
+	f.log["storage_dir"] = func(l *Log) { posix(l).StorageDir = *storageDir }
+	antispamDBPath := fs.String("antispam_db_path", "", "Path to the Badger antispam deduplication database directory. Persistent antispam is disabled if empty.")
+	f.log["antispam_db_path"] = func(l *Log) { posix(l).AntispamDBPath = *antispamDBPath }
+}
+
+// registerSecretSignerFlags registers flags for signers loaded from secrets,
+// with format describing secret names.
+func (f *Flags) registerSecretSignerFlags(format string) {
+	suffix := ""
+	if format != "" {
+		suffix = ". " + format
+	}
+	pub := f.fs.String("signer_public_key_secret_name", "", "Public key secret name for checkpoints and SCTs signer"+suffix)
+	f.log["signer_public_key_secret_name"] = func(l *Log) { l.Signer.PublicKeySecretName = *pub }
+	priv := f.fs.String("signer_private_key_secret_name", "", "Private key secret name for checkpoints and SCTs signer"+suffix)
+	f.log["signer_private_key_secret_name"] = func(l *Log) { l.Signer.PrivateKeySecretName = *priv }
+}
+
+// registerLocalSignerFlags registers flags for signers using a key file, or a
+// PKCS #11 token.
+func (f *Flags) registerLocalSignerFlags() {
+	fs := f.fs
+	// pkcs11 returns the PKCS #11 signer config of l. It is only created if
+	// v is not empty, so that unset flags don't configure a PKCS #11 signer.
+	pkcs11 := func(l *Log, v string) *PKCS11Signer {
+		if l.Signer.PKCS11 == nil {
+			if v == "" {
+				return &PKCS11Signer{}
+			}
+			l.Signer.PKCS11 = &PKCS11Signer{}
+		}
+		return l.Signer.PKCS11
+	}
+	privateKeyFile := fs.String("signer_private_key_file", "", "Path to the PEM encoded private key for checkpoints and SCTs signer.")
+	f.log["signer_private_key_file"] = func(l *Log) { l.Signer.PrivateKeyFile = *privateKeyFile }
+	publicKeyFile := fs.String("signer_public_key_file", "", "Path to the PEM encoded public key for checkpoints and SCTs signer. If set, it must match signer_private_key_file.")
+	f.log["signer_public_key_file"] = func(l *Log) { l.Signer.PublicKeyFile = *publicKeyFile }
+	modulePath := fs.String("signer_pkcs11_module_path", "", "Path to the PKCS #11 library of the token holding the checkpoints and SCTs signer key.")
+	f.log["signer_pkcs11_module_path"] = func(l *Log) { pkcs11(l, *modulePath).ModulePath = *modulePath }
+	tokenLabel := fs.String("signer_pkcs11_token_label", "", "Label of the PKCS #11 token holding the checkpoints and SCTs signer key.")
+	f.log["signer_pkcs11_token_label"] = func(l *Log) { pkcs11(l, *tokenLabel).TokenLabel = *tokenLabel }
+	pin := fs.String("signer_pkcs11_pin", "", "User PIN of the PKCS #11 token. Prefer setting it from an environment variable in a config file.")
+	f.log["signer_pkcs11_pin"] = func(l *Log) { pkcs11(l, *pin).PIN = *pin }
+	keyLabel := fs.String("signer_pkcs11_key_label", "", "Label of the PKCS #11 key pair of the checkpoints and SCTs signer.")
+	f.log["signer_pkcs11_key_label"] = func(l *Log) { pkcs11(l, *keyLabel).KeyLabel = *keyLabel }
+	keyID := fs.String("signer_pkcs11_key_id", "", "Hex encoded ID of the PKCS #11 key pair of the checkpoints and SCTs signer.")
+	f.log["signer_pkcs11_key_id"] = func(l *Log) { pkcs11(l, *keyID).KeyID = *keyID }
+}
+
+// PrintEffectiveConfig returns whether the effective config should be printed.
+func (f *Flags) PrintEffectiveConfig() bool {
+	return *f.printEffectiveConfig
+}
+
+// Config returns the effective config, once flags have been parsed.
+//
+// Without a config file, flags configure a single log. With a config file,
+// flags explicitly set on the command line override values from the file.
+// Flags configuring a log can only be set if the file has a single log.
+//
+// The returned config has defaults applied, and is valid.
+func (f *Flags) Config() (*Config, error) {
+	if !f.fs.Parsed() {
+		return nil, errors.New("flags have not been parsed")
+	}
+
+	cfg := &Config{Logs: []Log{{}}}
+	visit := f.fs.VisitAll
+	if *f.configFile != "" {
+		var err error
+		cfg, err = Load(*f.configFile)
+		if err != nil {
+			return nil, err
+		}
+		visit = f.fs.Visit
+	}
+
+	var logFlags []string
+	visit(func(fl *flag.Flag) {
+		if set, ok := f.server[fl.Name]; ok {
+			set(cfg)
+		}
+		if set, ok := f.log[fl.Name]; ok {
+			logFlags = append(logFlags, "--"+fl.Name)
+			for i := range cfg.Logs {
+				set(&cfg.Logs[i])
+			}
+		}
+	})
+	if len(logFlags) > 0 && len(cfg.Logs) != 1 {
+		return nil, fmt.Errorf("flags %s can't be used with a config file with %d logs", strings.Join(logFlags, ", "), len(cfg.Logs))
+	}
+
+	cfg.SetDefaults()
+	if err := cfg.Validate(f.backend); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	return cfg, nil
+}
+
+// timestampFlag parses RFC3339 UTC timestamps.
+type timestampFlag struct {
+	t *time.Time
+}
+
+func (t *timestampFlag) String() string {
+	if t.t != nil {
+		return t.t.Format(time.RFC3339)
+	}
+	return ""
+}
+
+func (t *timestampFlag) Set(w string) error {
+	if w == "" {
+		return nil
+	} else if !strings.HasSuffix(w, "Z") {
+		return fmt.Errorf("timestamps MUST be in UTC, got %v", w)
+	}
+	tt, err := time.Parse(time.RFC3339, w)
+	if err != nil {
+		return fmt.Errorf("can't parse %q as RFC3339 timestamp: %v", w, err)
+	}
+	t.t = &tt
+	return nil
+}
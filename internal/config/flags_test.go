@@ -0,0 +1,205 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// parseFlags registers flags for backend and parses args.
+func parseFlags(t *testing.T, backend Backend, args ...string) *Flags {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := RegisterFlags(fs, backend)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse(%v): %v", args, err)
+	}
+	return f
+}
+
+// writeConfig writes config to a file, and returns its path.
+func writeConfig(t *testing.T, config string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(p, []byte(config), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	return p
+}
+
+func TestFlagsOnly(t *testing.T) {
+	f := parseFlags(t, AWS,
+		"--origin=example.com/log",
+		"--roots_pem_file=roots.pem",
+		"--not_after_start=2025-01-01T00:00:00Z",
+		"--signer_public_key_secret_name=pub",
+		"--signer_private_key_secret_name=priv",
+		"--bucket=bucket",
+		"--db_name=db",
+		"--db_host=host",
+		"--db_user=user",
+		"--db_password=password",
+		"--batch_max_size=10",
+	)
+	cfg, err := f.Config()
+	if err != nil {
+		t.Fatalf("Config(): %v", err)
+	}
+	if got, want := len(cfg.Logs), 1; got != want {
+		t.Fatalf("got %d logs, want %d", got, want)
+	}
+	l := cfg.Logs[0]
+	if got, want := l.Origin, "example.com/log"; got != want {
+		t.Errorf("got origin %q, want %q", got, want)
+	}
+	if got, want := l.Tessera.BatchMaxSize, uint(10); got != want {
+		t.Errorf("got batch_max_size %d, want %d", got, want)
+	}
+	if got, want := l.Storage.AWS.DBPort, DefaultAWSDBPort; got != want {
+		t.Errorf("got db_port %d, want %d", got, want)
+	}
+	if got, want := l.ChainValidation.NotAfterStart, mustTime(t, "2025-01-01T00:00:00Z"); got == nil || !got.Equal(*want) {
+		t.Errorf("got not_after_start %v, want %v", got, want)
+	}
+	if got, want := cfg.HTTPEndpoint, DefaultHTTPEndpoint; got != want {
+		t.Errorf("got http_endpoint %q, want %q", got, want)
+	}
+}
+
+func TestFlagsPKCS11Signer(t *testing.T) {
+	f := parseFlags(t, POSIX,
+		"--origin=example.com/log",
+		"--roots_pem_file=roots.pem",
+		"--storage_dir=/tmp/log",
+		"--signer_pkcs11_module_path=/usr/lib/softhsm/libsofthsm2.so",
+		"--signer_pkcs11_token_label=tesseract",
+		"--signer_pkcs11_pin=1234",
+		"--signer_pkcs11_key_id=0102",
+	)
+	cfg, err := f.Config()
+	if err != nil {
+		t.Fatalf("Config(): %v", err)
+	}
+	want := &PKCS11Signer{
+		ModulePath: "/usr/lib/softhsm/libsofthsm2.so",
+		TokenLabel: "tesseract",
+		PIN:        "1234",
+		KeyID:      "0102",
+	}
+	if diff := cmp.Diff(want, cfg.Logs[0].Signer.PKCS11); diff != "" {
+		t.Errorf("PKCS11 signer mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := cfg.Logs[0].Signer.PKCS11.PKCS11Config().KeyID, []byte{1, 2}; !bytes.Equal(got, want) {
+		t.Errorf("got key ID %x, want %x", got, want)
+	}
+}
+
+func TestFlagsOverrideConfig(t *testing.T) {
+	t.Setenv("KEY_DIR", "/etc/keys")
+	p := writeConfig(t, `
+http_deadline: 5s
+logs:
+  - origin: example.com/log
+    signer:
+      private_key_file: ${KEY_DIR}/key.pem
+    chain_validation:
+      roots_pem_file: roots.pem
+    tessera:
+      batch_max_size: 10
+    storage:
+      posix:
+        storage_dir: /var/lib/tesseract
+`)
+	f := parseFlags(t, POSIX, "--config="+p, "--batch_max_size=20", "--http_endpoint=:80")
+	cfg, err := f.Config()
+	if err != nil {
+		t.Fatalf("Config(): %v", err)
+	}
+	l := cfg.Logs[0]
+	// Explicitly set flags override the file.
+	if got, want := l.Tessera.BatchMaxSize, uint(20); got != want {
+		t.Errorf("got batch_max_size %d, want %d", got, want)
+	}
+	if got, want := cfg.HTTPEndpoint, ":80"; got != want {
+		t.Errorf("got http_endpoint %q, want %q", got, want)
+	}
+	// Flags left to their default don't.
+	if got, want := cfg.HTTPDeadline, 5*time.Second; got != want {
+		t.Errorf("got http_deadline %v, want %v", got, want)
+	}
+	if got, want := l.Signer.PrivateKeyFile, "/etc/keys/key.pem"; got != want {
+		t.Errorf("got private_key_file %q, want %q", got, want)
+	}
+}
+
+func TestFlagsErrors(t *testing.T) {
+	twoLogs := writeConfig(t, `
+logs:
+  - origin: example.com/a
+    signer: {private_key_file: a.pem}
+    chain_validation: {roots_pem_file: roots.pem}
+    storage: {posix: {storage_dir: /a}}
+  - origin: example.com/b
+    signer: {private_key_file: b.pem}
+    chain_validation: {roots_pem_file: roots.pem}
+    storage: {posix: {storage_dir: /b}}
+`)
+
+	for _, tc := range []struct {
+		desc    string
+		args    []string
+		wantErr string
+	}{
+		{
+			desc:    "log-flag-with-multiple-logs",
+			args:    []string{"--config=" + twoLogs, "--origin=example.com/c"},
+			wantErr: "--origin can't be used with a config file with 2 logs",
+		},
+		{
+			desc:    "missing-config-file",
+			args:    []string{"--config=" + filepath.Join(t.TempDir(), "missing.yaml")},
+			wantErr: "failed to read config file",
+		},
+		{
+			desc:    "invalid-flags",
+			args:    []string{"--origin=example.com/log"},
+			wantErr: "invalid config",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := parseFlags(t, POSIX, tc.args...).Config()
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Config()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	// Server flags can be used with multiple logs.
+	cfg, err := parseFlags(t, POSIX, "--config="+twoLogs, "--enable_read_path").Config()
+	if err != nil {
+		t.Fatalf("Config(): %v", err)
+	}
+	if !cfg.EnableReadPath {
+		t.Errorf("got enable_read_path false, want true")
+	}
+}
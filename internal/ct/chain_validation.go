@@ -16,10 +16,12 @@ package ct
 
 import (
 	"bytes"
+	"context"
 	"crypto/x509"
 	"encoding/asn1"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -27,9 +29,18 @@ import (
 	"github.com/transparency-dev/tesseract/internal/lax509"
 	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
 	"github.com/transparency-dev/tesseract/internal/x509util"
+	"go.opentelemetry.io/otel/metric"
 	"k8s.io/klog/v2"
 )
 
+var reorderedChains = mustCreate(meter.Int64Counter("tesseract.chain.reordered",
+	metric.WithDescription("Chains accepted in lenient ordering mode, which had to be reordered or trimmed"),
+	metric.WithUnit("{chain}")))
+
+var weakKeyCerts = mustCreate(meter.Int64Counter("tesseract.chain.weak_key",
+	metric.WithDescription("Submitted leaves with a weak or compromised key, chaining to an accepted root"),
+	metric.WithUnit("{certificate}")))
+
 var stringToKeyUsage = map[string]x509.ExtKeyUsage{
 	"Any":                        x509.ExtKeyUsageAny,
 	"ServerAuth":                 x509.ExtKeyUsageServerAuth,
@@ -85,12 +96,79 @@ func ParseOIDs(oids []string) ([]asn1.ObjectIdentifier, error) {
 	return ret, nil
 }
 
+// RejectionReason is a stable, machine-readable code identifying why a chain
+// was rejected.
+type RejectionReason string
+
+// Reasons for rejecting a chain.
+const (
+	// ReasonParseError is used for empty chains, and chains with certificates
+	// that don't parse.
+	ReasonParseError RejectionReason = "parse_error"
+	// ReasonExpired is used for expired certificates, when they are rejected.
+	ReasonExpired RejectionReason = "expired"
+	// ReasonUnexpired is used for unexpired certificates, when they are
+	// rejected.
+	ReasonUnexpired RejectionReason = "unexpired"
+	// ReasonNotAfterOutOfRange is used for certificates with a notAfter date
+	// outside of the range accepted by the log.
+	ReasonNotAfterOutOfRange RejectionReason = "not_after_out_of_range"
+	// ReasonForbiddenExtension is used for certificates with an extension
+	// rejected by the log.
+	ReasonForbiddenExtension RejectionReason = "forbidden_extension"
+	// ReasonEKUMismatch is used for certificates without any of the extended
+	// key usages accepted by the log.
+	ReasonEKUMismatch RejectionReason = "eku_mismatch"
+	// ReasonUnknownRoot is used for chains which don't chain to any of the
+	// roots accepted by the log.
+	ReasonUnknownRoot RejectionReason = "unknown_root"
+	// ReasonOutOfOrderChain is used for chains which chain to an accepted
+	// root, but not in the order they were submitted in.
+	ReasonOutOfOrderChain RejectionReason = "out_of_order_chain"
+	// ReasonPrecertMismatch is used for certificates submitted to
+	// add-pre-chain, precertificates submitted to add-chain, and
+	// precertificates with an invalid CT poison extension.
+	ReasonPrecertMismatch RejectionReason = "precert_mismatch"
+	// ReasonPolicyViolation is used for chains violating a rule of the
+	// log's policy.
+	ReasonPolicyViolation RejectionReason = "policy_violation"
+	// ReasonWeakKey is used for certificates with a weak or compromised key,
+	// when they are rejected.
+	ReasonWeakKey RejectionReason = "weak_key"
+	// ReasonInvalidChain is used for chains failing verification for any
+	// other reason.
+	ReasonInvalidChain RejectionReason = "invalid_chain"
+)
+
+// ValidationError is returned when a chain is rejected.
+type ValidationError struct {
+	Reason RejectionReason
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// rejectf returns a ValidationError for reason, formatting its message
+// according to a format specifier.
+func rejectf(reason RejectionReason, format string, a ...any) error {
+	return &ValidationError{Reason: reason, Err: fmt.Errorf(format, a...)}
+}
+
 // chainValidator contains various parameters for certificate chain validation.
 type chainValidator struct {
-	// trustedRoots is a pool of certificates that defines the roots the CT log will accept.
-	trustedRoots *x509util.PEMCertPool
+	// trustedRoots defines the roots the CT log will accept.
+	trustedRoots *Roots
+	// timeSource provides the time against which roots windows and
+	// certificate validity periods are checked. If nil then time.Now() is used.
+	timeSource TimeSource
 	// currentTime is the time used for checking a certificate's validity period
-	// against. If it's zero then time.Now() is used. Only for testing.
+	// against. If it's zero then timeSource is used. Only for testing.
 	// TODO(phboneff): check if I can remove this or align it with the other time definition.
 	currentTime time.Time
 	// rejectExpired indicates that expired certificates will be rejected.
@@ -108,17 +186,70 @@ type chainValidator struct {
 	extKeyUsages []x509.ExtKeyUsage
 	// rejectExtIds contains a list of X.509 extension IDs to reject during chain verification.
 	rejectExtIds []asn1.ObjectIdentifier
+	// lenientOrdering accepts chains with extra or misordered intermediates,
+	// as long as a path to a root can be built from submitted certificates.
+	lenientOrdering bool
+	// knownIssuers holds previously seen issuers, used to complete chains
+	// with missing intermediates. nil means chains are not completed.
+	knownIssuers *IssuerPool
+	// policy holds rules that verified paths must satisfy. nil means all
+	// verified paths are accepted.
+	policy *Policy
+	// weakKeys detects weak and compromised keys in leaves. nil means keys
+	// are not checked.
+	weakKeys *WeakKeyDetector
 }
 
-func NewChainValidator(trustedRoots *x509util.PEMCertPool, rejectExpired, rejectUnexpired bool, notAfterStart, notAfterLimit *time.Time, extKeyUsages []x509.ExtKeyUsage, rejectExtIds []asn1.ObjectIdentifier) chainValidator {
+// ChainValidatorOptions holds the optional parameters of a chain validator.
+// The zero value accepts all the chains leading to a trusted root, as
+// submitted.
+type ChainValidatorOptions struct {
+	// TimeSource provides the time against which roots windows and
+	// certificate validity periods are checked. If nil then time.Now() is
+	// used.
+	TimeSource TimeSource
+	// RejectExpired rejects expired certificates.
+	RejectExpired bool
+	// RejectUnexpired rejects certificates that are currently valid or not
+	// yet valid.
+	RejectUnexpired bool
+	// NotAfterStart is the earliest notAfter date which will be accepted.
+	// nil means no lower bound on the accepted range.
+	NotAfterStart *time.Time
+	// NotAfterLimit is the notAfter date before which certificates must
+	// expire. nil means no upper bound on the accepted range.
+	NotAfterLimit *time.Time
+	// ExtKeyUsages lists the EKUs certificates must have one of.
+	ExtKeyUsages []x509.ExtKeyUsage
+	// RejectExtIds lists the X.509 extensions certificates must not have.
+	RejectExtIds []asn1.ObjectIdentifier
+	// LenientOrdering accepts chains with extra or misordered
+	// intermediates.
+	LenientOrdering bool
+	// KnownIssuers completes chains with missing intermediates, if not nil.
+	KnownIssuers *IssuerPool
+	// Policy holds rules that verified paths must satisfy, if not nil.
+	Policy *Policy
+	// WeakKeys detects weak and compromised keys in leaves, if not nil.
+	WeakKeys *WeakKeyDetector
+}
+
+// NewChainValidator returns a chain validator accepting chains leading to
+// trustedRoots, as configured by opts.
+func NewChainValidator(trustedRoots *Roots, opts ChainValidatorOptions) chainValidator {
 	return chainValidator{
 		trustedRoots:    trustedRoots,
-		rejectExpired:   rejectExpired,
-		rejectUnexpired: rejectUnexpired,
-		notAfterStart:   notAfterStart,
-		notAfterLimit:   notAfterLimit,
-		extKeyUsages:    extKeyUsages,
-		rejectExtIds:    rejectExtIds,
+		timeSource:      opts.TimeSource,
+		rejectExpired:   opts.RejectExpired,
+		rejectUnexpired: opts.RejectUnexpired,
+		notAfterStart:   opts.NotAfterStart,
+		notAfterLimit:   opts.NotAfterLimit,
+		extKeyUsages:    opts.ExtKeyUsages,
+		rejectExtIds:    opts.RejectExtIds,
+		lenientOrdering: opts.LenientOrdering,
+		knownIssuers:    opts.KnownIssuers,
+		policy:          opts.Policy,
+		weakKeys:        opts.WeakKeys,
 	}
 }
 
@@ -149,53 +280,89 @@ func isPrecertificate(cert *x509.Certificate) (bool, error) {
 // supplied in the chain. Then applies the RFC requirement that the path must involve all
 // the submitted chain in the order of submission.
 func (cv chainValidator) validate(rawChain [][]byte) ([]*x509.Certificate, error) {
+	chain, err := parseRawChain(rawChain)
+	if err != nil {
+		return nil, err
+	}
+	now := cv.now()
+	cert := chain[0]
+	for _, check := range []func() error{
+		func() error { return cv.checkNotAfter(cert) },
+		func() error { return cv.checkExpiry(cert, now) },
+		func() error { return cv.checkExtensions(cert) },
+		func() error { return cv.checkExtKeyUsages(cert) },
+	} {
+		if err := check(); err != nil {
+			return nil, err
+		}
+	}
+	verifiedChains, err := cv.verify(chain, now)
+	if err != nil {
+		return nil, err
+	}
+	validPath, err := cv.orderedChain(chain, verifiedChains)
+	if err != nil {
+		return nil, err
+	}
+	if cv.lenientOrdering && !chainsEquivalent(chain, validPath) && chainSubmitted(chain, validPath) {
+		klog.V(2).Infof("Reordered chain of %d certificates to a path of %d certificates", len(chain), len(validPath))
+		reorderedChains.Add(context.Background(), 1, metric.WithAttributes(originKey.String(cv.trustedRoots.origin)))
+	}
+	// Keys are checked last, so that only leaves chaining to an accepted root
+	// are counted.
+	if w := cv.weakKeys.detect(cert); w != "" {
+		weakKeyCerts.Add(context.Background(), 1, metric.WithAttributes(originKey.String(cv.trustedRoots.origin), weaknessKey.String(string(w))))
+		if err := cv.checkKey(w); err != nil {
+			return nil, err
+		}
+	}
+	return validPath, nil
+}
+
+// parseRawChain ensures that all the elements of rawChain decode as X.509
+// certificates.
+func parseRawChain(rawChain [][]byte) ([]*x509.Certificate, error) {
 	if len(rawChain) == 0 {
-		return nil, errors.New("empty certificate chain")
+		return nil, rejectf(ReasonParseError, "empty certificate chain")
 	}
 
-	// First make sure the certs parse as X.509
 	chain := make([]*x509.Certificate, 0, len(rawChain))
-	intermediatePool := x509util.NewPEMCertPool()
-
-	for i, certBytes := range rawChain {
+	for _, certBytes := range rawChain {
 		cert, err := x509.ParseCertificate(certBytes)
 		if err != nil {
-			return nil, fmt.Errorf("x509.ParseCertificate(): %v", err)
+			return nil, rejectf(ReasonParseError, "x509.ParseCertificate(): %v", err)
 		}
-
 		chain = append(chain, cert)
-
-		// All but the first cert form part of the intermediate pool
-		if i > 0 {
-			intermediatePool.AddCert(cert)
-		}
 	}
+	return chain, nil
+}
 
-	naStart := cv.notAfterStart
-	naLimit := cv.notAfterLimit
-	cert := chain[0]
-
-	// Check whether the expiry date of the cert is within the acceptable range.
-	if naStart != nil && cert.NotAfter.Before(*naStart) {
-		return nil, fmt.Errorf("certificate NotAfter (%v) < %v", cert.NotAfter, *naStart)
+// checkNotAfter checks whether the expiry date of the cert is within the
+// acceptable range.
+func (cv chainValidator) checkNotAfter(cert *x509.Certificate) error {
+	if naStart := cv.notAfterStart; naStart != nil && cert.NotAfter.Before(*naStart) {
+		return rejectf(ReasonNotAfterOutOfRange, "certificate NotAfter (%v) < %v", cert.NotAfter, *naStart)
 	}
-	if naLimit != nil && !cert.NotAfter.Before(*naLimit) {
-		return nil, fmt.Errorf("certificate NotAfter (%v) >= %v", cert.NotAfter, *naLimit)
+	if naLimit := cv.notAfterLimit; naLimit != nil && !cert.NotAfter.Before(*naLimit) {
+		return rejectf(ReasonNotAfterOutOfRange, "certificate NotAfter (%v) >= %v", cert.NotAfter, *naLimit)
 	}
+	return nil
+}
 
-	now := cv.currentTime
-	if now.IsZero() {
-		now = time.Now()
-	}
+// checkExpiry rejects expired or unexpired certificates, if required.
+func (cv chainValidator) checkExpiry(cert *x509.Certificate, now time.Time) error {
 	expired := now.After(cert.NotAfter)
 	if cv.rejectExpired && expired {
-		return nil, errors.New("rejecting expired certificate")
+		return rejectf(ReasonExpired, "rejecting expired certificate")
 	}
 	if cv.rejectUnexpired && !expired {
-		return nil, errors.New("rejecting unexpired certificate")
+		return rejectf(ReasonUnexpired, "rejecting unexpired certificate")
 	}
+	return nil
+}
 
-	// Check for unwanted extension types, if required.
+// checkExtensions checks for unwanted extension types, if required.
+func (cv chainValidator) checkExtensions(cert *x509.Certificate) error {
 	// TODO(al): Refactor CertValidationOpts c'tor to a builder pattern and
 	// pre-calc this in there
 	if len(cv.rejectExtIds) != 0 {
@@ -206,11 +373,16 @@ func (cv chainValidator) validate(rawChain [][]byte) ([]*x509.Certificate, error
 		for idx, ext := range cert.Extensions {
 			extOid := ext.Id.String()
 			if _, ok := badIDs[extOid]; ok {
-				return nil, fmt.Errorf("rejecting certificate containing extension %v at index %d", extOid, idx)
+				return rejectf(ReasonForbiddenExtension, "rejecting certificate containing extension %v at index %d", extOid, idx)
 			}
 		}
 	}
+	return nil
+}
 
+// checkExtKeyUsages checks that the certificate has one of the accepted EKUs,
+// if required.
+func (cv chainValidator) checkExtKeyUsages(cert *x509.Certificate) error {
 	// TODO(al): Refactor CertValidationOpts c'tor to a builder pattern and
 	// pre-calc this in there too.
 	if len(cv.extKeyUsages) > 0 {
@@ -226,7 +398,33 @@ func (cv chainValidator) validate(rawChain [][]byte) ([]*x509.Certificate, error
 			}
 		}
 		if !good {
-			return nil, fmt.Errorf("rejecting certificate without EKU in %v", cv.extKeyUsages)
+			return rejectf(ReasonEKUMismatch, "rejecting certificate without EKU in %v", cv.extKeyUsages)
+		}
+	}
+	return nil
+}
+
+// checkKey rejects certificates whose key has weakness w, if weak keys are
+// rejected.
+func (cv chainValidator) checkKey(w keyWeakness) error {
+	if w == "" || cv.weakKeys == nil || !cv.weakKeys.reject {
+		return nil
+	}
+	return rejectf(ReasonWeakKey, "rejecting certificate with weak key: %s", w)
+}
+
+// verify returns the paths from the first certificate of chain to a trusted
+// root, possibly using the other certificates of chain as intermediates, and
+// known issuers if chains are completed.
+func (cv chainValidator) verify(chain []*x509.Certificate, now time.Time) ([][]*x509.Certificate, error) {
+	// All but the first cert form part of the intermediate pool
+	intermediatePool := x509util.NewPEMCertPool()
+	for _, cert := range chain[1:] {
+		intermediatePool.AddCert(cert)
+	}
+	if cv.knownIssuers != nil {
+		for _, cert := range cv.knownIssuers.candidates(chain) {
+			intermediatePool.AddCert(cert)
 		}
 	}
 
@@ -236,20 +434,31 @@ func (cv chainValidator) validate(rawChain [][]byte) ([]*x509.Certificate, error
 	//  - allow certificate without policing them since this is not CT's responsibility
 	// See /internal/lax509/README.md for further information.
 	verifyOpts := lax509.VerifyOptions{
-		Roots:         cv.trustedRoots.CertPool(),
+		Roots:         cv.trustedRoots.Pool(now).CertPool(),
 		Intermediates: intermediatePool.CertPool(),
 		KeyUsages:     cv.extKeyUsages,
 	}
 
-	verifiedChains, err := lax509.Verify(cert, verifyOpts)
+	verifiedChains, err := lax509.Verify(chain[0], verifyOpts)
 	if err != nil {
-		return nil, err
+		return nil, &ValidationError{Reason: verifyErrorReason(err), Err: err}
 	}
 
 	if len(verifiedChains) == 0 {
-		return nil, errors.New("no path to root found when trying to validate chains")
+		return nil, rejectf(ReasonUnknownRoot, "no path to root found when trying to validate chains")
 	}
+	return verifiedChains, nil
+}
 
+// orderedChain returns the first of verifiedChains which uses all the
+// certificates of chain, in order.
+//
+// If there is no such chain, in lenient ordering mode, it returns the
+// shortest of verifiedChains built only from certificates of chain, apart
+// from the root. Then, if chains are completed, it returns the shortest of
+// verifiedChains which goes through all the certificates of chain in order,
+// with missing links filled from known issuers.
+func (cv chainValidator) orderedChain(chain []*x509.Certificate, verifiedChains [][]*x509.Certificate) ([]*x509.Certificate, error) {
 	// Verify might have found multiple paths to roots. Now we check that we have a path that
 	// uses all the certs in the order they were submitted so as to comply with RFC 6962
 	// requirements detailed in Section 3.1.
@@ -259,12 +468,48 @@ func (cv chainValidator) validate(rawChain [][]byte) ([]*x509.Certificate, error
 		}
 	}
 
-	return nil, errors.New("no RFC compliant path to root found when trying to validate chain")
+	if cv.lenientOrdering {
+		var reordered []*x509.Certificate
+		for _, verifiedChain := range verifiedChains {
+			if chainSubmitted(chain, verifiedChain) && (reordered == nil || len(verifiedChain) < len(reordered)) {
+				reordered = verifiedChain
+			}
+		}
+		if reordered != nil {
+			return reordered, nil
+		}
+	}
+
+	if cv.knownIssuers != nil {
+		var completed []*x509.Certificate
+		for _, verifiedChain := range verifiedChains {
+			if chainCompletes(chain, verifiedChain) && (completed == nil || len(verifiedChain) < len(completed)) {
+				completed = verifiedChain
+			}
+		}
+		if completed != nil {
+			klog.V(2).Infof("Completed chain of %d certificates with %d known issuers", len(chain), len(completed)-len(chain))
+			return completed, nil
+		}
+	}
+
+	return nil, rejectf(ReasonOutOfOrderChain, "no RFC compliant path to root found when trying to validate chain")
+}
+
+// verifyErrorReason returns the reason to reject a chain which failed
+// verification with err.
+func verifyErrorReason(err error) RejectionReason {
+	var uaErr lax509.UnknownAuthorityError
+	if errors.As(err, &uaErr) {
+		return ReasonUnknownRoot
+	}
+	return ReasonInvalidChain
 }
 
 // Validate is used by add-chain and add-pre-chain. It checks that the supplied
-// cert is of the correct type, chains to a trusted root and satisties time
-// constraints.
+// cert is of the correct type, chains to a trusted root, satisties time
+// constraints and the log's policy. Rejected chains return a
+// *ValidationError.
 // TODO(phbnf): add tests
 // TODO(phbnf): merge with validate
 func (cv chainValidator) Validate(req rfc6962.AddChainRequest, expectingPrecert bool) ([]*x509.Certificate, error) {
@@ -273,12 +518,12 @@ func (cv chainValidator) Validate(req rfc6962.AddChainRequest, expectingPrecert
 	if err != nil {
 		// We rejected it because the cert failed checks or we could not find a path to a root etc.
 		// Lots of possible causes for errors
-		return nil, fmt.Errorf("chain failed to validate: %s", err)
+		return nil, fmt.Errorf("chain failed to validate: %w", err)
 	}
 
 	isPrecert, err := isPrecertificate(validPath[0])
 	if err != nil {
-		return nil, fmt.Errorf("precert test failed: %s", err)
+		return nil, rejectf(ReasonPrecertMismatch, "precert test failed: %s", err)
 	}
 
 	// The type of the leaf must match the one the handler expects
@@ -288,14 +533,167 @@ func (cv chainValidator) Validate(req rfc6962.AddChainRequest, expectingPrecert
 		} else {
 			klog.Warningf("Precert (or cert with invalid CT ext) submitted as cert chain: %q", req.Chain)
 		}
-		return nil, fmt.Errorf("cert / precert mismatch: %T", expectingPrecert)
+		return nil, rejectf(ReasonPrecertMismatch, "cert / precert mismatch: got precert=%t, want %t", isPrecert, expectingPrecert)
 	}
 
+	if err := cv.policy.Evaluate(validPath, isPrecert); err != nil {
+		return nil, err
+	}
 	return validPath, nil
 }
 
+// rememberIssuers adds the intermediates of chain, a verified path whose
+// issuers have been stored by the log, to the issuers used to complete
+// chains, if chains are completed.
+func (cv chainValidator) rememberIssuers(chain []*x509.Certificate) {
+	if cv.knownIssuers == nil || len(chain) < 3 {
+		return
+	}
+	cv.knownIssuers.Add(chain[1 : len(chain)-1]...)
+}
+
+// loadKnownIssuers loads the issuers stored in s to complete chains, if
+// chains are completed.
+func (cv chainValidator) loadKnownIssuers(ctx context.Context, s IssuerSource) {
+	if cv.knownIssuers == nil {
+		return
+	}
+	if err := cv.knownIssuers.Load(ctx, s); err != nil {
+		klog.Warningf("Failed to load known issuers to complete chains: %v", err)
+	}
+}
+
+// CheckResult is the outcome of a single chain validation check.
+type CheckResult struct {
+	// Name identifies the check.
+	Name string `json:"name"`
+	// Passed is true if the chain passed the check.
+	Passed bool `json:"passed"`
+	// Reason and Message describe why the chain failed the check.
+	Reason  RejectionReason `json:"reason,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+// newCheckResult returns the result of the check called name, which failed
+// if err is not nil.
+func newCheckResult(name string, err error) CheckResult {
+	c := CheckResult{Name: name, Passed: err == nil}
+	if err != nil {
+		c.Message = err.Error()
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			c.Reason = vErr.Reason
+		}
+	}
+	return c
+}
+
+// ChainReport details the outcome of the checks run on a chain.
+type ChainReport struct {
+	// Checks lists the result of each check, in the order they were run.
+	Checks []CheckResult
+	// Precert is set to whether the leaf is a precertificate, if this
+	// could be determined.
+	Precert *bool
+	// Path is the verified path from the leaf to an accepted root, which
+	// would be logged, if the chain passed all the checks.
+	Path []*x509.Certificate
+}
+
+// Check runs the checks of Validate on rawChain, without stopping at the
+// first failure, and reports their outcome. Checks which depend on a failed
+// one are not run. Unlike Validate, Check has no side effect.
+func (cv chainValidator) Check(rawChain [][]byte) ChainReport {
+	r := ChainReport{}
+	add := func(name string, err error) bool {
+		r.Checks = append(r.Checks, newCheckResult(name, err))
+		return err == nil
+	}
+
+	chain, err := parseRawChain(rawChain)
+	if !add("parse", err) {
+		return r
+	}
+	now := cv.now()
+	cert := chain[0]
+	add("not_after_range", cv.checkNotAfter(cert))
+	add("expiry", cv.checkExpiry(cert, now))
+	add("extensions", cv.checkExtensions(cert))
+	add("ext_key_usages", cv.checkExtKeyUsages(cert))
+	add("key", cv.checkKey(cv.weakKeys.detect(cert)))
+	isPrecert, err := isPrecertificate(cert)
+	if err != nil {
+		err = rejectf(ReasonPrecertMismatch, "precert test failed: %s", err)
+	} else {
+		r.Precert = &isPrecert
+	}
+	add("precert", err)
+
+	verifiedChains, err := cv.verify(chain, now)
+	if !add("path_to_root", err) {
+		return r
+	}
+	validPath, err := cv.orderedChain(chain, verifiedChains)
+	if !add("chain_order", err) || r.Precert == nil {
+		return r
+	}
+	if cv.policy != nil {
+		add("policy", cv.policy.Evaluate(validPath, *r.Precert))
+	}
+	if slices.ContainsFunc(r.Checks, func(c CheckResult) bool { return !c.Passed }) {
+		return r
+	}
+	r.Path = validPath
+	return r
+}
+
+// now returns the time against which chains are validated.
+func (cv chainValidator) now() time.Time {
+	if !cv.currentTime.IsZero() {
+		return cv.currentTime
+	}
+	if cv.timeSource != nil {
+		return cv.timeSource.Now()
+	}
+	return time.Now()
+}
+
+// Roots returns the roots currently accepted.
 func (cv chainValidator) Roots() []*x509.Certificate {
-	return cv.trustedRoots.RawCertificates()
+	return cv.trustedRoots.Pool(cv.now()).RawCertificates()
+}
+
+// GetRootsResponse returns the get-roots response body for the roots
+// currently accepted.
+func (cv chainValidator) GetRootsResponse() []byte {
+	return cv.trustedRoots.GetRootsResponse(cv.now())
+}
+
+// chainSubmitted returns whether all the certificates of verifiedChain, apart
+// from its root, are in inChain.
+func chainSubmitted(inChain []*x509.Certificate, verifiedChain []*x509.Certificate) bool {
+	for _, c := range verifiedChain[:len(verifiedChain)-1] {
+		if !slices.ContainsFunc(inChain, c.Equal) {
+			return false
+		}
+	}
+	return true
+}
+
+// chainCompletes returns whether verifiedChain starts with the first
+// certificate of inChain, and goes through all its other certificates in
+// order.
+func chainCompletes(inChain []*x509.Certificate, verifiedChain []*x509.Certificate) bool {
+	if len(verifiedChain) == 0 || !inChain[0].Equal(verifiedChain[0]) {
+		return false
+	}
+	i := 1
+	for _, c := range verifiedChain[1:] {
+		if i < len(inChain) && c.Equal(inChain[i]) {
+			i++
+		}
+	}
+	return i == len(inChain)
 }
 
 func chainsEquivalent(inChain []*x509.Certificate, verifiedChain []*x509.Certificate) bool {
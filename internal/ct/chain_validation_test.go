@@ -19,6 +19,7 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/pem"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -255,35 +256,35 @@ func TestValidateChain(t *testing.T) {
 		t.Fatal("failed to load real intermediate")
 	}
 	cv := chainValidator{
-		trustedRoots: fakeCARoots,
+		trustedRoots: mustNewRoots(t, fakeCARoots),
 	}
 
 	var tests = []struct {
 		desc        string
 		chain       [][]byte
-		wantErr     bool
+		wantReason  RejectionReason
 		wantPathLen int
 		modifyOpts  func(v *chainValidator)
 	}{
 		{
-			desc:    "missing-intermediate-cert",
-			chain:   pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "missing-intermediate-cert",
+			chain:      pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM}),
+			wantReason: ReasonUnknownRoot,
 		},
 		{
-			desc:    "wrong-cert-order",
-			chain:   pemsToDERChain(t, []string{testdata.FakeIntermediateCertPEM, testdata.LeafSignedByFakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "wrong-cert-order",
+			chain:      pemsToDERChain(t, []string{testdata.FakeIntermediateCertPEM, testdata.LeafSignedByFakeIntermediateCertPEM}),
+			wantReason: ReasonOutOfOrderChain,
 		},
 		{
-			desc:    "unrelated-cert-in-chain",
-			chain:   pemsToDERChain(t, []string{testdata.FakeIntermediateCertPEM, testdata.TestCertPEM}),
-			wantErr: true,
+			desc:       "unrelated-cert-in-chain",
+			chain:      pemsToDERChain(t, []string{testdata.FakeIntermediateCertPEM, testdata.TestCertPEM}),
+			wantReason: ReasonOutOfOrderChain,
 		},
 		{
-			desc:    "unrelated-cert-after-chain",
-			chain:   pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM, testdata.TestCertPEM}),
-			wantErr: true,
+			desc:       "unrelated-cert-after-chain",
+			chain:      pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM, testdata.TestCertPEM}),
+			wantReason: ReasonOutOfOrderChain,
 		},
 		{
 			desc:        "valid-chain",
@@ -316,9 +317,33 @@ func TestValidateChain(t *testing.T) {
 			wantPathLen: 4,
 		},
 		{
-			desc:    "misordered-chain-of-len-4",
-			chain:   pemFileToDERChain(t, "../testdata/subleaf.misordered.chain"),
-			wantErr: true,
+			desc:       "misordered-chain-of-len-4",
+			chain:      pemFileToDERChain(t, "../testdata/subleaf.misordered.chain"),
+			wantReason: ReasonOutOfOrderChain,
+		},
+		{
+			desc:        "lenient-misordered-chain-of-len-4",
+			chain:       pemFileToDERChain(t, "../testdata/subleaf.misordered.chain"),
+			wantPathLen: 4,
+			modifyOpts: func(v *chainValidator) {
+				v.lenientOrdering = true
+			},
+		},
+		{
+			desc:        "lenient-unrelated-cert-after-chain",
+			chain:       pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM, testdata.TestCertPEM}),
+			wantPathLen: 3,
+			modifyOpts: func(v *chainValidator) {
+				v.lenientOrdering = true
+			},
+		},
+		{
+			desc:       "lenient-missing-intermediate-cert",
+			chain:      pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM}),
+			wantReason: ReasonUnknownRoot,
+			modifyOpts: func(v *chainValidator) {
+				v.lenientOrdering = true
+			},
 		},
 		{
 			desc:  "reject-non-existent-ext-id",
@@ -339,27 +364,27 @@ func TestValidateChain(t *testing.T) {
 			wantPathLen: 2,
 		},
 		{
-			desc:    "reject-ext-id",
-			chain:   pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "reject-ext-id",
+			chain:      pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM}),
+			wantReason: ReasonForbiddenExtension,
 			modifyOpts: func(v *chainValidator) {
 				// reject ExtendedKeyUsage extension
 				v.rejectExtIds = []asn1.ObjectIdentifier{[]int{2, 5, 29, 37}}
 			},
 		},
 		{
-			desc:    "reject-ext-id-precert",
-			chain:   pemsToDERChain(t, []string{testdata.PrecertPEMValid}),
-			wantErr: true,
+			desc:       "reject-ext-id-precert",
+			chain:      pemsToDERChain(t, []string{testdata.PrecertPEMValid}),
+			wantReason: ReasonForbiddenExtension,
 			modifyOpts: func(v *chainValidator) {
 				// reject ExtendedKeyUsage extension
 				v.rejectExtIds = []asn1.ObjectIdentifier{[]int{2, 5, 29, 37}}
 			},
 		},
 		{
-			desc:    "reject-eku-not-present-in-cert",
-			chain:   pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "reject-eku-not-present-in-cert",
+			chain:      pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM}),
+			wantReason: ReasonEKUMismatch,
 			modifyOpts: func(v *chainValidator) {
 				// reject cert without ExtKeyUsageEmailProtection
 				v.extKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}
@@ -374,9 +399,9 @@ func TestValidateChain(t *testing.T) {
 			},
 		},
 		{
-			desc:    "reject-eku-not-present-in-precert",
-			chain:   pemsToDERChain(t, []string{testdata.RealPrecertWithEKUPEM}),
-			wantErr: true,
+			desc:       "reject-eku-not-present-in-precert",
+			chain:      pemsToDERChain(t, []string{testdata.RealPrecertWithEKUPEM}),
+			wantReason: ReasonEKUMismatch,
 			modifyOpts: func(v *chainValidator) {
 				// reject cert without ExtKeyUsageEmailProtection
 				v.extKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}
@@ -391,14 +416,14 @@ func TestValidateChain(t *testing.T) {
 			},
 		},
 		{
-			desc:    "empty-chain",
-			chain:   [][]byte{},
-			wantErr: true,
+			desc:       "empty-chain",
+			chain:      [][]byte{},
+			wantReason: ReasonParseError,
 		},
 		{
-			desc:    "nil-chain",
-			chain:   nil,
-			wantErr: true,
+			desc:       "nil-chain",
+			chain:      nil,
+			wantReason: ReasonParseError,
 		},
 	}
 	for _, test := range tests {
@@ -409,12 +434,13 @@ func TestValidateChain(t *testing.T) {
 			}
 			gotPath, err := opts.validate(test.chain)
 			if err != nil {
-				if !test.wantErr {
-					t.Errorf("ValidateChain()=%v,%v; want _,nil", gotPath, err)
+				var vErr *ValidationError
+				if !errors.As(err, &vErr) || vErr.Reason != test.wantReason {
+					t.Errorf("ValidateChain()=%v,%v; want _,ValidationError with reason %q", gotPath, err, test.wantReason)
 				}
 				return
 			}
-			if test.wantErr {
+			if test.wantReason != "" {
 				t.Errorf("ValidateChain()=%v,%v; want _,non-nil", gotPath, err)
 				return
 			}
@@ -434,7 +460,7 @@ func TestNotAfterRange(t *testing.T) {
 		t.Fatal("failed to load fake root")
 	}
 	opts := chainValidator{
-		trustedRoots:  fakeCARoots,
+		trustedRoots:  mustNewRoots(t, fakeCARoots),
 		rejectExpired: false,
 	}
 
@@ -483,6 +509,10 @@ func TestNotAfterRange(t *testing.T) {
 				if !test.wantErr {
 					t.Errorf("ValidateChain()=%v,%v; want _,nil", gotPath, err)
 				}
+				var vErr *ValidationError
+				if !errors.As(err, &vErr) || vErr.Reason != ReasonNotAfterOutOfRange {
+					t.Errorf("ValidateChain()=_,%v; want ValidationError with reason %q", err, ReasonNotAfterOutOfRange)
+				}
 				return
 			}
 			if test.wantErr {
@@ -501,7 +531,7 @@ func TestRejectExpiredUnexpired(t *testing.T) {
 	// Validity period: May 13, 2016 - Jul 12, 2019.
 	chain := pemsToDERChain(t, []string{testdata.LeafSignedByFakeIntermediateCertPEM, testdata.FakeIntermediateCertPEM})
 	opts := chainValidator{
-		trustedRoots: fakeCARoots,
+		trustedRoots: mustNewRoots(t, fakeCARoots),
 		extKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 	}
 	beforeValidPeriod := time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -700,7 +730,7 @@ func TestPreIssuedCert(t *testing.T) {
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			opts := chainValidator{
-				trustedRoots: roots,
+				trustedRoots: mustNewRoots(t, roots),
 				extKeyUsages: tc.eku,
 			}
 			chain, err := opts.validate(tc.chain)
@@ -713,3 +743,104 @@ func TestPreIssuedCert(t *testing.T) {
 		})
 	}
 }
+
+func TestCompleteChain(t *testing.T) {
+	fakeCARoots := x509util.NewPEMCertPool()
+	if !fakeCARoots.AppendCertsFromPEM([]byte(testdata.FakeCACertPEM)) {
+		t.Fatal("failed to load fake root")
+	}
+	leaf := pemToCert(t, testdata.LeafSignedByFakeIntermediateCertPEM)
+	intermediate := pemToCert(t, testdata.FakeIntermediateCertPEM)
+	root := pemToCert(t, testdata.FakeCACertPEM)
+
+	newValidator := func(known ...*x509.Certificate) chainValidator {
+		p := NewIssuerPool()
+		p.Add(known...)
+		return chainValidator{
+			trustedRoots: mustNewRoots(t, fakeCARoots),
+			knownIssuers: p,
+		}
+	}
+	validate := func(cv chainValidator, chain ...*x509.Certificate) ([]*x509.Certificate, error) {
+		req := rfc6962.AddChainRequest{}
+		for _, c := range chain {
+			req.Chain = append(req.Chain, c.Raw)
+		}
+		return cv.Validate(req, false)
+	}
+
+	for _, test := range []struct {
+		desc       string
+		known      []*x509.Certificate
+		chain      []*x509.Certificate
+		wantReason RejectionReason
+	}{
+		{
+			desc:  "complete",
+			chain: []*x509.Certificate{leaf, intermediate},
+		},
+		{
+			desc:  "missing-intermediate",
+			known: []*x509.Certificate{intermediate},
+			chain: []*x509.Certificate{leaf},
+		},
+		{
+			desc:  "missing-intermediate-with-root",
+			known: []*x509.Certificate{intermediate},
+			chain: []*x509.Certificate{leaf, root},
+		},
+		{
+			desc:       "unknown-intermediate",
+			chain:      []*x509.Certificate{leaf},
+			wantReason: ReasonUnknownRoot,
+		},
+		{
+			desc:       "misordered",
+			known:      []*x509.Certificate{intermediate},
+			chain:      []*x509.Certificate{leaf, root, intermediate},
+			wantReason: ReasonOutOfOrderChain,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := validate(newValidator(test.known...), test.chain...)
+			if test.wantReason != "" {
+				var vErr *ValidationError
+				if !errors.As(err, &vErr) || vErr.Reason != test.wantReason {
+					t.Errorf("Validate()=_,%v; want ValidationError with reason %q", err, test.wantReason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate()=_,%v; want _,nil", err)
+			}
+			want := []*x509.Certificate{leaf, intermediate, root}
+			if len(got) != len(want) {
+				t.Fatalf("|Validate()|=%d; want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !got[i].Equal(want[i]) {
+					t.Errorf("Validate()[%d]=%s; want %s", i, got[i].Subject, want[i].Subject)
+				}
+			}
+		})
+	}
+
+	t.Run("remember-stored-issuers", func(t *testing.T) {
+		cv := newValidator()
+		if _, err := validate(cv, leaf); err == nil {
+			t.Fatal("Validate()=_,nil before learning the intermediate; want error")
+		}
+		path, err := validate(cv, leaf, intermediate)
+		if err != nil {
+			t.Fatalf("Validate()=_,%v; want _,nil", err)
+		}
+		// Validating a chain doesn't learn its issuers, storing them does.
+		if _, err := validate(cv, leaf); err == nil {
+			t.Fatal("Validate()=_,nil before remembering the intermediate; want error")
+		}
+		cv.rememberIssuers(path)
+		if _, err := validate(cv, leaf); err != nil {
+			t.Errorf("Validate()=_,%v after learning the intermediate; want _,nil", err)
+		}
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/rsa"
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/transparency-dev/tessera/ctonly"
 	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
@@ -14,7 +16,7 @@ import (
 	"k8s.io/klog/v2"
 )
 
-// log provides objects and functions to implement static-ct-api write api.
+// log provides objects and functions to implement static-ct-api write and read apis.
 // TODO(phboneff): consider moving to methods.
 type log struct {
 	// origin identifies the log. It will be used in its checkpoint, and
@@ -26,6 +28,20 @@ type log struct {
 	chainValidator ChainValidator
 	// storage stores certificate data.
 	storage Storage
+	// reader reads static-ct-api objects from the log.
+	reader ReadStorage
+	// cpKeyHash identifies checkpoint signatures from the log.
+	cpKeyHash uint32
+	// leafIndex maps leaf hashes to their index in the log.
+	leafIndex LeafIndexLookup
+	// stopped is set once the log starts shutting down.
+	stopped atomic.Bool
+	// lastPushback is the time, in milliseconds since the epoch, at which
+	// the storage last pushed back on an entry.
+	lastPushback atomic.Int64
+	// pushbackSince is the time, in milliseconds since the epoch, at which
+	// the storage started pushing back on entries without interruption.
+	pushbackSince atomic.Int64
 }
 
 // signSCT builds an SCT for a leaf.
@@ -37,12 +53,31 @@ type Storage interface {
 	Add(context.Context, *ctonly.Entry) (idx uint64, timestamp uint64, err error)
 	// AddIssuerChain stores every the chain certificate in a content-addressable store under their sha256 hash.
 	AddIssuerChain(context.Context, []*x509.Certificate) error
+	// Shutdown stops accepting new entries, and waits for the ones already
+	// added to be integrated and committed to by a published checkpoint.
+	Shutdown(context.Context) error
 }
 
 // ChainValidator provides functions to validate incoming chains.
 type ChainValidator interface {
 	Validate(req rfc6962.AddChainRequest, expectingPrecert bool) ([]*x509.Certificate, error)
+	// Check runs the checks of Validate on a chain, without side effects, and
+	// reports the outcome of each of them, and the path Validate would
+	// return.
+	Check(rawChain [][]byte) ChainReport
 	Roots() []*x509.Certificate
+	// GetRootsResponse returns the get-roots response body.
+	GetRootsResponse() []byte
+}
+
+// knownIssuersKeeper is implemented by ChainValidators which complete chains
+// with the issuers stored by the log.
+type knownIssuersKeeper interface {
+	// loadKnownIssuers loads the issuers already stored by the log.
+	loadKnownIssuers(ctx context.Context, s IssuerSource)
+	// rememberIssuers records the issuers of a chain, once they have been
+	// stored by the log.
+	rememberIssuers(chain []*x509.Certificate)
 }
 
 // NewLog instantiates a new log instance, with write endpoints.
@@ -50,7 +85,12 @@ type ChainValidator interface {
 //   - checkpoint signer
 //   - SCT signer
 //   - storage, used to persist chains
-func NewLog(ctx context.Context, origin string, signer crypto.Signer, cv ChainValidator, cs storage.CreateStorage, ts TimeSource) (*log, error) {
+//
+// If deterministicSCTs is true, SCTs are signed deterministically, such that
+// identical leaves with identical timestamps get byte-identical SCTs. This
+// requires signer to produce deterministic signatures when its source of
+// randomness is nil, such as RFC 6979 ECDSA signatures, which is checked.
+func NewLog(ctx context.Context, origin string, signer crypto.Signer, deterministicSCTs bool, cv ChainValidator, cs storage.CreateStorage, ts TimeSource) (*log, error) {
 	log := &log{}
 
 	if origin == "" {
@@ -58,17 +98,26 @@ func NewLog(ctx context.Context, origin string, signer crypto.Signer, cv ChainVa
 	}
 	log.origin = origin
 
-	// Validate signer that only ECDSA is supported.
+	// Validate signer that only ECDSA and RSA are supported.
 	if signer == nil {
 		return nil, errors.New("empty signer")
 	}
 	switch keyType := signer.Public().(type) {
 	case *ecdsa.PublicKey:
+	case *rsa.PublicKey:
+		if n := keyType.N.BitLen(); n < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key is too small: got %d bits, want at least %d", n, minRSAKeyBits)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported key type: %v", keyType)
 	}
 
-	sctSigner := &sctSigner{signer: signer}
+	if deterministicSCTs {
+		if err := checkDeterministic(signer); err != nil {
+			return nil, err
+		}
+	}
+	sctSigner := &sctSigner{signer: signer, deterministic: deterministicSCTs}
 	log.signSCT = sctSigner.Sign
 
 	log.chainValidator = cv
@@ -77,12 +126,35 @@ func NewLog(ctx context.Context, origin string, signer crypto.Signer, cv ChainVa
 	if err != nil {
 		klog.Exitf("failed to create checkpoint Signer: %v", err)
 	}
+	log.cpKeyHash = cpSigner.KeyHash()
 
 	storage, err := cs(ctx, cpSigner)
 	if err != nil {
 		klog.Exitf("failed to initiate storage backend: %v", err)
 	}
 	log.storage = storage
+	log.reader = storage
+	log.leafIndex = storage
+
+	if l, ok := cv.(knownIssuersKeeper); ok {
+		go l.loadKnownIssuers(ctx, storage)
+	}
 
 	return log, nil
 }
+
+// Shutdown stops the log from accepting new entries, and waits for those
+// already added to be integrated and published, or for ctx to be done.
+func (l *log) Shutdown(ctx context.Context) error {
+	l.stopped.Store(true)
+	return l.storage.Shutdown(ctx)
+}
+
+// recordPushback records that the storage pushed back on an entry at
+// timeMillis. Pushbacks less than pushbackReadinessWindow apart are part of the
+// same uninterrupted pushback.
+func (l *log) recordPushback(timeMillis int64) {
+	if last := l.lastPushback.Swap(timeMillis); last == 0 || timeMillis-last >= pushbackReadinessWindow.Milliseconds() {
+		l.pushbackSince.Store(timeMillis)
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -26,20 +28,29 @@ func TestNewLog(t *testing.T) {
 		t.Fatalf("Can't open key: %v", err)
 	}
 	rsaSigner, err := loadPEMPrivateKey("../testdata/test_ct_server_rsa_private_key.pem")
+	if err != nil {
+		t.Fatalf("Can't open key: %v", err)
+	}
+	smallRSASigner, err := rsa.GenerateKey(rand.Reader, 1024)
 	if err != nil {
 		t.Fatalf("Failed to generate RSA key: %v", err)
 	}
+	_, ed25519Signer, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
 	roots := x509util.NewPEMCertPool()
 	if err := roots.AppendCertsFromPEMFile("../testdata/fake-ca.cert"); err != nil {
 		t.Fatalf("Can't open roots: %v", err)
 	}
 
 	for _, tc := range []struct {
-		desc    string
-		origin  string
-		wantErr string
-		cv      chainValidator
-		signer  crypto.Signer
+		desc          string
+		origin        string
+		wantErr       string
+		cv            chainValidator
+		signer        crypto.Signer
+		deterministic bool
 	}{
 		{
 			desc:    "empty-origin",
@@ -49,7 +60,7 @@ func TestNewLog(t *testing.T) {
 			desc:   "empty-signer",
 			origin: "testlog",
 			cv: chainValidator{
-				trustedRoots: roots,
+				trustedRoots: mustNewRoots(t, roots),
 			},
 			wantErr: "empty signer",
 		},
@@ -57,22 +68,58 @@ func TestNewLog(t *testing.T) {
 			desc:   "ok",
 			origin: "testlog",
 			cv: chainValidator{
-				trustedRoots: roots,
+				trustedRoots: mustNewRoots(t, roots),
 			},
 			signer: ecdsaSigner,
 		},
+		{
+			desc:   "ok-deterministic",
+			origin: "testlog",
+			cv: chainValidator{
+				trustedRoots: mustNewRoots(t, roots),
+			},
+			signer:        ecdsaSigner,
+			deterministic: true,
+		},
+		{
+			desc:   "randomized-signer-deterministic",
+			origin: "testlog",
+			cv: chainValidator{
+				trustedRoots: mustNewRoots(t, roots),
+			},
+			signer:        randomizedSigner{ecdsaSigner.(*ecdsa.PrivateKey)},
+			deterministic: true,
+			wantErr:       "signer doesn't produce deterministic signatures",
+		},
+		{
+			desc:   "ok-rsa",
+			origin: "testlog",
+			cv: chainValidator{
+				trustedRoots: mustNewRoots(t, roots),
+			},
+			signer: rsaSigner,
+		},
+		{
+			desc:   "rsa-key-too-small",
+			origin: "testlog",
+			cv: chainValidator{
+				trustedRoots: mustNewRoots(t, roots),
+			},
+			signer:  smallRSASigner,
+			wantErr: "RSA key is too small",
+		},
 		{
 			desc:   "incorrect-signer-type",
 			origin: "testlog",
 			cv: chainValidator{
-				trustedRoots: roots,
+				trustedRoots: mustNewRoots(t, roots),
 			},
-			signer:  rsaSigner,
+			signer:  ed25519Signer,
 			wantErr: "unsupported key type",
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
-			log, err := NewLog(ctx, tc.origin, tc.signer, tc.cv,
+			log, err := NewLog(ctx, tc.origin, tc.signer, tc.deterministic, tc.cv,
 				func(_ context.Context, _ note.Signer) (*storage.CTStorage, error) {
 					return &storage.CTStorage{}, nil
 				}, &FixedTimeSource{})
@@ -89,6 +136,16 @@ func TestNewLog(t *testing.T) {
 	}
 }
 
+// randomizedSigner signs with an ECDSA key, always using a source of
+// randomness.
+type randomizedSigner struct {
+	*ecdsa.PrivateKey
+}
+
+func (s randomizedSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.PrivateKey.Sign(rand.Reader, digest, opts)
+}
+
 func loadPEMPrivateKey(path string) (crypto.Signer, error) {
 	keyBytes, err := os.ReadFile(path)
 	if err != nil {
@@ -33,6 +33,7 @@ import (
 	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
 	"github.com/transparency-dev/tesseract/internal/types/tls"
 	"github.com/transparency-dev/tesseract/internal/x509util"
+	"github.com/transparency-dev/tesseract/storage"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
@@ -66,7 +67,7 @@ var (
 	lastSCTIndex     metric.Int64Gauge       // origin => value
 	lastSCTTimestamp metric.Int64Gauge       // origin => value
 	reqCounter       metric.Int64Counter     // origin, op => value
-	rspCounter       metric.Int64Counter     // origin, op, code => value
+	rspCounter       metric.Int64Counter     // origin, op, code, reason => value
 	reqDuration      metric.Float64Histogram // origin, op, code => value
 )
 
@@ -118,7 +119,7 @@ type appHandler struct {
 // ServeHTTP for an AppHandler invokes the underlying handler function but
 // does additional common error and stats processing.
 func (a appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	logCtx := a.opts.RequestLog.start(r.Context())
+	logCtx := a.opts.RequestLog.Start(r.Context())
 	logCtx, span := tracer.Start(logCtx, fmt.Sprintf("tesseract.ServeHTTP.%s", a.name))
 	defer span.End()
 
@@ -128,7 +129,7 @@ func (a appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	reqCounter.Add(logCtx, 1, metric.WithAttributes(attrs...))
 	startTime := time.Now()
-	a.opts.RequestLog.origin(logCtx, a.log.origin)
+	a.opts.RequestLog.Origin(logCtx, a.log.origin)
 	defer func() {
 		latency := time.Since(startTime).Seconds()
 		reqDuration.Record(r.Context(), latency, metric.WithAttributes(attrs...))
@@ -139,7 +140,7 @@ func (a appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != a.method {
 		klog.Warningf("%s: %s wrong HTTP method: %v", a.log.origin, a.name, r.Method)
 		a.opts.sendHTTPError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
-		a.opts.RequestLog.status(logCtx, http.StatusMethodNotAllowed)
+		a.opts.RequestLog.Status(logCtx, http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -148,7 +149,7 @@ func (a appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		if err := r.ParseForm(); err != nil {
 			a.opts.sendHTTPError(w, http.StatusBadRequest, fmt.Errorf("failed to parse form data: %s", err))
-			a.opts.RequestLog.status(logCtx, http.StatusBadRequest)
+			a.opts.RequestLog.Status(logCtx, http.StatusBadRequest)
 			return
 		}
 	}
@@ -161,7 +162,7 @@ func (a appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	statusCode, hattrs, err := a.handler(ctx, a.opts, a.log, w, r)
 	attrs = append(attrs, hattrs...)
 	attrs = append(attrs, codeKey.Int(statusCode))
-	a.opts.RequestLog.status(ctx, statusCode)
+	a.opts.RequestLog.Status(ctx, statusCode)
 	klog.V(2).Infof("%s: %s <= st=%d", a.log.origin, a.name, statusCode)
 	rspCounter.Add(logCtx, 1, metric.WithAttributes(attrs...))
 	if err != nil {
@@ -187,7 +188,7 @@ type HandlerOptions struct {
 	// Deadline is a timeout for HTTP requests.
 	Deadline time.Duration
 	// RequestLog provides structured logging of TesseraCT requests.
-	RequestLog requestLog
+	RequestLog RequestLog
 	// MaskInternalErrors indicates if internal server errors should be masked
 	// or returned to the user containing the full error message.
 	MaskInternalErrors bool
@@ -200,10 +201,7 @@ func NewPathHandlers(ctx context.Context, opts *HandlerOptions, log *log) pathHa
 	once.Do(func() { setupMetrics() })
 	knownLogs.Record(ctx, 1, metric.WithAttributes(originKey.String(log.origin)))
 
-	prefix := strings.TrimRight(log.origin, "/")
-	if !strings.HasPrefix(prefix, "/") {
-		prefix = "/" + prefix
-	}
+	prefix := logPrefix(log.origin)
 
 	// Bind each endpoint to an appHandler instance.
 	// TODO(phboneff): try and get rid of PathHandlers and appHandler
@@ -216,8 +214,39 @@ func NewPathHandlers(ctx context.Context, opts *HandlerOptions, log *log) pathHa
 	return ph
 }
 
-// sendHTTPError generates a custom error page to give more information on why something didn't work
+// logPrefix returns the URL path prefix under which a log's endpoints are served.
+func logPrefix(origin string) string {
+	prefix := strings.TrimRight(origin, "/")
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// errorResponse is the JSON body of responses to rejected chains.
+type errorResponse struct {
+	// Code is a stable identifier of the reason why the chain was rejected.
+	Code RejectionReason `json:"code"`
+	// Message is a human readable description of the error.
+	Message string `json:"message"`
+}
+
+// sendHTTPError generates a custom error page to give more information on why something didn't work.
+//
+// Rejected chains get a JSON errorResponse body, and other errors a plain text one.
 func (opts *HandlerOptions) sendHTTPError(w http.ResponseWriter, statusCode int, err error) {
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		body, mErr := json.Marshal(errorResponse{Code: vErr.Reason, Message: err.Error()})
+		if mErr == nil {
+			w.Header().Set(contentTypeHeader, contentTypeJSON)
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(statusCode)
+			_, _ = w.Write(body)
+			return
+		}
+		klog.Warningf("Failed to marshal error response: %v", mErr)
+	}
 	errorBody := http.StatusText(statusCode)
 	if !opts.MaskInternalErrors || statusCode != http.StatusInternalServerError {
 		errorBody += fmt.Sprintf("\n%v", err)
@@ -268,14 +297,18 @@ func addChainInternal(ctx context.Context, opts *HandlerOptions, log *log, w htt
 	}
 	// Log the DERs now because they might not parse as valid X.509.
 	for _, der := range addChainReq.Chain {
-		opts.RequestLog.addDERToChain(ctx, der)
+		opts.RequestLog.AddDERToChain(ctx, der)
 	}
 	chain, err := log.chainValidator.Validate(addChainReq, isPrecert)
 	if err != nil {
-		return http.StatusBadRequest, nil, fmt.Errorf("failed to verify add-chain contents: %s", err)
+		var attrs []attribute.KeyValue
+		if vErr := (*ValidationError)(nil); errors.As(err, &vErr) {
+			attrs = append(attrs, reasonKey.String(string(vErr.Reason)))
+		}
+		return http.StatusBadRequest, attrs, fmt.Errorf("failed to verify add-chain contents: %w", err)
 	}
 	for _, cert := range chain {
-		opts.RequestLog.addCertToChain(ctx, cert)
+		opts.RequestLog.AddCertToChain(ctx, cert)
 	}
 	// Get the current time in the form used throughout RFC6962, namely milliseconds since Unix
 	// epoch, and use this throughout.
@@ -288,19 +321,31 @@ func addChainInternal(ctx context.Context, opts *HandlerOptions, log *log, w htt
 	}
 
 	if err := log.storage.AddIssuerChain(ctx, chain[1:]); err != nil {
+		if errors.Is(err, storage.ErrShuttingDown) {
+			return shuttingDown(w)
+		}
 		return http.StatusInternalServerError, nil, fmt.Errorf("failed to store issuer chain: %s", err)
 	}
+	// Only issuers stored by the log can complete later submissions.
+	if k, ok := log.chainValidator.(knownIssuersKeeper); ok {
+		k.rememberIssuers(chain)
+	}
 
 	klog.V(2).Infof("%s: %s => storage.Add", log.origin, method)
 	index, dedupedTimeMillis, err := log.storage.Add(ctx, entry)
 	if err != nil {
 		if errors.Is(err, tessera.ErrPushback) {
+			log.recordPushback(int64(timeMillis))
 			w.Header().Add("Retry-After", strconv.Itoa(rand.IntN(5)+1)) // random retry within [1,6) seconds
 			return http.StatusTooManyRequests, nil, errors.New(http.StatusText(http.StatusTooManyRequests))
 		}
+		if errors.Is(err, storage.ErrShuttingDown) {
+			return shuttingDown(w)
+		}
 		return http.StatusInternalServerError, nil, fmt.Errorf("couldn't store the leaf: %v", err)
 	}
 	isDup := dedupedTimeMillis != timeMillis
+	opts.RequestLog.Entry(ctx, index, dedupedTimeMillis, isDup)
 	dedupedAttribute := duplicateKey.Bool(isDup)
 	entry.Timestamp = dedupedTimeMillis
 
@@ -324,7 +369,7 @@ func addChainInternal(ctx context.Context, opts *HandlerOptions, log *log, w htt
 		return http.StatusInternalServerError, nil, fmt.Errorf("failed to marshall SCT: %s", err)
 	}
 	// We could possibly fail to issue the SCT after this but it's v. unlikely.
-	opts.RequestLog.issueSCT(ctx, sctBytes)
+	opts.RequestLog.IssueSCT(ctx, sctBytes)
 	err = marshalAndWriteAddChainResponse(sct, w)
 	if err != nil {
 		// reason is logged and http status is already set
@@ -339,6 +384,13 @@ func addChainInternal(ctx context.Context, opts *HandlerOptions, log *log, w htt
 	return http.StatusOK, []attribute.KeyValue{dedupedAttribute}, nil
 }
 
+// shuttingDown asks clients to retry later, once the log is back up or a new
+// instance has taken over.
+func shuttingDown(w http.ResponseWriter) (int, []attribute.KeyValue, error) {
+	w.Header().Add("Retry-After", strconv.Itoa(rand.IntN(5)+1)) // random retry within [1,6) seconds
+	return http.StatusServiceUnavailable, nil, errors.New("log is shutting down")
+}
+
 func addChain(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
 	ctx, span := tracer.Start(ctx, "tesseract.addChain")
 	defer span.End()
@@ -357,18 +409,8 @@ func getRoots(ctx context.Context, opts *HandlerOptions, log *log, w http.Respon
 	_, span := tracer.Start(ctx, "tesseract.getRoots")
 	defer span.End()
 
-	// Pull out the raw certificates from the parsed versions
-	// TODO(phbnf): precompute the answer
-	rawCerts := make([][]byte, 0, len(log.chainValidator.Roots()))
-	for _, cert := range log.chainValidator.Roots() {
-		rawCerts = append(rawCerts, cert.Raw)
-	}
-
-	jsonMap := make(map[string]any)
-	jsonMap[jsonMapKeyCertificates] = rawCerts
-	enc := json.NewEncoder(w)
-	err := enc.Encode(jsonMap)
-	if err != nil {
+	// The response is precomputed, and swapped whenever roots are reloaded.
+	if _, err := w.Write(log.chainValidator.GetRootsResponse()); err != nil {
 		klog.Warningf("%s: get_roots failed: %v", log.origin, err)
 		return http.StatusInternalServerError, nil, fmt.Errorf("get-roots failed with: %s", err)
 	}
@@ -24,6 +24,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -41,11 +42,11 @@ import (
 	posixTessera "github.com/transparency-dev/tessera/storage/posix"
 	badger_as "github.com/transparency-dev/tessera/storage/posix/antispam"
 	"github.com/transparency-dev/tesseract/internal/testdata"
-	"github.com/transparency-dev/tesseract/internal/testonly/storage/posix"
 	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
 	"github.com/transparency-dev/tesseract/internal/types/staticct"
 	"github.com/transparency-dev/tesseract/internal/x509util"
 	"github.com/transparency-dev/tesseract/storage"
+	"github.com/transparency-dev/tesseract/storage/posix"
 	"golang.org/x/mod/sumdb/note"
 	"k8s.io/klog/v2"
 )
@@ -119,12 +120,12 @@ func setupTestLog(t *testing.T) (*log, string) {
 	}
 
 	cv := chainValidator{
-		trustedRoots:    roots,
+		trustedRoots:    mustNewRoots(t, roots),
 		rejectExpired:   false,
 		rejectUnexpired: false,
 	}
 
-	log, err := NewLog(t.Context(), origin, sctSigner.signer, cv, newPOSIXStorageFunc(t, storageDir), timeSource)
+	log, err := NewLog(t.Context(), origin, sctSigner.signer, false, cv, newPOSIXStorageFunc(t, storageDir), timeSource)
 	if err != nil {
 		t.Fatalf("newLog(): %v", err)
 	}
@@ -175,7 +176,7 @@ func newPOSIXStorageFunc(t *testing.T, root string) storage.CreateStorage {
 			WithAntispam(256, antispam).
 			WithCheckpointInterval(time.Second)
 
-		appender, _, reader, err := tessera.NewAppender(ctx, driver, opts)
+		appender, shutdown, reader, err := tessera.NewAppender(ctx, driver, opts)
 		if err != nil {
 			klog.Fatalf("Failed to initialize POSIX Tessera appender: %v", err)
 		}
@@ -185,7 +186,7 @@ func newPOSIXStorageFunc(t *testing.T, root string) storage.CreateStorage {
 			klog.Fatalf("failed to initialize InMemory issuer storage: %v", err)
 		}
 
-		s, err := storage.NewCTStorage(t.Context(), appender, issuerStorage, reader, false)
+		s, err := storage.NewCTStorage(t.Context(), appender, shutdown, issuerStorage, reader, false)
 		if err != nil {
 			klog.Fatalf("Failed to initialize CTStorage: %v", err)
 		}
@@ -466,17 +467,21 @@ func TestAddChain(t *testing.T) {
 		wantIdx       uint64
 		wantLogSize   uint64
 		wantTimestamp time.Time
+		wantReason    RejectionReason
+		wantMessage   string
 		err           error
 	}{
 		{
-			descr: "leaf-only",
-			chain: []string{testdata.CertFromIntermediate},
-			want:  http.StatusBadRequest,
+			descr:      "leaf-only",
+			chain:      []string{testdata.CertFromIntermediate},
+			want:       http.StatusBadRequest,
+			wantReason: ReasonUnknownRoot,
 		},
 		{
-			descr: "wrong-entry-type",
-			chain: []string{testdata.PreCertFromIntermediate},
-			want:  http.StatusBadRequest,
+			descr:      "wrong-entry-type",
+			chain:      []string{testdata.PreCertFromIntermediate},
+			want:       http.StatusBadRequest,
+			wantReason: ReasonUnknownRoot,
 		},
 		{
 			descr:         "success",
@@ -510,6 +515,13 @@ func TestAddChain(t *testing.T) {
 			wantTimestamp: fakeTimeStart.Add(3 * time.Minute),
 			want:          http.StatusOK,
 		},
+		{
+			descr:       "wrong-entry-type-valid-chain",
+			chain:       []string{testdata.PreCertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM},
+			want:        http.StatusBadRequest,
+			wantReason:  ReasonPrecertMismatch,
+			wantMessage: "got precert=true, want false",
+		},
 	}
 
 	log, dir := setupTestLog(t)
@@ -532,6 +544,21 @@ func TestAddChain(t *testing.T) {
 			if got, want := resp.StatusCode, test.want; got != want {
 				t.Errorf("http.Post(%s)=(%d,nil); want (%d,nil)", rfc6962.AddChainPath, got, want)
 			}
+			if test.wantReason != "" {
+				if got, want := resp.Header.Get(contentTypeHeader), contentTypeJSON; got != want {
+					t.Errorf("resp.Header[%s]=%q; want %q", contentTypeHeader, got, want)
+				}
+				var gotRsp errorResponse
+				if err := json.NewDecoder(resp.Body).Decode(&gotRsp); err != nil {
+					t.Fatalf("json.Decode()=%v; want nil", err)
+				}
+				if got, want := gotRsp.Code, test.wantReason; got != want {
+					t.Errorf("resp.Code=%q; want %q", got, want)
+				}
+				if got, want := gotRsp.Message, test.wantMessage; !strings.Contains(got, want) {
+					t.Errorf("resp.Message=%q; want it to contain %q", got, want)
+				}
+			}
 			if test.want == http.StatusOK {
 				unseqEntry, wantIssChain := parseChain(t, false, test.chain, log.chainValidator.Roots()[0], test.wantTimestamp)
 
@@ -606,6 +633,146 @@ func TestAddChain(t *testing.T) {
 	}
 }
 
+func TestAddChainAfterShutdown(t *testing.T) {
+	log, dir := setupTestLog(t)
+	server := setupTestServer(t, log, path.Join(prefix, rfc6962.AddChainPath))
+	defer server.Close()
+
+	post := func(chain ...string) *http.Response {
+		t.Helper()
+		pool := loadCertsIntoPoolOrDie(t, chain)
+		resp, err := http.Post(server.URL+rfc6962.AddChainPath, "application/json", createJSONChain(t, *pool))
+		if err != nil {
+			t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", rfc6962.AddChainPath, err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	// Tessera only waits for a checkpoint on shutdown if an entry other than
+	// the very first one of the log was added, so add two.
+	for _, chain := range [][]string{
+		{testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM},
+		{testdata.TestCertPEM, testdata.CACertPEM},
+	} {
+		if got, want := post(chain...).StatusCode, http.StatusOK; got != want {
+			t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", rfc6962.AddChainPath, got, want)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	if err := log.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown(): %v", err)
+	}
+
+	// The entries added before shutting down must be committed to by the
+	// published checkpoint.
+	cp, err := os.ReadFile(path.Join(dir, logDir, layout.CheckpointPath))
+	if err != nil {
+		t.Fatalf("Failed to read checkpoint: %v", err)
+	}
+	if lines := strings.Split(string(cp), "\n"); len(lines) < 2 || lines[1] != "2" {
+		t.Errorf("Got checkpoint %q, want size 2", cp)
+	}
+
+	resp := post(testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM)
+	if got, want := resp.StatusCode, http.StatusServiceUnavailable; got != want {
+		t.Errorf("http.Post(%s)=(%d,nil) after shutdown; want (%d,nil)", rfc6962.AddChainPath, got, want)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("Missing Retry-After header after shutdown")
+	}
+}
+
+func TestAddChainDuplicateNotCached(t *testing.T) {
+	log, _ := setupTestLog(t)
+	// Only cache the latest timestamp, so that the timestamp of the first
+	// entry has to be read back from its entry bundle.
+	if err := log.storage.(*storage.CTStorage).SetTimestampCacheSize(1); err != nil {
+		t.Fatalf("SetTimestampCacheSize(): %v", err)
+	}
+	server := setupTestServer(t, log, path.Join(prefix, rfc6962.AddChainPath))
+	defer server.Close()
+	defer timeSource.Reset()
+
+	post := func(chain ...string) rfc6962.AddChainResponse {
+		t.Helper()
+		timeSource.Add1m()
+		pool := loadCertsIntoPoolOrDie(t, chain)
+		resp, err := http.Post(server.URL+rfc6962.AddChainPath, "application/json", createJSONChain(t, *pool))
+		if err != nil {
+			t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", rfc6962.AddChainPath, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", rfc6962.AddChainPath, got, want)
+		}
+		var rsp rfc6962.AddChainResponse
+		if err := json.NewDecoder(resp.Body).Decode(&rsp); err != nil {
+			t.Fatalf("json.Decode()=%v; want nil", err)
+		}
+		return rsp
+	}
+
+	first := post(testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM)
+	post(testdata.TestCertPEM, testdata.CACertPEM)
+	dup := post(testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM)
+	if got, want := dup.Timestamp, first.Timestamp; got != want {
+		t.Errorf("Got duplicate timestamp %d, want %d", got, want)
+	}
+	if got, want := dup.Extensions, first.Extensions; got != want {
+		t.Errorf("Got duplicate extensions %q, want %q", got, want)
+	}
+}
+
+// failingIssuersStorage is a Storage failing to store issuers.
+type failingIssuersStorage struct {
+	Storage
+}
+
+func (failingIssuersStorage) AddIssuerChain(context.Context, []*x509.Certificate) error {
+	return errors.New("boom")
+}
+
+func TestAddChainRemembersStoredIssuers(t *testing.T) {
+	log, _ := setupTestLog(t)
+	knownIssuers := NewIssuerPool()
+	cv := log.chainValidator.(chainValidator)
+	cv.knownIssuers = knownIssuers
+	log.chainValidator = cv
+	server := setupTestServer(t, log, path.Join(prefix, rfc6962.AddChainPath))
+	defer server.Close()
+
+	post := func() int {
+		t.Helper()
+		pool := loadCertsIntoPoolOrDie(t, []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM})
+		resp, err := http.Post(server.URL+rfc6962.AddChainPath, "application/json", createJSONChain(t, *pool))
+		if err != nil {
+			t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", rfc6962.AddChainPath, err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	s := log.storage
+	log.storage = failingIssuersStorage{Storage: s}
+	if got, want := post(), http.StatusInternalServerError; got != want {
+		t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", rfc6962.AddChainPath, got, want)
+	}
+	if got := knownIssuers.Len(); got != 0 {
+		t.Errorf("Known issuers after failing to store them: %d, want 0", got)
+	}
+
+	log.storage = s
+	if got, want := post(), http.StatusOK; got != want {
+		t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", rfc6962.AddChainPath, got, want)
+	}
+	if got, want := knownIssuers.Len(), 1; got != want {
+		t.Errorf("Known issuers after storing them: %d, want %d", got, want)
+	}
+}
+
 func TestAddPreChain(t *testing.T) {
 	var tests = []struct {
 		descr         string
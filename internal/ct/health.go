@@ -0,0 +1,193 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// HealthzPath is the path of the liveness endpoint.
+	HealthzPath = "/healthz"
+	// ReadyzPath is the path of the readiness endpoint.
+	ReadyzPath = "/readyz"
+
+	// pushbackReadinessWindow is how long after its storage last pushed back
+	// on an entry a log is still considered to be pushed back on.
+	pushbackReadinessWindow = 5 * time.Second
+)
+
+// ReadinessOptions configures the readiness checks of a log.
+type ReadinessOptions struct {
+	// MaxCheckpointAge is the maximum age of the latest checkpoint of a
+	// ready log. Zero disables this check.
+	MaxCheckpointAge time.Duration
+	// MaxPushback is how long the storage of a ready log may push back on
+	// entries without interruption. Occasional pushback is expected under
+	// load, and doesn't make a log unready. Zero disables this check.
+	MaxPushback time.Duration
+	// TimeSource provides the current time.
+	TimeSource TimeSource
+}
+
+// ReadinessCheck is the outcome of a single readiness check.
+type ReadinessCheck struct {
+	// Name of the check.
+	Name string
+	// Err is nil if the check passed.
+	Err error
+}
+
+// ReadinessProbe runs the readiness checks of a log.
+type ReadinessProbe func(ctx context.Context) []ReadinessCheck
+
+// probeLeaf is signed to check that the SCT signer works.
+var probeLeaf = rfc6962.MerkleTreeLeaf{
+	Version:  rfc6962.V1,
+	LeafType: rfc6962.TimestampedEntryLeafType,
+	TimestampedEntry: &rfc6962.TimestampedEntry{
+		EntryType: rfc6962.X509LogEntryType,
+		X509Entry: &rfc6962.ASN1Cert{Data: []byte("readiness probe")},
+	},
+}
+
+// NewReadinessProbe returns a probe checking that:
+//   - the log is not shutting down
+//   - its latest checkpoint can be read from storage
+//   - this checkpoint is fresh, if opts.MaxCheckpointAge is set
+//   - SCTs can be signed
+//   - its storage has not been pushing back on entries for more than
+//     opts.MaxPushback, if set
+func NewReadinessProbe(log *log, opts ReadinessOptions) ReadinessProbe {
+	return func(ctx context.Context) []ReadinessCheck {
+		now := opts.TimeSource.Now()
+		var checks []ReadinessCheck
+		check := func(name string, err error) {
+			checks = append(checks, ReadinessCheck{Name: name, Err: err})
+		}
+
+		if log.stopped.Load() {
+			check("shutdown", errors.New("log is shutting down"))
+		} else {
+			check("shutdown", nil)
+		}
+
+		sth, err := readSTH(ctx, log)
+		check("storage", err)
+		if opts.MaxCheckpointAge > 0 {
+			if err != nil {
+				check("checkpoint", errors.New("no checkpoint"))
+			} else if age := now.Sub(time.UnixMilli(int64(sth.sig.Timestamp))); age > opts.MaxCheckpointAge {
+				check("checkpoint", fmt.Errorf("latest checkpoint is %s old, more than %s", age.Truncate(time.Millisecond), opts.MaxCheckpointAge))
+			} else {
+				check("checkpoint", nil)
+			}
+		}
+
+		leaf := probeLeaf
+		entry := *probeLeaf.TimestampedEntry
+		entry.Timestamp = uint64(now.UnixMilli())
+		leaf.TimestampedEntry = &entry
+		_, err = log.signSCT(&leaf)
+		check("signer", err)
+
+		if opts.MaxPushback > 0 {
+			last, since := time.UnixMilli(log.lastPushback.Load()), time.UnixMilli(log.pushbackSince.Load())
+			if d := now.Sub(since); now.Sub(last) < pushbackReadinessWindow && d > opts.MaxPushback {
+				check("pushback", fmt.Errorf("storage has been pushing back for %s, more than %s", d.Truncate(time.Millisecond), opts.MaxPushback))
+			} else {
+				check("pushback", nil)
+			}
+		}
+		return checks
+	}
+}
+
+// NewHealthHandlers returns liveness and readiness handlers for logs, keyed
+// by path.
+//
+// The liveness handler always succeeds as long as the process can serve
+// requests. The readiness handler runs the probes of all the logs, keyed by
+// origin, and only succeeds if all their checks pass. Its response lists
+// the outcome of each check, without the errors of failed checks if
+// maskErrors is true.
+func NewHealthHandlers(probes map[string]ReadinessProbe, maskErrors bool) map[string]http.Handler {
+	return map[string]http.Handler{
+		HealthzPath: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("ok\n"))
+		}),
+		ReadyzPath: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			readyz(w, r, probes, maskErrors)
+		}),
+	}
+}
+
+// readyz runs probes concurrently, and writes their outcome.
+func readyz(w http.ResponseWriter, r *http.Request, probes map[string]ReadinessProbe, maskErrors bool) {
+	origins := slices.Sorted(maps.Keys(probes))
+	results := make([][]ReadinessCheck, len(origins))
+	var wg sync.WaitGroup
+	for i, o := range origins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = probes[o](r.Context())
+		}()
+	}
+	wg.Wait()
+
+	// b is the response body, and l its unmasked version.
+	var b, l strings.Builder
+	ready := true
+	for i, o := range origins {
+		for _, c := range results[i] {
+			if c.Err != nil {
+				ready = false
+				fmt.Fprintf(&l, "[-]%s %s failed: %v\n", o, c.Name, c.Err)
+				if maskErrors {
+					fmt.Fprintf(&b, "[-]%s %s failed\n", o, c.Name)
+				} else {
+					fmt.Fprintf(&b, "[-]%s %s failed: %v\n", o, c.Name, c.Err)
+				}
+				continue
+			}
+			fmt.Fprintf(&l, "[+]%s %s ok\n", o, c.Name)
+			fmt.Fprintf(&b, "[+]%s %s ok\n", o, c.Name)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if !ready {
+		klog.V(1).Infof("Readiness check failed:\n%s", l.String())
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(&b, "not ready\n")
+	} else {
+		fmt.Fprint(&b, "ready\n")
+	}
+	_, _ = w.Write([]byte(b.String()))
+}
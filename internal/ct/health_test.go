@@ -0,0 +1,159 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadinessProbe(t *testing.T) {
+	for _, tc := range []struct {
+		desc       string
+		modify     func(*log)
+		after      time.Duration
+		wantFailed []string
+	}{
+		{
+			desc: "ready",
+		},
+		{
+			desc:       "stale-checkpoint",
+			after:      time.Minute,
+			wantFailed: []string{"checkpoint"},
+		},
+		{
+			desc:   "pushback",
+			modify: func(l *log) { l.recordPushback(timeSource.Now().UnixMilli()) },
+		},
+		{
+			desc: "sustained-pushback",
+			modify: func(l *log) {
+				for d := 2 * time.Minute; d >= 0; d -= time.Second {
+					l.recordPushback(timeSource.Now().Add(-d).UnixMilli())
+				}
+			},
+			wantFailed: []string{"pushback"},
+		},
+		{
+			desc: "interrupted-pushback",
+			modify: func(l *log) {
+				l.recordPushback(timeSource.Now().Add(-2 * time.Minute).UnixMilli())
+				l.recordPushback(timeSource.Now().UnixMilli())
+			},
+		},
+		{
+			desc: "old-pushback",
+			modify: func(l *log) {
+				for d := 3 * time.Minute; d >= time.Minute; d -= time.Second {
+					l.recordPushback(timeSource.Now().Add(-d).UnixMilli())
+				}
+			},
+		},
+		{
+			desc:       "shutting-down",
+			modify:     func(l *log) { l.stopped.Store(true) },
+			wantFailed: []string{"shutdown"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			log, dir := setupTestLog(t)
+			waitForCheckpoint(t, dir, 0)
+			if tc.modify != nil {
+				tc.modify(log)
+			}
+			probe := NewReadinessProbe(log, ReadinessOptions{
+				MaxCheckpointAge: 10 * time.Second,
+				MaxPushback:      time.Minute,
+				TimeSource:       newFakeTimeSource(timeSource.Now().Add(tc.after)),
+			})
+
+			var failed []string
+			for _, c := range probe(t.Context()) {
+				if c.Err != nil {
+					failed = append(failed, c.Name)
+				}
+			}
+			if got, want := strings.Join(failed, ","), strings.Join(tc.wantFailed, ","); got != want {
+				t.Errorf("Got failed checks %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHealthHandlers(t *testing.T) {
+	log, dir := setupTestLog(t)
+	waitForCheckpoint(t, dir, 0)
+	ready := NewReadinessProbe(log, ReadinessOptions{TimeSource: timeSource})
+	notReady := func(context.Context) []ReadinessCheck {
+		return []ReadinessCheck{{Name: "storage", Err: io.ErrUnexpectedEOF}}
+	}
+
+	for _, tc := range []struct {
+		desc       string
+		probes     map[string]ReadinessProbe
+		maskErrors bool
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "healthz",
+			probes:     map[string]ReadinessProbe{"a": notReady},
+			path:       HealthzPath,
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			desc:       "readyz",
+			probes:     map[string]ReadinessProbe{"a": ready, "b": ready},
+			path:       ReadyzPath,
+			wantStatus: http.StatusOK,
+			wantBody:   "[+]b signer ok",
+		},
+		{
+			desc:       "readyz-not-ready",
+			probes:     map[string]ReadinessProbe{"a": ready, "b": notReady},
+			path:       ReadyzPath,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[-]b storage failed: unexpected EOF",
+		},
+		{
+			desc:       "readyz-masked-errors",
+			probes:     map[string]ReadinessProbe{"a": ready, "b": notReady},
+			maskErrors: true,
+			path:       ReadyzPath,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[-]b storage failed\n",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			handler := NewHealthHandlers(tc.probes, tc.maskErrors)[tc.path]
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if got, want := w.Code, tc.wantStatus; got != want {
+				t.Errorf("Got status %d, want %d", got, want)
+			}
+			if got := w.Body.String(); !strings.Contains(got, tc.wantBody) {
+				t.Errorf("Got body %q, want it to contain %q", got, tc.wantBody)
+			}
+		})
+	}
+}
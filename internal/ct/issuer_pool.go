@@ -0,0 +1,157 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// maxCompletionIssuers bounds the number of known issuers which can be used
+// to complete a single chain.
+const maxCompletionIssuers = 32
+
+// maxKnownIssuers bounds the number of issuers held by an IssuerPool.
+// A CT log references ~15k unique issuer certificates in 2024, so this leaves
+// plenty of room, while keeping the pool's memory, and the number of issuers
+// read to load it, bounded.
+const maxKnownIssuers = 1 << 16
+
+// IssuerSource lists and reads the issuer certificates stored by a log.
+type IssuerSource interface {
+	// ListIssuers returns the hex encoded sha256 keys of all the stored
+	// issuers.
+	ListIssuers(ctx context.Context) ([][]byte, error)
+	// ReadIssuer returns the DER encoded issuer stored under key.
+	ReadIssuer(ctx context.Context, key []byte) ([]byte, error)
+}
+
+// IssuerPool holds issuer certificates seen by a log, to complete submitted
+// chains with missing intermediates. It is safe for concurrent use.
+//
+// The pool holds up to maxKnownIssuers certificates. Once it is full, further
+// certificates are not added.
+type IssuerPool struct {
+	// limit is the maximum number of certificates in the pool.
+	limit int
+	mu    sync.RWMutex
+	// seen holds the sha256 of the certificates in the pool.
+	seen map[[sha256.Size]byte]bool
+	// bySubject maps raw subjects to the certificates with this subject.
+	bySubject map[string][]*x509.Certificate
+}
+
+// NewIssuerPool returns an empty IssuerPool.
+func NewIssuerPool() *IssuerPool {
+	return &IssuerPool{
+		limit:     maxKnownIssuers,
+		seen:      make(map[[sha256.Size]byte]bool),
+		bySubject: make(map[string][]*x509.Certificate),
+	}
+}
+
+// Add adds certs to the pool, unless they're already in it or the pool is
+// full.
+func (p *IssuerPool) Add(certs ...*x509.Certificate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range certs {
+		id := sha256.Sum256(c.Raw)
+		if p.seen[id] {
+			continue
+		}
+		if len(p.seen) >= p.limit {
+			klog.V(1).Infof("IssuerPool: pool is full with %d issuers, not adding %q", len(p.seen), c.Subject)
+			return
+		}
+		p.seen[id] = true
+		p.bySubject[string(c.RawSubject)] = append(p.bySubject[string(c.RawSubject)], c)
+	}
+}
+
+// Len returns the number of certificates in the pool.
+func (p *IssuerPool) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.seen)
+}
+
+// candidates returns the certificates of the pool which may be needed to
+// chain the certificates of chain to a root: their potential issuers, the
+// potential issuers of these, and so on, up to maxCompletionIssuers.
+func (p *IssuerPool) candidates(chain []*x509.Certificate) []*x509.Certificate {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var found []*x509.Certificate
+	visited := make(map[string]bool)
+	queue := make([]*x509.Certificate, len(chain))
+	copy(queue, chain)
+	for len(queue) > 0 && len(found) < maxCompletionIssuers {
+		c := queue[0]
+		queue = queue[1:]
+		if visited[string(c.RawIssuer)] {
+			continue
+		}
+		visited[string(c.RawIssuer)] = true
+		for _, issuer := range p.bySubject[string(c.RawIssuer)] {
+			if len(found) == maxCompletionIssuers {
+				break
+			}
+			found = append(found, issuer)
+			queue = append(queue, issuer)
+		}
+	}
+	return found
+}
+
+// Load adds the issuers stored in s to the pool.
+//
+// Issuers which can't be read or parsed are skipped. Loading stops once the
+// pool is full.
+func (p *IssuerPool) Load(ctx context.Context, s IssuerSource) error {
+	start := time.Now()
+	keys, err := s.ListIssuers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list issuers: %v", err)
+	}
+	loaded := 0
+	for _, k := range keys {
+		if p.Len() >= p.limit {
+			klog.Warningf("IssuerPool: pool is full with %d issuers, not loading the remaining stored ones", p.limit)
+			break
+		}
+		der, err := s.ReadIssuer(ctx, k)
+		if err != nil {
+			klog.Warningf("IssuerPool: failed to read issuer %q: %v", k, err)
+			continue
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			klog.Warningf("IssuerPool: failed to parse issuer %q: %v", k, err)
+			continue
+		}
+		p.Add(cert)
+		loaded++
+	}
+	klog.Infof("IssuerPool: loaded %d of %d stored issuers in %s", loaded, len(keys), time.Since(start))
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/transparency-dev/tesseract/internal/testdata"
+)
+
+// mapIssuerSource is an IssuerSource backed by a map.
+type mapIssuerSource map[string][]byte
+
+func (m mapIssuerSource) ListIssuers(_ context.Context) ([][]byte, error) {
+	keys := [][]byte{}
+	for k := range m {
+		keys = append(keys, []byte(k))
+	}
+	keys = append(keys, []byte("missing"))
+	return keys, nil
+}
+
+func (m mapIssuerSource) ReadIssuer(_ context.Context, key []byte) ([]byte, error) {
+	der, ok := m[string(key)]
+	if !ok {
+		return nil, fmt.Errorf("issuer %q: %w", key, os.ErrNotExist)
+	}
+	return der, nil
+}
+
+type failingIssuerSource struct{}
+
+func (failingIssuerSource) ListIssuers(_ context.Context) ([][]byte, error) {
+	return nil, errors.New("boom")
+}
+
+func (failingIssuerSource) ReadIssuer(_ context.Context, _ []byte) ([]byte, error) {
+	return nil, errors.New("boom")
+}
+
+func TestIssuerPoolLoad(t *testing.T) {
+	s := mapIssuerSource{"garbage": []byte("not a certificate")}
+	for _, p := range []string{testdata.FakeIntermediateCertPEM, testdata.FakeCACertPEM} {
+		c := pemToCert(t, p)
+		id := sha256.Sum256(c.Raw)
+		s[hex.EncodeToString(id[:])] = c.Raw
+	}
+
+	p := NewIssuerPool()
+	if err := p.Load(t.Context(), s); err != nil {
+		t.Fatalf("Load()=%v", err)
+	}
+	if got, want := p.Len(), 2; got != want {
+		t.Errorf("Len()=%d, want %d", got, want)
+	}
+	// Issuers are only added once.
+	p.Add(pemToCert(t, testdata.FakeIntermediateCertPEM))
+	if got, want := p.Len(), 2; got != want {
+		t.Errorf("Len()=%d, want %d", got, want)
+	}
+
+	if err := NewIssuerPool().Load(t.Context(), failingIssuerSource{}); err == nil {
+		t.Error("Load()=nil, want error")
+	}
+}
+
+func TestIssuerPoolLimit(t *testing.T) {
+	intermediate := pemToCert(t, testdata.FakeIntermediateCertPEM)
+	root := pemToCert(t, testdata.FakeCACertPEM)
+	s := mapIssuerSource{}
+	for _, c := range []*x509.Certificate{intermediate, root} {
+		id := sha256.Sum256(c.Raw)
+		s[hex.EncodeToString(id[:])] = c.Raw
+	}
+
+	p := NewIssuerPool()
+	p.limit = 1
+	if err := p.Load(t.Context(), s); err != nil {
+		t.Fatalf("Load()=%v", err)
+	}
+	if got, want := p.Len(), 1; got != want {
+		t.Errorf("Len() after Load()=%d, want %d", got, want)
+	}
+	p.Add(intermediate, root)
+	if got, want := p.Len(), 1; got != want {
+		t.Errorf("Len() after Add()=%d, want %d", got, want)
+	}
+}
+
+func TestIssuerPoolCandidates(t *testing.T) {
+	leaf := pemToCert(t, testdata.LeafSignedByFakeIntermediateCertPEM)
+	intermediate := pemToCert(t, testdata.FakeIntermediateCertPEM)
+	root := pemToCert(t, testdata.FakeCACertPEM)
+
+	p := NewIssuerPool()
+	if got := p.candidates([]*x509.Certificate{leaf}); len(got) != 0 {
+		t.Errorf("candidates() on empty pool returned %d certificates, want 0", len(got))
+	}
+
+	p.Add(intermediate, root, pemToCert(t, testdata.TestCertPEM))
+	got := p.candidates([]*x509.Certificate{leaf})
+	if len(got) != 2 || !got[0].Equal(intermediate) || !got[1].Equal(root) {
+		t.Errorf("candidates() returned %d certificates, want the intermediate and the root", len(got))
+	}
+}
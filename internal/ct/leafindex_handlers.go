@@ -0,0 +1,101 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tesseract/storage"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Constants for leaf index entrypoint names, as exposed in statistics/logging.
+const (
+	getLeafIndexName = entrypointName("GetLeafIndex")
+)
+
+// leafIndexPath is the leaf index lookup URI path, relative to the log prefix.
+const leafIndexPath = "/leaf-index"
+
+// leafIndexEntrypoints is a list of leaf index entrypoint names as exposed in statistics/logging.
+var leafIndexEntrypoints = []entrypointName{getLeafIndexName}
+
+// leafIndexResponse is the JSON response to a leaf index lookup.
+type leafIndexResponse struct {
+	LeafIndex int64 `json:"leaf_index"`
+}
+
+// NewLeafIndexPathHandlers returns handlers looking up entries of a log by
+// their RFC 6962 Merkle leaf hash.
+//
+// GET <prefix>/leaf-index?hash=<base64 leaf hash> returns the index of the
+// entry with that leaf hash, if the log has been configured with a leaf index.
+func NewLeafIndexPathHandlers(ctx context.Context, opts *HandlerOptions, log *log) pathHandlers {
+	once.Do(func() { setupMetrics() })
+
+	prefix := logPrefix(log.origin)
+
+	return pathHandlers{
+		prefix + leafIndexPath: appHandler{opts: opts, log: log, handler: getLeafIndex, name: getLeafIndexName, method: http.MethodGet},
+	}
+}
+
+// parseLeafHash parses the base64 encoded leaf hash in the hash parameter of r.
+func parseLeafHash(r *http.Request) ([]byte, error) {
+	hash, err := base64.StdEncoding.DecodeString(r.FormValue("hash"))
+	if err != nil || len(hash) != rfc6962.DefaultHasher.Size() {
+		return nil, fmt.Errorf("invalid hash parameter %q", r.FormValue("hash"))
+	}
+	return hash, nil
+}
+
+// lookupLeafIndex returns the index of the entry with the given leaf hash, and
+// the HTTP status to return if the lookup fails.
+func lookupLeafIndex(ctx context.Context, log *log, hash []byte) (uint64, int, error) {
+	if log.leafIndex == nil {
+		return 0, http.StatusNotImplemented, errors.New("leaf hash lookups are not enabled on this log")
+	}
+	idx, err := log.leafIndex.LeafIndex(ctx, hash)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return 0, http.StatusNotFound, fmt.Errorf("leaf hash %x not found", hash)
+	case errors.Is(err, storage.ErrLeafIndexNotSupported):
+		return 0, http.StatusNotImplemented, errors.New("leaf hash lookups are not enabled on this log")
+	case err != nil:
+		return 0, http.StatusInternalServerError, fmt.Errorf("failed to look up leaf hash %x: %v", hash, err)
+	}
+	return idx, http.StatusOK, nil
+}
+
+func getLeafIndex(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getLeafIndex")
+	defer span.End()
+
+	hash, err := parseLeafHash(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	idx, status, err := lookupLeafIndex(ctx, log, hash)
+	if err != nil {
+		return status, nil, err
+	}
+	return writeJSONResponse(w, leafIndexResponse{LeafIndex: int64(idx)})
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGetLeafIndex(t *testing.T) {
+	log, _ := setupTestLog(t)
+	mux := http.NewServeMux()
+	handlers := NewLeafIndexPathHandlers(t.Context(), &hOpts, log)
+	if got, want := len(handlers), len(leafIndexEntrypoints); got != want {
+		t.Fatalf("len(handlers)=%d; want %d", got, want)
+	}
+	for p, h := range handlers {
+		mux.Handle(p, h)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	knownHash := make([]byte, 32)
+	knownHash[0] = 1
+	hashParam := func(h []byte) url.Values {
+		return url.Values{"hash": {base64.StdEncoding.EncodeToString(h)}}
+	}
+
+	// The test storage does not have a leaf index.
+	if got, want := getJSON(t, server, leafIndexPath, hashParam(knownHash), nil), http.StatusNotImplemented; got != want {
+		t.Errorf("leaf-index without leaf index: got status %d, want %d", got, want)
+	}
+
+	log.leafIndex = mapLeafIndex{string(knownHash): 42}
+	for _, test := range []struct {
+		descr  string
+		params url.Values
+		want   int
+		wantID int64
+	}{
+		{descr: "known", params: hashParam(knownHash), want: http.StatusOK, wantID: 42},
+		{descr: "unknown", params: hashParam(make([]byte, 32)), want: http.StatusNotFound},
+		{descr: "short-hash", params: hashParam(knownHash[:31]), want: http.StatusBadRequest},
+		{descr: "invalid-base64", params: url.Values{"hash": {"!"}}, want: http.StatusBadRequest},
+		{descr: "missing-hash", params: nil, want: http.StatusBadRequest},
+	} {
+		t.Run(test.descr, func(t *testing.T) {
+			var rsp leafIndexResponse
+			if got := getJSON(t, server, leafIndexPath, test.params, &rsp); got != test.want {
+				t.Fatalf("got status %d, want %d", got, test.want)
+			}
+			if got := rsp.LeafIndex; got != test.wantID {
+				t.Errorf("got leaf_index %d, want %d", got, test.wantID)
+			}
+		})
+	}
+}
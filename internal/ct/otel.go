@@ -32,6 +32,8 @@ var (
 	operationKey = attribute.Key("tesseract.operation")
 	originKey    = attribute.Key("tesseract.origin")
 	duplicateKey = attribute.Key("tesseract.duplicate")
+	reasonKey    = attribute.Key("tesseract.reason")
+	weaknessKey  = attribute.Key("tesseract.key_weakness")
 )
 
 func mustCreate[T any](t T, err error) T {
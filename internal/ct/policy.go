@@ -0,0 +1,323 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/cel-go/cel"
+	"github.com/transparency-dev/tesseract/internal/x509util"
+	"gopkg.in/yaml.v3"
+)
+
+// policyCostLimit bounds the cost of evaluating a single policy rule, to
+// protect the log from expensive expressions.
+const policyCostLimit = 100000
+
+// policyFile holds the acceptance rules of a log, and tests for these rules.
+// It is a YAML or JSON file.
+//
+// Rules are CEL expressions which must evaluate to true for a chain to be
+// accepted. They can use the following variables:
+//   - leaf: the submitted certificate.
+//   - chain: the certificates of the verified path, from the leaf to the
+//     root.
+//   - precert: whether the leaf is a precertificate.
+//
+// Certificates are maps, with the fields documented in certificateVars.
+//
+// For example:
+//
+//	rules:
+//	  - name: max-validity
+//	    expr: leaf.not_after - leaf.not_before <= duration("9528h")
+//	    message: certificates must be valid for at most 397 days
+//	  - name: precerts-only
+//	    expr: precert
+//	tests:
+//	  - name: short-lived-precert
+//	    chain_pem_file: chains/precert.pem
+//	  - name: final-cert
+//	    chain_pem_file: chains/cert.pem
+//	    want_violation: precerts-only
+type policyFile struct {
+	Rules []policyFileRule `yaml:"rules"`
+	Tests []policyFileTest `yaml:"tests,omitempty"`
+}
+
+// policyFileRule is a single acceptance rule.
+type policyFileRule struct {
+	// Name identifies the rule in rejection messages.
+	Name string `yaml:"name"`
+	// Expr is a CEL expression evaluating to true for accepted chains.
+	Expr string `yaml:"expr"`
+	// Message optionally explains why chains violating the rule are rejected.
+	Message string `yaml:"message,omitempty"`
+}
+
+// policyFileTest checks the outcome of the rules on a chain when the policy
+// is loaded.
+type policyFileTest struct {
+	// Name identifies the test in errors.
+	Name string `yaml:"name"`
+	// ChainPEMFile is the path to a file holding the PEM encoded certificates
+	// of a verified path, from the leaf to the root. Relative paths are
+	// relative to the directory of the policy file.
+	ChainPEMFile string `yaml:"chain_pem_file"`
+	// WantViolation is the name of the first rule the chain is expected to
+	// violate, or empty if the chain is expected to be accepted.
+	WantViolation string `yaml:"want_violation,omitempty"`
+}
+
+// policyRule is a compiled acceptance rule.
+type policyRule struct {
+	name    string
+	message string
+	prg     cel.Program
+}
+
+// Policy holds expression-based rules that chains must satisfy to be
+// accepted by a log, once a path to a root has been built.
+type Policy struct {
+	rules []policyRule
+}
+
+// LoadPolicy compiles the rules of the policy file at path, and runs the
+// tests it contains against them.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %v", err)
+	}
+	var f policyFile
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty policy file")
+		}
+		return nil, fmt.Errorf("failed to parse policy file: %v", err)
+	}
+
+	p, err := newPolicy(f.Rules)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range f.Tests {
+		if err := p.runTest(filepath.Dir(path), t); err != nil {
+			return nil, fmt.Errorf("tests[%d] (%q): %v", i, t.Name, err)
+		}
+	}
+	return p, nil
+}
+
+// newPolicy compiles rules.
+func newPolicy(rules []policyFileRule) (*Policy, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("no rule in policy")
+	}
+	env, err := cel.NewEnv(
+		cel.Variable("leaf", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("chain", cel.ListType(cel.MapType(cel.StringType, cel.DynType))),
+		cel.Variable("precert", cel.BoolType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %v", err)
+	}
+
+	p := &Policy{}
+	names := make(map[string]bool)
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rules[%d]: missing name", i)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("rules[%d]: duplicate name %q", i, r.Name)
+		}
+		names[r.Name] = true
+		ast, iss := env.Compile(r.Expr)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("rules[%d] (%q): failed to compile expression: %v", i, r.Name, iss.Err())
+		}
+		if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+			return nil, fmt.Errorf("rules[%d] (%q): expression evaluates to %v, want bool", i, r.Name, t)
+		}
+		prg, err := env.Program(ast, cel.CostLimit(policyCostLimit))
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d] (%q): failed to create program: %v", i, r.Name, err)
+		}
+		p.rules = append(p.rules, policyRule{name: r.Name, message: r.Message, prg: prg})
+	}
+	return p, nil
+}
+
+// runTest checks that the chain of t violates the rule it expects.
+func (p *Policy) runTest(dir string, t policyFileTest) error {
+	path := t.ChainPEMFile
+	if path == "" {
+		return errors.New("missing chain_pem_file")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	pemData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read chain: %v", err)
+	}
+	pool := x509util.NewPEMCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return errors.New("failed to parse chain")
+	}
+	chain := pool.RawCertificates()
+	isPrecert, err := isPrecertificate(chain[0])
+	if err != nil {
+		return fmt.Errorf("precert test failed: %v", err)
+	}
+
+	violated := ""
+	if err := p.Evaluate(chain, isPrecert); err != nil {
+		var vErr *policyViolation
+		if !errors.As(err, &vErr) {
+			return err
+		}
+		violated = vErr.rule
+	}
+	if violated != t.WantViolation {
+		return fmt.Errorf("chain violates rule %q, want %q", violated, t.WantViolation)
+	}
+	return nil
+}
+
+// policyViolation is returned when a chain violates a rule.
+type policyViolation struct {
+	rule string
+	msg  string
+}
+
+func (e *policyViolation) Error() string {
+	return fmt.Sprintf("chain violates policy rule %q: %s", e.rule, e.msg)
+}
+
+// Evaluate checks that chain, a verified path from a leaf to a root,
+// satisfies all the rules of the policy. Chains violating a rule, or for
+// which a rule can't be evaluated, return a *ValidationError.
+//
+// A nil policy accepts all chains.
+func (p *Policy) Evaluate(chain []*x509.Certificate, isPrecert bool) error {
+	if p == nil {
+		return nil
+	}
+	vars := map[string]any{
+		"leaf":    certificateVars(chain[0]),
+		"precert": isPrecert,
+	}
+	chainVars := make([]map[string]any, 0, len(chain))
+	for _, c := range chain {
+		chainVars = append(chainVars, certificateVars(c))
+	}
+	vars["chain"] = chainVars
+
+	for _, r := range p.rules {
+		out, _, err := r.prg.Eval(vars)
+		if err != nil {
+			return &ValidationError{Reason: ReasonPolicyViolation, Err: &policyViolation{rule: r.name, msg: fmt.Sprintf("failed to evaluate: %v", err)}}
+		}
+		if ok, isBool := out.Value().(bool); !isBool || !ok {
+			msg := r.message
+			if !isBool {
+				msg = fmt.Sprintf("expression evaluated to %v, want bool", out)
+			} else if msg == "" {
+				msg = "expression evaluated to false"
+			}
+			return &ValidationError{Reason: ReasonPolicyViolation, Err: &policyViolation{rule: r.name, msg: msg}}
+		}
+	}
+	return nil
+}
+
+// certificateVars returns the fields of cert which policy rules can use:
+//   - subject, issuer: the distinguished names, as strings.
+//   - serial_number: the hex encoded serial number.
+//   - not_before, not_after: the validity period, as timestamps.
+//   - dns_names, email_addresses, ip_addresses, uris: the SANs, as lists of
+//     strings.
+//   - key_algorithm: "RSA", "ECDSA" or "Ed25519".
+//   - key_size: the RSA modulus or ECDSA curve size in bits, 256 for
+//     Ed25519 keys.
+//   - signature_algorithm: e.g. "SHA256-RSA" or "ECDSA-SHA384".
+//   - is_ca: whether the certificate is a CA.
+//   - extensions: the OIDs of the extensions, in dotted string form.
+//   - sha256: the hex encoded SHA-256 fingerprint of the certificate.
+func certificateVars(cert *x509.Certificate) map[string]any {
+	var ips []string
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	var uris []string
+	for _, u := range cert.URIs {
+		uris = append(uris, u.String())
+	}
+	var exts []string
+	for _, e := range cert.Extensions {
+		exts = append(exts, e.Id.String())
+	}
+	keySize := 0
+	switch k := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		keySize = k.N.BitLen()
+	case *ecdsa.PublicKey:
+		keySize = k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		keySize = 256
+	}
+	fp := sha256.Sum256(cert.Raw)
+	return map[string]any{
+		"subject":             cert.Subject.String(),
+		"issuer":              cert.Issuer.String(),
+		"serial_number":       cert.SerialNumber.Text(16),
+		"not_before":          cert.NotBefore,
+		"not_after":           cert.NotAfter,
+		"dns_names":           nonNil(cert.DNSNames),
+		"email_addresses":     nonNil(cert.EmailAddresses),
+		"ip_addresses":        nonNil(ips),
+		"uris":                nonNil(uris),
+		"key_algorithm":       cert.PublicKeyAlgorithm.String(),
+		"key_size":            keySize,
+		"signature_algorithm": cert.SignatureAlgorithm.String(),
+		"is_ca":               cert.IsCA,
+		"extensions":          nonNil(exts),
+		"sha256":              hex.EncodeToString(fp[:]),
+	}
+}
+
+// nonNil returns s, or an empty slice if s is nil, so that rules can use
+// list functions on absent fields.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,300 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/testdata"
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/x509util"
+)
+
+// writePolicyFile writes a policy file, and the chains it references, to a
+// temporary directory, and returns its path.
+func writePolicyFile(t *testing.T, policy string, chains map[string][]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, pems := range chains {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(strings.Join(pems, "\n")), 0o644); err != nil {
+			t.Fatalf("WriteFile(%q): %v", p, err)
+		}
+	}
+	p := filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(p, []byte(policy), 0o644); err != nil {
+		t.Fatalf("WriteFile(%q): %v", p, err)
+	}
+	return p
+}
+
+func TestLoadPolicy(t *testing.T) {
+	chains := map[string][]string{
+		"cert.pem":    {testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM},
+		"precert.pem": {testdata.PrecertPEMValid, testdata.CACertPEM},
+	}
+	for _, test := range []struct {
+		desc    string
+		policy  string
+		wantErr string
+	}{
+		{
+			desc: "valid",
+			policy: `
+rules:
+  - name: precerts-only
+    expr: precert
+tests:
+  - name: precert
+    chain_pem_file: precert.pem
+  - name: cert
+    chain_pem_file: cert.pem
+    want_violation: precerts-only
+`,
+		},
+		{
+			desc:    "empty",
+			policy:  "",
+			wantErr: "empty policy file",
+		},
+		{
+			desc:    "unknown-field",
+			policy:  "rulez: []",
+			wantErr: "failed to parse policy file",
+		},
+		{
+			desc:    "no-rule",
+			policy:  "rules: []",
+			wantErr: "no rule in policy",
+		},
+		{
+			desc: "missing-name",
+			policy: `
+rules:
+  - expr: precert
+`,
+			wantErr: "missing name",
+		},
+		{
+			desc: "duplicate-name",
+			policy: `
+rules:
+  - name: rule
+    expr: precert
+  - name: rule
+    expr: '!precert'
+`,
+			wantErr: `duplicate name "rule"`,
+		},
+		{
+			desc: "invalid-expression",
+			policy: `
+rules:
+  - name: rule
+    expr: leaf.
+`,
+			wantErr: "failed to compile expression",
+		},
+		{
+			desc: "unknown-variable",
+			policy: `
+rules:
+  - name: rule
+    expr: root.is_ca
+`,
+			wantErr: "failed to compile expression",
+		},
+		{
+			desc: "not-bool",
+			policy: `
+rules:
+  - name: rule
+    expr: size(chain)
+`,
+			wantErr: "want bool",
+		},
+		{
+			desc: "failing-test",
+			policy: `
+rules:
+  - name: precerts-only
+    expr: precert
+tests:
+  - name: cert
+    chain_pem_file: cert.pem
+`,
+			wantErr: `chain violates rule "precerts-only", want ""`,
+		},
+		{
+			desc: "missing-chain",
+			policy: `
+rules:
+  - name: precerts-only
+    expr: precert
+tests:
+  - name: cert
+    chain_pem_file: missing.pem
+`,
+			wantErr: "failed to read chain",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := LoadPolicy(writePolicyFile(t, test.policy, chains))
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadPolicy()=%v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("LoadPolicy()=%v, want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	certChain := []*x509.Certificate{
+		pemToCert(t, testdata.CertFromIntermediate),
+		pemToCert(t, testdata.IntermediateFromRoot),
+		pemToCert(t, testdata.CACertPEM),
+	}
+	intermediateFP := sha256.Sum256(certChain[1].Raw)
+
+	for _, test := range []struct {
+		desc          string
+		expr          string
+		wantViolation bool
+	}{
+		{
+			desc: "max-validity",
+			expr: `leaf.not_after - leaf.not_before <= duration("9528h")`,
+		},
+		{
+			desc:          "max-validity-exceeded",
+			expr:          `leaf.not_after - leaf.not_before <= duration("2160h")`,
+			wantViolation: true,
+		},
+		{
+			desc: "required-san",
+			expr: `leaf.dns_names.exists(n, n.endsWith(".transparency.dev"))`,
+		},
+		{
+			desc:          "missing-san",
+			expr:          `size(leaf.email_addresses) > 0`,
+			wantViolation: true,
+		},
+		{
+			desc: "key-types",
+			expr: `leaf.key_algorithm == "ECDSA" && leaf.key_size >= 256`,
+		},
+		{
+			desc:          "rsa-only",
+			expr:          `leaf.key_algorithm == "RSA"`,
+			wantViolation: true,
+		},
+		{
+			desc: "issuer-allow-list",
+			expr: `chain[1].sha256 in ["` + hex.EncodeToString(intermediateFP[:]) + `"]`,
+		},
+		{
+			desc:          "issuer-deny-list",
+			expr:          `!chain.exists(c, c.issuer.contains("Intermediate"))`,
+			wantViolation: true,
+		},
+		{
+			desc:          "precerts-only",
+			expr:          `precert`,
+			wantViolation: true,
+		},
+		{
+			desc: "ca-chain",
+			expr: `!leaf.is_ca && chain[1].is_ca && size(chain) == 3`,
+		},
+		{
+			desc:          "evaluation-error",
+			expr:          `chain[3].is_ca`,
+			wantViolation: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			p, err := newPolicy([]policyFileRule{{Name: test.desc, Expr: test.expr}})
+			if err != nil {
+				t.Fatalf("newPolicy()=%v", err)
+			}
+			err = p.Evaluate(certChain, false)
+			if gotViolation := err != nil; gotViolation != test.wantViolation {
+				t.Fatalf("Evaluate()=%v, want violation %t", err, test.wantViolation)
+			}
+			if err == nil {
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) || vErr.Reason != ReasonPolicyViolation {
+				t.Errorf("Evaluate()=%v, want reason %q", err, ReasonPolicyViolation)
+			}
+		})
+	}
+
+	var p *Policy
+	if err := p.Evaluate(certChain, true); err != nil {
+		t.Errorf("nil Policy.Evaluate()=%v, want nil", err)
+	}
+}
+
+func TestValidateWithPolicy(t *testing.T) {
+	roots := x509util.NewPEMCertPool()
+	if !roots.AppendCertsFromPEM([]byte(testdata.CACertPEM)) {
+		t.Fatal("failed to load root")
+	}
+	p, err := newPolicy([]policyFileRule{{Name: "precerts-only", Expr: "precert", Message: "only precertificates are accepted"}})
+	if err != nil {
+		t.Fatalf("newPolicy()=%v", err)
+	}
+	cv := chainValidator{
+		trustedRoots: mustNewRoots(t, roots),
+		currentTime:  time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		policy:       p,
+	}
+
+	precert := rfc6962.AddChainRequest{Chain: pemsToDERChain(t, []string{testdata.PrecertPEMValid, testdata.CACertPEM})}
+	if _, err := cv.Validate(precert, true); err != nil {
+		t.Errorf("Validate(precert)=%v, want nil", err)
+	}
+
+	cert := rfc6962.AddChainRequest{Chain: pemsToDERChain(t, []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot})}
+	_, err = cv.Validate(cert, false)
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Reason != ReasonPolicyViolation {
+		t.Fatalf("Validate(cert)=%v, want reason %q", err, ReasonPolicyViolation)
+	}
+	if !strings.Contains(err.Error(), "only precertificates are accepted") {
+		t.Errorf("Validate(cert)=%v, want the rule message", err)
+	}
+
+	r := cv.Check(cert.Chain)
+	last := r.Checks[len(r.Checks)-1]
+	if last.Name != "policy" || last.Passed || last.Reason != ReasonPolicyViolation {
+		t.Errorf("Check() last result=%+v, want failed policy check", last)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/transparency-dev/tessera/api/layout"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Cache-Control header
+	cacheControlHeader string = "Cache-Control"
+	// Full tiles and entry bundles never change once written.
+	cacheControlImmutable string = "max-age=604800,immutable"
+	// Checkpoints and partial tiles are superseded as the log grows.
+	cacheControlShort string = "max-age=5"
+	// MIME content type for checkpoints
+	contentTypeCheckpoint string = "text/plain; charset=utf-8"
+	// MIME content type for tiles and entry bundles
+	contentTypeTile string = "application/octet-stream"
+	// MIME content type for issuer certificates
+	contentTypeIssuer string = "application/pkix-cert"
+)
+
+// Constants for read path entrypoint names, as exposed in statistics/logging.
+const (
+	getCheckpointName  = entrypointName("GetCheckpoint")
+	getTileName        = entrypointName("GetTile")
+	getEntryBundleName = entrypointName("GetEntryBundle")
+	getIssuerName      = entrypointName("GetIssuer")
+)
+
+// Read path URI paths, relative to the log prefix, as defined in https://c2sp.org/static-ct-api.
+const (
+	checkpointPath  = "/checkpoint"
+	tilePath        = "/tile/"
+	entryBundlePath = "/tile/data/"
+	issuerPath      = "/issuer/"
+)
+
+// readEntrypoints is a list of read path entrypoint names as exposed in statistics/logging.
+var readEntrypoints = []entrypointName{getCheckpointName, getTileName, getEntryBundleName, getIssuerName}
+
+// ReadStorage provides functions to read static-ct-api objects from a log.
+type ReadStorage interface {
+	// ReadCheckpoint returns the latest checkpoint published by the log.
+	ReadCheckpoint(ctx context.Context) ([]byte, error)
+	// ReadTile returns the tile at the given level and index, with partial size p.
+	ReadTile(ctx context.Context, level, index uint64, p uint8) ([]byte, error)
+	// ReadEntryBundle returns the entry bundle at the given index, with partial size p.
+	ReadEntryBundle(ctx context.Context, index uint64, p uint8) ([]byte, error)
+	// ReadIssuer returns the issuer certificate stored under its hex encoded sha256.
+	ReadIssuer(ctx context.Context, key []byte) ([]byte, error)
+}
+
+// NewReadPathHandlers returns handlers serving the https://c2sp.org/static-ct-api
+// read path of a log: checkpoints, tiles, entry bundles and issuers.
+//
+// Tile, entry bundle and issuer handlers are registered on subtree paths.
+func NewReadPathHandlers(ctx context.Context, opts *HandlerOptions, log *log) pathHandlers {
+	once.Do(func() { setupMetrics() })
+	knownLogs.Record(ctx, 1, metric.WithAttributes(originKey.String(log.origin)))
+
+	prefix := logPrefix(log.origin)
+
+	return pathHandlers{
+		prefix + checkpointPath:  appHandler{opts: opts, log: log, handler: getCheckpoint, name: getCheckpointName, method: http.MethodGet},
+		prefix + tilePath:        appHandler{opts: opts, log: log, handler: getTile, name: getTileName, method: http.MethodGet},
+		prefix + entryBundlePath: appHandler{opts: opts, log: log, handler: getEntryBundle, name: getEntryBundleName, method: http.MethodGet},
+		prefix + issuerPath:      appHandler{opts: opts, log: log, handler: getIssuer, name: getIssuerName, method: http.MethodGet},
+	}
+}
+
+// readStatus maps errors returned by ReadStorage to HTTP status codes.
+func readStatus(err error) int {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeReadResponse writes a read path object with its headers.
+func writeReadResponse(w http.ResponseWriter, contentType, cacheControl string, data []byte) (int, []attribute.KeyValue, error) {
+	w.Header().Set(contentTypeHeader, contentType)
+	w.Header().Set(cacheControlHeader, cacheControl)
+	if _, err := w.Write(data); err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to write response: %v", err)
+	}
+	return http.StatusOK, nil, nil
+}
+
+// tileCacheControl returns the Cache-Control value for a tile or entry bundle
+// with partial size p.
+func tileCacheControl(p uint8) string {
+	if p > 0 {
+		return cacheControlShort
+	}
+	return cacheControlImmutable
+}
+
+func getCheckpoint(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, _ *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getCheckpoint")
+	defer span.End()
+
+	cp, err := log.reader.ReadCheckpoint(ctx)
+	if err != nil {
+		return readStatus(err), nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	return writeReadResponse(w, contentTypeCheckpoint, cacheControlShort, cp)
+}
+
+func getTile(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getTile")
+	defer span.End()
+
+	rest, ok := strings.CutPrefix(r.URL.Path, logPrefix(log.origin)+tilePath)
+	if !ok {
+		return http.StatusNotFound, nil, fmt.Errorf("unknown path: %q", r.URL.Path)
+	}
+	level, index, ok := strings.Cut(rest, "/")
+	if !ok {
+		return http.StatusBadRequest, nil, fmt.Errorf("malformed tile path: %q", r.URL.Path)
+	}
+	l, i, p, err := layout.ParseTileLevelIndexPartial(level, index)
+	if err != nil {
+		return http.StatusBadRequest, nil, fmt.Errorf("malformed tile path %q: %v", r.URL.Path, err)
+	}
+
+	klog.V(3).Infof("%s: %s => ReadTile(%d, %d, %d)", log.origin, getTileName, l, i, p)
+	tile, err := log.reader.ReadTile(ctx, l, i, p)
+	if err != nil {
+		return readStatus(err), nil, fmt.Errorf("failed to read tile %s: %v", layout.TilePath(l, i, p), err)
+	}
+	return writeReadResponse(w, contentTypeTile, tileCacheControl(p), tile)
+}
+
+func getEntryBundle(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getEntryBundle")
+	defer span.End()
+
+	index, ok := strings.CutPrefix(r.URL.Path, logPrefix(log.origin)+entryBundlePath)
+	if !ok {
+		return http.StatusNotFound, nil, fmt.Errorf("unknown path: %q", r.URL.Path)
+	}
+	i, p, err := layout.ParseTileIndexPartial(index)
+	if err != nil {
+		return http.StatusBadRequest, nil, fmt.Errorf("malformed entry bundle path %q: %v", r.URL.Path, err)
+	}
+
+	klog.V(3).Infof("%s: %s => ReadEntryBundle(%d, %d)", log.origin, getEntryBundleName, i, p)
+	bundle, err := log.reader.ReadEntryBundle(ctx, i, p)
+	if err != nil {
+		return readStatus(err), nil, fmt.Errorf("failed to read entry bundle %d.%d: %v", i, p, err)
+	}
+	return writeReadResponse(w, contentTypeTile, tileCacheControl(p), bundle)
+}
+
+func getIssuer(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getIssuer")
+	defer span.End()
+
+	key, ok := strings.CutPrefix(r.URL.Path, logPrefix(log.origin)+issuerPath)
+	if !ok {
+		return http.StatusNotFound, nil, fmt.Errorf("unknown path: %q", r.URL.Path)
+	}
+	// Issuers are stored under their lowercase hex encoded sha256.
+	if b, err := hex.DecodeString(key); err != nil || len(b) != 32 || strings.ToLower(key) != key {
+		return http.StatusBadRequest, nil, fmt.Errorf("malformed issuer fingerprint: %q", key)
+	}
+
+	issuer, err := log.reader.ReadIssuer(ctx, []byte(key))
+	if err != nil {
+		return readStatus(err), nil, fmt.Errorf("failed to read issuer %q: %v", key, err)
+	}
+	return writeReadResponse(w, contentTypeIssuer, cacheControlImmutable, issuer)
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/testdata"
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+)
+
+// setupReadTestServer creates a test TesseraCT server with write and read
+// endpoints, and adds a single chain to it.
+//
+// It returns the server and the path to the storage directory.
+func setupReadTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	log, dir := setupTestLog(t)
+
+	mux := http.NewServeMux()
+	for p, h := range NewPathHandlers(t.Context(), &hOpts, log) {
+		mux.Handle(p, h)
+	}
+	for p, h := range NewReadPathHandlers(t.Context(), &hOpts, log) {
+		mux.Handle(p, h)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	pool := loadCertsIntoPoolOrDie(t, []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM})
+	resp, err := http.Post(server.URL+prefix+rfc6962.AddChainPath, "application/json", createJSONChain(t, *pool))
+	if err != nil {
+		t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", rfc6962.AddChainPath, err)
+	}
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", rfc6962.AddChainPath, got, want)
+	}
+
+	// Wait for a checkpoint committing to the chain to be published, so that
+	// it does not change while tests are running.
+	waitForCheckpoint(t, dir, 1)
+
+	return server, dir
+}
+
+// waitForCheckpoint waits until the log stored in dir publishes a checkpoint
+// of the given size.
+func waitForCheckpoint(t *testing.T, dir string, size uint64) {
+	t.Helper()
+	cpPath := path.Join(dir, logDir, "checkpoint")
+	for {
+		if cp, err := os.ReadFile(cpPath); err == nil && strings.HasPrefix(string(cp), fmt.Sprintf("%s\n%d\n", origin, size)) {
+			return
+		}
+		select {
+		case <-t.Context().Done():
+			t.Fatalf("checkpoint of size %d was not published: %v", size, t.Context().Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func TestNewReadPathHandlers(t *testing.T) {
+	log, _ := setupTestLog(t)
+	handlers := NewReadPathHandlers(t.Context(), &HandlerOptions{}, log)
+	if got, want := len(handlers), len(readEntrypoints); got != want {
+		t.Fatalf("len(handlers)=%d; want %d", got, want)
+	}
+	for _, p := range []string{prefix + checkpointPath, prefix + tilePath, prefix + entryBundlePath, prefix + issuerPath} {
+		if _, ok := handlers[p]; !ok {
+			t.Errorf("%q path not registered", p)
+		}
+	}
+}
+
+func TestReadPathHandlers(t *testing.T) {
+	server, dir := setupReadTestServer(t)
+
+	issuer := loadCertsIntoPoolOrDie(t, []string{testdata.IntermediateFromRoot}).RawCertificates()[0]
+	issuerKey := sha256.Sum256(issuer.Raw)
+	unknownKey := sha256.Sum256([]byte("unknown"))
+
+	for _, test := range []struct {
+		descr            string
+		path             string
+		method           string
+		want             int
+		wantFile         string
+		wantBody         []byte
+		wantContentType  string
+		wantCacheControl string
+	}{
+		{
+			descr:            "checkpoint",
+			path:             "/checkpoint",
+			want:             http.StatusOK,
+			wantFile:         "checkpoint",
+			wantContentType:  contentTypeCheckpoint,
+			wantCacheControl: cacheControlShort,
+		},
+		{
+			descr:            "partial-tile",
+			path:             "/tile/0/000.p/1",
+			want:             http.StatusOK,
+			wantFile:         "tile/0/000.p/1",
+			wantContentType:  contentTypeTile,
+			wantCacheControl: cacheControlShort,
+		},
+		{
+			descr:            "partial-entry-bundle",
+			path:             "/tile/data/000.p/1",
+			want:             http.StatusOK,
+			wantFile:         "tile/data/000.p/1",
+			wantContentType:  contentTypeTile,
+			wantCacheControl: cacheControlShort,
+		},
+		{
+			descr:            "issuer",
+			path:             "/issuer/" + hex.EncodeToString(issuerKey[:]),
+			want:             http.StatusOK,
+			wantBody:         issuer.Raw,
+			wantContentType:  contentTypeIssuer,
+			wantCacheControl: cacheControlImmutable,
+		},
+		{
+			descr: "missing-full-tile",
+			path:  "/tile/0/000",
+			want:  http.StatusNotFound,
+		},
+		{
+			descr: "missing-full-entry-bundle",
+			path:  "/tile/data/000",
+			want:  http.StatusNotFound,
+		},
+		{
+			descr: "missing-issuer",
+			path:  "/issuer/" + hex.EncodeToString(unknownKey[:]),
+			want:  http.StatusNotFound,
+		},
+		{
+			descr: "malformed-tile-level",
+			path:  "/tile/64/000",
+			want:  http.StatusBadRequest,
+		},
+		{
+			descr: "malformed-tile-index",
+			path:  "/tile/0/x000/../checkpoint",
+			want:  http.StatusBadRequest,
+		},
+		{
+			descr: "malformed-tile-path",
+			path:  "/tile/0",
+			want:  http.StatusBadRequest,
+		},
+		{
+			descr: "malformed-entry-bundle-width",
+			path:  "/tile/data/000.p/256",
+			want:  http.StatusBadRequest,
+		},
+		{
+			descr: "malformed-issuer-length",
+			path:  "/issuer/abcd",
+			want:  http.StatusBadRequest,
+		},
+		{
+			descr: "malformed-issuer-uppercase",
+			path:  "/issuer/" + strings.ToUpper(hex.EncodeToString(issuerKey[:])),
+			want:  http.StatusBadRequest,
+		},
+		{
+			descr:  "post-checkpoint",
+			path:   "/checkpoint",
+			method: http.MethodPost,
+			want:   http.StatusMethodNotAllowed,
+		},
+	} {
+		t.Run(test.descr, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequestWithContext(t.Context(), method, server.URL+prefix+test.path, nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest(): %v", err)
+			}
+			// Don't let the client clean up paths, the server needs to handle them.
+			req.URL.Opaque = prefix + test.path
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", method, test.path, err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if got, want := resp.StatusCode, test.want; got != want {
+				t.Fatalf("%s %s: got status %d, want %d", method, test.path, got, want)
+			}
+			if test.want != http.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("io.ReadAll(): %v", err)
+			}
+			wantBody := test.wantBody
+			if test.wantFile != "" {
+				wantBody, err = os.ReadFile(path.Join(dir, logDir, test.wantFile))
+				if err != nil {
+					t.Fatalf("os.ReadFile(%q): %v", test.wantFile, err)
+				}
+			}
+			if !bytes.Equal(body, wantBody) {
+				t.Errorf("%s %s: got body %x, want %x", method, test.path, body, wantBody)
+			}
+			if got, want := resp.Header.Get(contentTypeHeader), test.wantContentType; got != want {
+				t.Errorf("%s %s: got %s %q, want %q", method, test.path, contentTypeHeader, got, want)
+			}
+			if got, want := resp.Header.Get(cacheControlHeader), test.wantCacheControl; got != want {
+				t.Errorf("%s %s: got %s %q, want %q", method, test.path, cacheControlHeader, got, want)
+			}
+		})
+	}
+}
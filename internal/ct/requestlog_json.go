@@ -0,0 +1,161 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// JSONRequestLogOptions configures a JSONRequestLog.
+type JSONRequestLogOptions struct {
+	// SampleRate is the fraction of requests which are logged, between 0
+	// and 1.
+	SampleRate float64
+	// RedactNames omits the subject and issuer names of submitted
+	// certificates from records.
+	RedactNames bool
+}
+
+// JSONRequestLog is a RequestLog writing a record per request to an
+// io.Writer, as newline-delimited JSON.
+type JSONRequestLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	opts JSONRequestLogOptions
+}
+
+// NewJSONRequestLog returns a JSONRequestLog writing records to w.
+func NewJSONRequestLog(w io.Writer, opts JSONRequestLogOptions) *JSONRequestLog {
+	return &JSONRequestLog{w: w, opts: opts}
+}
+
+// requestRecord is the JSON record of a request.
+type requestRecord struct {
+	// Time is the time at which the request started being processed.
+	Time   time.Time `json:"time"`
+	Origin string    `json:"origin,omitempty"`
+	// Chain holds the hex encoded SHA-256 fingerprints of the submitted
+	// certificates, in the order of the request.
+	Chain []string `json:"chain,omitempty"`
+	// Subject and Issuer are the names of the submitted leaf certificate.
+	Subject string `json:"subject,omitempty"`
+	Issuer  string `json:"issuer,omitempty"`
+	// LeafIndex and SCTTimestamp are the index and timestamp assigned to the
+	// submitted entry, which Duplicate is true for if it had already been
+	// submitted.
+	LeafIndex    *uint64 `json:"leaf_index,omitempty"`
+	SCTTimestamp *uint64 `json:"sct_timestamp,omitempty"`
+	Duplicate    bool    `json:"duplicate,omitempty"`
+	// SCTIssued is true if an SCT was returned to the client.
+	SCTIssued bool    `json:"sct_issued,omitempty"`
+	Status    int     `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+
+	// hasLeaf is set once the leaf certificate has been recorded.
+	hasLeaf bool
+}
+
+type requestRecordKey struct{}
+
+// record returns the record of the request ctx is for, or nil if the request
+// isn't logged.
+func record(ctx context.Context) *requestRecord {
+	r, _ := ctx.Value(requestRecordKey{}).(*requestRecord)
+	return r
+}
+
+// Start starts a record for the request, if it is sampled.
+func (l *JSONRequestLog) Start(ctx context.Context) context.Context {
+	if l.opts.SampleRate < 1 && rand.Float64() >= l.opts.SampleRate {
+		return ctx
+	}
+	return context.WithValue(ctx, requestRecordKey{}, &requestRecord{Time: time.Now()})
+}
+
+// Origin records the origin of the CT log that this request is for.
+func (l *JSONRequestLog) Origin(ctx context.Context, o string) {
+	if r := record(ctx); r != nil {
+		r.Origin = o
+	}
+}
+
+// AddDERToChain records the fingerprint of a submitted certificate.
+func (l *JSONRequestLog) AddDERToChain(ctx context.Context, d []byte) {
+	if r := record(ctx); r != nil {
+		fp := sha256.Sum256(d)
+		r.Chain = append(r.Chain, hex.EncodeToString(fp[:]))
+	}
+}
+
+// AddCertToChain records the names of the submitted leaf certificate, unless
+// they are redacted.
+func (l *JSONRequestLog) AddCertToChain(ctx context.Context, cert *x509.Certificate) {
+	r := record(ctx)
+	if r == nil || r.hasLeaf {
+		return
+	}
+	r.hasLeaf = true
+	if !l.opts.RedactNames {
+		r.Subject = cert.Subject.String()
+		r.Issuer = cert.Issuer.String()
+	}
+}
+
+// Entry records the index and timestamp assigned to the submitted entry.
+func (l *JSONRequestLog) Entry(ctx context.Context, index, timestamp uint64, duplicate bool) {
+	if r := record(ctx); r != nil {
+		r.LeafIndex = &index
+		r.SCTTimestamp = &timestamp
+		r.Duplicate = duplicate
+	}
+}
+
+// IssueSCT records that an SCT was issued.
+func (l *JSONRequestLog) IssueSCT(ctx context.Context, _ []byte) {
+	if r := record(ctx); r != nil {
+		r.SCTIssued = true
+	}
+}
+
+// Status records the response HTTP status code, and writes the record.
+func (l *JSONRequestLog) Status(ctx context.Context, s int) {
+	r := record(ctx)
+	if r == nil {
+		return
+	}
+	r.Status = s
+	r.LatencyMS = float64(time.Since(r.Time).Microseconds()) / 1000
+	b, err := json.Marshal(r)
+	if err != nil {
+		klog.Warningf("Failed to marshal request record: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(append(b, '\n')); err != nil {
+		klog.Warningf("Failed to write request record: %v", err)
+	}
+}
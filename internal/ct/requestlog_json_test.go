@@ -0,0 +1,120 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestJSONRequestLog(t *testing.T) {
+	leaf := &x509.Certificate{
+		Raw:     []byte("leaf"),
+		Subject: pkix.Name{CommonName: "leaf.example.com"},
+		Issuer:  pkix.Name{CommonName: "Intermediate CA"},
+	}
+	intermediate := &x509.Certificate{
+		Raw:     []byte("intermediate"),
+		Subject: pkix.Name{CommonName: "Intermediate CA"},
+		Issuer:  pkix.Name{CommonName: "Root CA"},
+	}
+	fp := func(d []byte) string {
+		h := sha256.Sum256(d)
+		return hex.EncodeToString(h[:])
+	}
+	index, timestamp := uint64(42), uint64(1234)
+
+	for _, tc := range []struct {
+		desc string
+		opts JSONRequestLogOptions
+		want []requestRecord
+	}{
+		{
+			desc: "all",
+			opts: JSONRequestLogOptions{SampleRate: 1},
+			want: []requestRecord{{
+				Origin:       "example.com/log",
+				Chain:        []string{fp(leaf.Raw), fp(intermediate.Raw)},
+				Subject:      "CN=leaf.example.com",
+				Issuer:       "CN=Intermediate CA",
+				LeafIndex:    &index,
+				SCTTimestamp: &timestamp,
+				Duplicate:    true,
+				SCTIssued:    true,
+				Status:       http.StatusOK,
+			}},
+		},
+		{
+			desc: "redacted",
+			opts: JSONRequestLogOptions{SampleRate: 1, RedactNames: true},
+			want: []requestRecord{{
+				Origin:       "example.com/log",
+				Chain:        []string{fp(leaf.Raw), fp(intermediate.Raw)},
+				LeafIndex:    &index,
+				SCTTimestamp: &timestamp,
+				Duplicate:    true,
+				SCTIssued:    true,
+				Status:       http.StatusOK,
+			}},
+		},
+		{
+			desc: "not-sampled",
+			opts: JSONRequestLogOptions{SampleRate: 0},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewJSONRequestLog(buf, tc.opts)
+
+			ctx := l.Start(context.Background())
+			l.Origin(ctx, "example.com/log")
+			for _, c := range []*x509.Certificate{leaf, intermediate} {
+				l.AddDERToChain(ctx, c.Raw)
+			}
+			for _, c := range []*x509.Certificate{leaf, intermediate} {
+				l.AddCertToChain(ctx, c)
+			}
+			l.Entry(ctx, index, timestamp, true)
+			l.IssueSCT(ctx, []byte("sct"))
+			l.Status(ctx, http.StatusOK)
+
+			got := []requestRecord{}
+			dec := json.NewDecoder(buf)
+			for dec.More() {
+				r := requestRecord{}
+				if err := dec.Decode(&r); err != nil {
+					t.Fatalf("Failed to decode record: %v", err)
+				}
+				if r.Time.IsZero() {
+					t.Errorf("Record has no time")
+				}
+				got = append(got, r)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty(), cmpopts.IgnoreFields(requestRecord{}, "Time", "LatencyMS"), cmpopts.IgnoreUnexported(requestRecord{})); diff != "" {
+				t.Errorf("Records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
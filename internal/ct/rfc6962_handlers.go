@@ -0,0 +1,466 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	tfl "github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/ctonly"
+	"github.com/transparency-dev/tesseract/internal/client"
+	ctrfc6962 "github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/types/staticct"
+	"github.com/transparency-dev/tesseract/internal/types/tls"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/mod/sumdb/note"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxGetEntries is the maximum number of entries returned by a single
+	// get-entries request.
+	maxGetEntries = layout.EntryBundleWidth
+)
+
+// Constants for RFC 6962 read entrypoint names, as exposed in statistics/logging.
+const (
+	getSTHName            = entrypointName("GetSTH")
+	getSTHConsistencyName = entrypointName("GetSTHConsistency")
+	getProofByHashName    = entrypointName("GetProofByHash")
+	getEntriesName        = entrypointName("GetEntries")
+	getEntryAndProofName  = entrypointName("GetEntryAndProof")
+)
+
+// rfc6962Entrypoints is a list of RFC 6962 read entrypoint names as exposed in statistics/logging.
+var rfc6962Entrypoints = []entrypointName{getSTHName, getSTHConsistencyName, getProofByHashName, getEntriesName, getEntryAndProofName}
+
+// LeafIndexLookup maps Merkle leaf hashes to their index in a log.
+type LeafIndexLookup interface {
+	// LeafIndex returns the index of the leaf with the given Merkle leaf hash,
+	// or an error wrapping os.ErrNotExist if there is no such leaf.
+	LeafIndex(ctx context.Context, leafHash []byte) (uint64, error)
+}
+
+// NewRFC6962PathHandlers returns handlers serving the RFC 6962 read API of a
+// log: get-sth, get-sth-consistency, get-proof-by-hash, get-entries and
+// get-entry-and-proof.
+//
+// Responses are synthesized from the log's https://c2sp.org/static-ct-api
+// checkpoints, tiles and entry bundles.
+func NewRFC6962PathHandlers(ctx context.Context, opts *HandlerOptions, log *log) pathHandlers {
+	once.Do(func() { setupMetrics() })
+
+	prefix := logPrefix(log.origin)
+
+	return pathHandlers{
+		prefix + ctrfc6962.GetSTHPath:            appHandler{opts: opts, log: log, handler: getSTH, name: getSTHName, method: http.MethodGet},
+		prefix + ctrfc6962.GetSTHConsistencyPath: appHandler{opts: opts, log: log, handler: getSTHConsistency, name: getSTHConsistencyName, method: http.MethodGet},
+		prefix + ctrfc6962.GetProofByHashPath:    appHandler{opts: opts, log: log, handler: getProofByHash, name: getProofByHashName, method: http.MethodGet},
+		prefix + ctrfc6962.GetEntriesPath:        appHandler{opts: opts, log: log, handler: getEntries, name: getEntriesName, method: http.MethodGet},
+		prefix + ctrfc6962.GetEntryAndProofPath:  appHandler{opts: opts, log: log, handler: getEntryAndProof, name: getEntryAndProofName, method: http.MethodGet},
+	}
+}
+
+// signedTreeHead holds a parsed https://c2sp.org/static-ct-api checkpoint,
+// together with its RFC 6962 STH signature.
+type signedTreeHead struct {
+	checkpoint tfl.Checkpoint
+	sig        rfc6962NoteSignature
+}
+
+// readSTH reads the latest checkpoint of the log, and converts it back to an
+// RFC 6962 STH.
+func readSTH(ctx context.Context, log *log) (*signedTreeHead, error) {
+	cpRaw, err := log.reader.ReadCheckpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	// Checkpoints are read from the log's own storage, so there's no need to
+	// verify them. Without any verifier, note.Open returns the note with all
+	// its signatures as unverified.
+	_, err = note.Open(cpRaw, note.VerifierList())
+	var nErr *note.UnverifiedNoteError
+	if !errors.As(err, &nErr) {
+		return nil, fmt.Errorf("failed to open checkpoint: %v", err)
+	}
+	n := nErr.Note
+	sth := &signedTreeHead{}
+	if _, err := sth.checkpoint.Unmarshal([]byte(n.Text)); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %v", err)
+	}
+	var sig []byte
+	for _, s := range n.UnverifiedSigs {
+		if s.Name == log.origin && s.Hash == log.cpKeyHash {
+			sig, err = base64.StdEncoding.DecodeString(s.Base64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode checkpoint signature: %v", err)
+			}
+			break
+		}
+	}
+	if len(sig) < 4 {
+		return nil, errors.New("checkpoint has no signature from the log")
+	}
+	// Skip the 4 bytes key hash.
+	if rest, err := tls.Unmarshal(sig[4:], &sth.sig); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint signature: %v", err)
+	} else if len(rest) > 0 {
+		return nil, fmt.Errorf("extra data (%d bytes) after checkpoint signature", len(rest))
+	}
+	return sth, nil
+}
+
+// parseUintParam parses the named form parameter as an unsigned integer.
+func parseUintParam(r *http.Request, name string) (uint64, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return 0, fmt.Errorf("missing %s parameter", name)
+	}
+	u, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter %q: %v", name, v, err)
+	}
+	return u, nil
+}
+
+// writeJSONResponse writes rsp as a JSON response.
+func writeJSONResponse(w http.ResponseWriter, rsp any) (int, []attribute.KeyValue, error) {
+	w.Header().Set(contentTypeHeader, contentTypeJSON)
+	jsonData, err := json.Marshal(rsp)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to marshal response: %v", err)
+	}
+	if _, err := w.Write(jsonData); err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to write response: %v", err)
+	}
+	return http.StatusOK, nil, nil
+}
+
+// readTileFunc returns a client.TileFetcherFunc reading tiles from the log.
+//
+// Tiles are cached for the duration of a request. Partial tiles which have
+// since been completed are extracted from the corresponding full tile, so that
+// proofs can be built for any tree size up to the current one.
+func readTileFunc(log *log) client.TileFetcherFunc {
+	type tileKey struct {
+		level, index uint64
+		p            uint8
+	}
+	tiles := make(map[tileKey][]byte)
+	return func(ctx context.Context, level, index uint64, p uint8) ([]byte, error) {
+		k := tileKey{level, index, p}
+		if t, ok := tiles[k]; ok {
+			return t, nil
+		}
+		t, err := log.reader.ReadTile(ctx, level, index, p)
+		if errors.Is(err, os.ErrNotExist) && p > 0 {
+			t, err = log.reader.ReadTile(ctx, level, index, 0)
+			if err == nil {
+				if len(t) < int(p)*rfc6962.DefaultHasher.Size() {
+					return nil, fmt.Errorf("tile %s too short to extract partial tile of size %d", layout.TilePath(level, index, 0), p)
+				}
+				t = t[:int(p)*rfc6962.DefaultHasher.Size()]
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		tiles[k] = t
+		return t, nil
+	}
+}
+
+// proofBuilder returns a ProofBuilder for the tree of the given size.
+func proofBuilder(ctx context.Context, log *log, size uint64) (*client.ProofBuilder, error) {
+	f := readTileFunc(log)
+	cp := tfl.Checkpoint{Origin: log.origin, Size: size}
+	if size > 0 {
+		hashes, err := client.FetchRangeNodes(ctx, size, f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch range nodes: %v", err)
+		}
+		r, err := (&compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}).NewRange(0, size, hashes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build compact range: %v", err)
+		}
+		cp.Hash, err = r.GetRootHash(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute root hash: %v", err)
+		}
+	}
+	return client.NewProofBuilder(ctx, cp, f)
+}
+
+// readLeafEntries reads the entries in [start, end] and converts them to RFC
+// 6962 leaf entries, in a log of the given size.
+func readLeafEntries(ctx context.Context, log *log, start, end, size uint64) ([]ctrfc6962.LeafEntry, error) {
+	issuers := make(map[[32]byte][]byte)
+	entries := make([]ctrfc6962.LeafEntry, 0, end-start+1)
+	for bIdx := start / layout.EntryBundleWidth; bIdx <= end/layout.EntryBundleWidth; bIdx++ {
+		bundle, err := client.GetEntryBundle(ctx, log.reader.ReadEntryBundle, bIdx, size)
+		if err != nil {
+			return nil, err
+		}
+		first := bIdx * layout.EntryBundleWidth
+		for i := max(start, first); i <= end && i < first+uint64(len(bundle.Entries)); i++ {
+			le, err := leafEntry(ctx, log, bundle.Entries[i-first], issuers)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert entry %d: %v", i, err)
+			}
+			entries = append(entries, le)
+		}
+	}
+	if got, want := uint64(len(entries)), end-start+1; got != want {
+		return nil, fmt.Errorf("got %d entries, want %d", got, want)
+	}
+	return entries, nil
+}
+
+// leafEntry converts a https://c2sp.org/static-ct-api entry to an RFC 6962
+// leaf entry.
+//
+// Issuers are fetched from the log's issuer storage and cached in issuers.
+func leafEntry(ctx context.Context, log *log, raw []byte, issuers map[[32]byte][]byte) (ctrfc6962.LeafEntry, error) {
+	e := staticct.Entry{}
+	if err := e.UnmarshalText(raw); err != nil {
+		return ctrfc6962.LeafEntry{}, fmt.Errorf("failed to parse entry: %v", err)
+	}
+	ctEntry := ctonly.Entry{
+		Timestamp:      e.Timestamp,
+		IsPrecert:      e.IsPrecert,
+		Certificate:    e.Certificate,
+		Precertificate: e.Precertificate,
+		IssuerKeyHash:  e.IssuerKeyHash,
+	}
+
+	chain := make([]ctrfc6962.ASN1Cert, 0, len(e.FingerprintsChain))
+	for _, fp := range e.FingerprintsChain {
+		iss, ok := issuers[fp]
+		if !ok {
+			var err error
+			iss, err = log.reader.ReadIssuer(ctx, []byte(hex.EncodeToString(fp[:])))
+			if err != nil {
+				return ctrfc6962.LeafEntry{}, fmt.Errorf("failed to read issuer %x: %w", fp, err)
+			}
+			issuers[fp] = iss
+		}
+		chain = append(chain, ctrfc6962.ASN1Cert{Data: iss})
+	}
+
+	var extra any
+	if e.IsPrecert {
+		extra = ctrfc6962.PrecertChainEntry{
+			PreCertificate:   ctrfc6962.ASN1Cert{Data: e.Precertificate},
+			CertificateChain: chain,
+		}
+	} else {
+		extra = ctrfc6962.CertificateChain{Entries: chain}
+	}
+	extraData, err := tls.Marshal(extra)
+	if err != nil {
+		return ctrfc6962.LeafEntry{}, fmt.Errorf("failed to marshal extra data: %v", err)
+	}
+
+	return ctrfc6962.LeafEntry{
+		LeafInput: ctEntry.MerkleTreeLeaf(e.LeafIndex),
+		ExtraData: extraData,
+	}, nil
+}
+
+func getSTH(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, _ *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getSTH")
+	defer span.End()
+
+	sth, err := readSTH(ctx, log)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	sig, err := tls.Marshal(sth.sig.Signature)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to marshal STH signature: %v", err)
+	}
+
+	return writeJSONResponse(w, ctrfc6962.GetSTHResponse{
+		TreeSize:          sth.checkpoint.Size,
+		Timestamp:         sth.sig.Timestamp,
+		SHA256RootHash:    sth.checkpoint.Hash,
+		TreeHeadSignature: sig,
+	})
+}
+
+func getSTHConsistency(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getSTHConsistency")
+	defer span.End()
+
+	first, err := parseUintParam(r, "first")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	second, err := parseUintParam(r, "second")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if first > second {
+		return http.StatusBadRequest, nil, fmt.Errorf("first %d > second %d", first, second)
+	}
+	sth, err := readSTH(ctx, log)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if second > sth.checkpoint.Size {
+		return http.StatusBadRequest, nil, fmt.Errorf("second %d > tree size %d", second, sth.checkpoint.Size)
+	}
+
+	rsp := ctrfc6962.GetSTHConsistencyResponse{Consistency: [][]byte{}}
+	if first > 0 && first < second {
+		pb, err := proofBuilder(ctx, log, second)
+		if err != nil {
+			return http.StatusInternalServerError, nil, fmt.Errorf("failed to create proof builder: %v", err)
+		}
+		rsp.Consistency, err = pb.ConsistencyProof(ctx, first, second)
+		if err != nil {
+			return http.StatusInternalServerError, nil, fmt.Errorf("failed to build consistency proof: %v", err)
+		}
+	}
+	return writeJSONResponse(w, rsp)
+}
+
+func getProofByHash(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getProofByHash")
+	defer span.End()
+
+	hash, err := parseLeafHash(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	treeSize, err := parseUintParam(r, "tree_size")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	sth, err := readSTH(ctx, log)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if treeSize > sth.checkpoint.Size {
+		return http.StatusBadRequest, nil, fmt.Errorf("tree_size %d > tree size %d", treeSize, sth.checkpoint.Size)
+	}
+
+	idx, status, err := lookupLeafIndex(ctx, log, hash)
+	if err != nil {
+		return status, nil, err
+	}
+	if idx >= treeSize {
+		return http.StatusNotFound, nil, fmt.Errorf("leaf hash %x not found in tree of size %d", hash, treeSize)
+	}
+
+	pb, err := proofBuilder(ctx, log, treeSize)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to create proof builder: %v", err)
+	}
+	p, err := pb.InclusionProof(ctx, idx)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to build inclusion proof: %v", err)
+	}
+	return writeJSONResponse(w, ctrfc6962.GetProofByHashResponse{
+		LeafIndex: int64(idx),
+		AuditPath: p,
+	})
+}
+
+func getEntries(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getEntries")
+	defer span.End()
+
+	start, err := parseUintParam(r, "start")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	end, err := parseUintParam(r, "end")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if start > end {
+		return http.StatusBadRequest, nil, fmt.Errorf("start %d > end %d", start, end)
+	}
+	sth, err := readSTH(ctx, log)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if start >= sth.checkpoint.Size {
+		return http.StatusBadRequest, nil, fmt.Errorf("start %d >= tree size %d", start, sth.checkpoint.Size)
+	}
+	// Logs may return fewer entries than requested.
+	end = min(end, sth.checkpoint.Size-1, start+maxGetEntries-1)
+
+	klog.V(3).Infof("%s: %s => readLeafEntries(%d, %d)", log.origin, getEntriesName, start, end)
+	entries, err := readLeafEntries(ctx, log, start, end, sth.checkpoint.Size)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to read entries: %v", err)
+	}
+	return writeJSONResponse(w, ctrfc6962.GetEntriesResponse{Entries: entries})
+}
+
+func getEntryAndProof(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.getEntryAndProof")
+	defer span.End()
+
+	leafIndex, err := parseUintParam(r, "leaf_index")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	treeSize, err := parseUintParam(r, "tree_size")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if leafIndex >= treeSize {
+		return http.StatusBadRequest, nil, fmt.Errorf("leaf_index %d >= tree_size %d", leafIndex, treeSize)
+	}
+	sth, err := readSTH(ctx, log)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if treeSize > sth.checkpoint.Size {
+		return http.StatusBadRequest, nil, fmt.Errorf("tree_size %d > tree size %d", treeSize, sth.checkpoint.Size)
+	}
+
+	entries, err := readLeafEntries(ctx, log, leafIndex, leafIndex, sth.checkpoint.Size)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to read entry: %v", err)
+	}
+	pb, err := proofBuilder(ctx, log, treeSize)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to create proof builder: %v", err)
+	}
+	p, err := pb.InclusionProof(ctx, leafIndex)
+	if err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("failed to build inclusion proof: %v", err)
+	}
+	return writeJSONResponse(w, ctrfc6962.GetEntryAndProofResponse{
+		LeafInput: entries[0].LeafInput,
+		ExtraData: entries[0].ExtraData,
+		AuditPath: p,
+	})
+}
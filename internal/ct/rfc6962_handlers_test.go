@@ -0,0 +1,245 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/testdata"
+	ctrfc6962 "github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/types/tls"
+)
+
+// mapLeafIndex implements LeafIndexLookup with a map.
+type mapLeafIndex map[string]uint64
+
+func (m mapLeafIndex) LeafIndex(_ context.Context, leafHash []byte) (uint64, error) {
+	idx, ok := m[string(leafHash)]
+	if !ok {
+		return 0, os.ErrNotExist
+	}
+	return idx, nil
+}
+
+// setupRFC6962TestServer creates a test TesseraCT server with write and RFC
+// 6962 read endpoints, and adds a certificate and a precertificate to it.
+func setupRFC6962TestServer(t *testing.T) (*httptest.Server, *log) {
+	t.Helper()
+	log, dir := setupTestLog(t)
+
+	mux := http.NewServeMux()
+	for p, h := range NewPathHandlers(t.Context(), &hOpts, log) {
+		mux.Handle(p, h)
+	}
+	for p, h := range NewRFC6962PathHandlers(t.Context(), &hOpts, log) {
+		mux.Handle(p, h)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for _, c := range []struct {
+		path  string
+		chain []string
+	}{
+		{path: ctrfc6962.AddChainPath, chain: []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM}},
+		{path: ctrfc6962.AddPreChainPath, chain: []string{testdata.PreCertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM}},
+	} {
+		pool := loadCertsIntoPoolOrDie(t, c.chain)
+		resp, err := http.Post(server.URL+prefix+c.path, "application/json", createJSONChain(t, *pool))
+		if err != nil {
+			t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", c.path, err)
+		}
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", c.path, got, want)
+		}
+	}
+	waitForCheckpoint(t, dir, 2)
+
+	return server, log
+}
+
+// getJSON sends a GET request to the RFC 6962 endpoint at path with params,
+// and decodes its response in rsp if the request succeeds.
+func getJSON(t *testing.T, server *httptest.Server, path string, params url.Values, rsp any) int {
+	t.Helper()
+	resp, err := http.Get(server.URL + prefix + path + "?" + params.Encode())
+	if err != nil {
+		t.Fatalf("http.Get(%s)=(_,%q); want (_,nil)", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK {
+		if got, want := resp.Header.Get(contentTypeHeader), contentTypeJSON; got != want {
+			t.Errorf("http.Get(%s): got %s %q, want %q", path, contentTypeHeader, got, want)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(rsp); err != nil {
+			t.Fatalf("json.Decode()=%v; want nil", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestNewRFC6962PathHandlers(t *testing.T) {
+	log, _ := setupTestLog(t)
+	handlers := NewRFC6962PathHandlers(t.Context(), &HandlerOptions{}, log)
+	if got, want := len(handlers), len(rfc6962Entrypoints); got != want {
+		t.Fatalf("len(handlers)=%d; want %d", got, want)
+	}
+	for _, p := range []string{ctrfc6962.GetSTHPath, ctrfc6962.GetSTHConsistencyPath, ctrfc6962.GetProofByHashPath, ctrfc6962.GetEntriesPath, ctrfc6962.GetEntryAndProofPath} {
+		if _, ok := handlers[prefix+p]; !ok {
+			t.Errorf("%q path not registered", p)
+		}
+	}
+}
+
+func TestRFC6962Handlers(t *testing.T) {
+	server, log := setupRFC6962TestServer(t)
+
+	// get-sth
+	var sth ctrfc6962.GetSTHResponse
+	if got, want := getJSON(t, server, ctrfc6962.GetSTHPath, nil, &sth), http.StatusOK; got != want {
+		t.Fatalf("get-sth: got status %d, want %d", got, want)
+	}
+	if got, want := sth.TreeSize, uint64(2); got != want {
+		t.Errorf("get-sth: got tree_size %d, want %d", got, want)
+	}
+	var sig ctrfc6962.DigitallySigned
+	if _, err := tls.Unmarshal(sth.TreeHeadSignature, &sig); err != nil {
+		t.Fatalf("get-sth: failed to parse tree_head_signature: %v", err)
+	}
+	if got, want := sig.Signature, fakeSignature; !bytes.Equal(got, want) {
+		t.Errorf("get-sth: got signature %q, want %q", got, want)
+	}
+	if got, want := sth.Timestamp, uint64(timeSource.Now().UnixMilli()); got != want {
+		t.Errorf("get-sth: got timestamp %d, want %d", got, want)
+	}
+
+	// get-entries
+	var entries ctrfc6962.GetEntriesResponse
+	if got, want := getJSON(t, server, ctrfc6962.GetEntriesPath, url.Values{"start": {"0"}, "end": {"5"}}, &entries), http.StatusOK; got != want {
+		t.Fatalf("get-entries: got status %d, want %d", got, want)
+	}
+	if got, want := len(entries.Entries), 2; got != want {
+		t.Fatalf("get-entries: got %d entries, want %d", got, want)
+	}
+	leafHashes := make([][]byte, 0, len(entries.Entries))
+	for i, e := range entries.Entries {
+		var leaf ctrfc6962.MerkleTreeLeaf
+		if _, err := tls.Unmarshal(e.LeafInput, &leaf); err != nil {
+			t.Fatalf("get-entries: failed to parse leaf_input %d: %v", i, err)
+		}
+		var chain []ctrfc6962.ASN1Cert
+		switch leaf.TimestampedEntry.EntryType {
+		case ctrfc6962.X509LogEntryType:
+			var c ctrfc6962.CertificateChain
+			if _, err := tls.Unmarshal(e.ExtraData, &c); err != nil {
+				t.Fatalf("get-entries: failed to parse extra_data %d: %v", i, err)
+			}
+			chain = c.Entries
+		case ctrfc6962.PrecertLogEntryType:
+			var c ctrfc6962.PrecertChainEntry
+			if _, err := tls.Unmarshal(e.ExtraData, &c); err != nil {
+				t.Fatalf("get-entries: failed to parse extra_data %d: %v", i, err)
+			}
+			chain = c.CertificateChain
+		}
+		if got, want := len(chain), 2; got != want {
+			t.Errorf("get-entries: got chain of length %d for entry %d, want %d", got, want, i)
+		}
+		leafHashes = append(leafHashes, rfc6962.DefaultHasher.HashLeaf(e.LeafInput))
+	}
+
+	// get-entry-and-proof
+	for i, lh := range leafHashes {
+		var rsp ctrfc6962.GetEntryAndProofResponse
+		params := url.Values{"leaf_index": {fmt.Sprint(i)}, "tree_size": {"2"}}
+		if got, want := getJSON(t, server, ctrfc6962.GetEntryAndProofPath, params, &rsp), http.StatusOK; got != want {
+			t.Fatalf("get-entry-and-proof(%d): got status %d, want %d", i, got, want)
+		}
+		if !bytes.Equal(rsp.LeafInput, entries.Entries[i].LeafInput) || !bytes.Equal(rsp.ExtraData, entries.Entries[i].ExtraData) {
+			t.Errorf("get-entry-and-proof(%d): entry does not match get-entries", i)
+		}
+		if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(i), sth.TreeSize, lh, rsp.AuditPath, sth.SHA256RootHash); err != nil {
+			t.Errorf("get-entry-and-proof(%d): failed to verify inclusion proof: %v", i, err)
+		}
+	}
+
+	// get-sth-consistency, from the tree containing only the first entry.
+	var cons ctrfc6962.GetSTHConsistencyResponse
+	if got, want := getJSON(t, server, ctrfc6962.GetSTHConsistencyPath, url.Values{"first": {"1"}, "second": {"2"}}, &cons), http.StatusOK; got != want {
+		t.Fatalf("get-sth-consistency: got status %d, want %d", got, want)
+	}
+	if err := proof.VerifyConsistency(rfc6962.DefaultHasher, 1, 2, cons.Consistency, leafHashes[0], sth.SHA256RootHash); err != nil {
+		t.Errorf("get-sth-consistency: failed to verify consistency proof: %v", err)
+	}
+
+	// get-proof-by-hash is not available without a leaf index.
+	hashParams := url.Values{"hash": {base64.StdEncoding.EncodeToString(leafHashes[1])}, "tree_size": {"2"}}
+	if got, want := getJSON(t, server, ctrfc6962.GetProofByHashPath, hashParams, nil), http.StatusNotImplemented; got != want {
+		t.Errorf("get-proof-by-hash without leaf index: got status %d, want %d", got, want)
+	}
+	log.leafIndex = mapLeafIndex{string(leafHashes[0]): 0, string(leafHashes[1]): 1}
+	var pbh ctrfc6962.GetProofByHashResponse
+	if got, want := getJSON(t, server, ctrfc6962.GetProofByHashPath, hashParams, &pbh), http.StatusOK; got != want {
+		t.Fatalf("get-proof-by-hash: got status %d, want %d", got, want)
+	}
+	if got, want := pbh.LeafIndex, int64(1); got != want {
+		t.Errorf("get-proof-by-hash: got leaf_index %d, want %d", got, want)
+	}
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, 1, sth.TreeSize, leafHashes[1], pbh.AuditPath, sth.SHA256RootHash); err != nil {
+		t.Errorf("get-proof-by-hash: failed to verify inclusion proof: %v", err)
+	}
+}
+
+func TestRFC6962HandlersBadRequests(t *testing.T) {
+	server, log := setupRFC6962TestServer(t)
+	log.leafIndex = mapLeafIndex{}
+	unknownHash := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	for _, test := range []struct {
+		descr  string
+		path   string
+		params url.Values
+		want   int
+	}{
+		{descr: "consistency-missing-first", path: ctrfc6962.GetSTHConsistencyPath, params: url.Values{"second": {"2"}}, want: http.StatusBadRequest},
+		{descr: "consistency-first-after-second", path: ctrfc6962.GetSTHConsistencyPath, params: url.Values{"first": {"2"}, "second": {"1"}}, want: http.StatusBadRequest},
+		{descr: "consistency-beyond-tree", path: ctrfc6962.GetSTHConsistencyPath, params: url.Values{"first": {"1"}, "second": {"3"}}, want: http.StatusBadRequest},
+		{descr: "entries-invalid-start", path: ctrfc6962.GetEntriesPath, params: url.Values{"start": {"-1"}, "end": {"1"}}, want: http.StatusBadRequest},
+		{descr: "entries-start-after-end", path: ctrfc6962.GetEntriesPath, params: url.Values{"start": {"1"}, "end": {"0"}}, want: http.StatusBadRequest},
+		{descr: "entries-beyond-tree", path: ctrfc6962.GetEntriesPath, params: url.Values{"start": {"2"}, "end": {"3"}}, want: http.StatusBadRequest},
+		{descr: "entry-and-proof-index-beyond-size", path: ctrfc6962.GetEntryAndProofPath, params: url.Values{"leaf_index": {"1"}, "tree_size": {"1"}}, want: http.StatusBadRequest},
+		{descr: "entry-and-proof-beyond-tree", path: ctrfc6962.GetEntryAndProofPath, params: url.Values{"leaf_index": {"1"}, "tree_size": {"3"}}, want: http.StatusBadRequest},
+		{descr: "proof-by-hash-invalid-hash", path: ctrfc6962.GetProofByHashPath, params: url.Values{"hash": {"abcd"}, "tree_size": {"2"}}, want: http.StatusBadRequest},
+		{descr: "proof-by-hash-beyond-tree", path: ctrfc6962.GetProofByHashPath, params: url.Values{"hash": {unknownHash}, "tree_size": {"3"}}, want: http.StatusBadRequest},
+		{descr: "proof-by-hash-unknown-hash", path: ctrfc6962.GetProofByHashPath, params: url.Values{"hash": {unknownHash}, "tree_size": {"2"}}, want: http.StatusNotFound},
+	} {
+		t.Run(test.descr, func(t *testing.T) {
+			if got := getJSON(t, server, test.path, test.params, nil); got != test.want {
+				t.Errorf("http.Get(%s, %v): got status %d, want %d", test.path, test.params, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/x509util"
+	"go.opentelemetry.io/otel/metric"
+	"k8s.io/klog/v2"
+)
+
+var rootsVersion = mustCreate(meter.Int64Gauge("tesseract.roots.version",
+	metric.WithDescription("Version of the accepted roots, incremented every time they change")))
+
+// Roots holds the root certificates accepted by a log.
+//
+// Each root can be restricted to a time window, outside of which it is
+// neither accepted nor served by get-roots.
+//
+// Roots loaded from a file can be reloaded without restarting the log. Chain
+// validation and get-roots responses always use a consistent set of roots.
+type Roots struct {
+	origin string
+	// path is the file roots are loaded from, and load reads it.
+	path string
+	load func(path string) ([]rootEntry, [sha256.Size]byte, error)
+
+	// mu serializes reloads.
+	mu      sync.Mutex
+	current atomic.Pointer[rootsSnapshot]
+}
+
+// rootEntry is a root, and the conditions under which it's accepted.
+type rootEntry struct {
+	cert *x509.Certificate
+	// acceptFrom is the time from which the root is accepted, inclusive.
+	// nil means that the root is accepted from the beginning of time.
+	acceptFrom *time.Time
+	// acceptUntil is the time until which the root is accepted, exclusive.
+	// nil means that the root is accepted until the end of time.
+	acceptUntil *time.Time
+	// labels are free-form annotations, such as the root programs including
+	// the root.
+	labels []string
+}
+
+// activeAt returns whether the root is accepted at time t.
+func (e rootEntry) activeAt(t time.Time) bool {
+	return (e.acceptFrom == nil || !t.Before(*e.acceptFrom)) && (e.acceptUntil == nil || t.Before(*e.acceptUntil))
+}
+
+// String returns a human readable description of the root, for logging.
+func (e rootEntry) String() string {
+	s := fmt.Sprintf("%q (sha256: %x)", e.cert.Subject, sha256.Sum256(e.cert.Raw))
+	if e.acceptFrom != nil {
+		s += fmt.Sprintf(" from %s", e.acceptFrom.Format(time.RFC3339))
+	}
+	if e.acceptUntil != nil {
+		s += fmt.Sprintf(" until %s", e.acceptUntil.Format(time.RFC3339))
+	}
+	if len(e.labels) > 0 {
+		s += fmt.Sprintf(" %v", e.labels)
+	}
+	return s
+}
+
+// equal returns whether e and o are the same root, with the same conditions.
+func (e rootEntry) equal(o rootEntry) bool {
+	timeEqual := func(a, b *time.Time) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && a.Equal(*b))
+	}
+	return e.cert.Equal(o.cert) && timeEqual(e.acceptFrom, o.acceptFrom) && timeEqual(e.acceptUntil, o.acceptUntil) && slices.Equal(e.labels, o.labels)
+}
+
+// rootsSnapshot is an immutable set of roots.
+type rootsSnapshot struct {
+	entries []rootEntry
+	// windows partition time in contiguous intervals, in chronological
+	// order, during which the set of active roots doesn't change.
+	windows []rootsWindow
+	// fileHash is the SHA-256 of the files the roots were loaded from.
+	fileHash [sha256.Size]byte
+	// version is incremented every time the set of roots changes.
+	version int64
+}
+
+// rootsWindow holds the roots active from start until the start of the next
+// window.
+type rootsWindow struct {
+	start time.Time
+	pool  *x509util.PEMCertPool
+	// getRootsRsp is the precomputed get-roots response body.
+	getRootsRsp []byte
+}
+
+// NewRoots returns static roots, which are always accepted, and can't be
+// reloaded.
+func NewRoots(pool *x509util.PEMCertPool) (*Roots, error) {
+	entries := make([]rootEntry, 0, len(pool.RawCertificates()))
+	for _, cert := range pool.RawCertificates() {
+		entries = append(entries, rootEntry{cert: cert})
+	}
+	s, err := newRootsSnapshot(entries, 1)
+	if err != nil {
+		return nil, err
+	}
+	r := &Roots{}
+	r.current.Store(s)
+	return r, nil
+}
+
+// LoadRoots loads the roots of the log identified by origin from pemFile.
+// These roots are always accepted.
+func LoadRoots(origin, pemFile string) (*Roots, error) {
+	if pemFile == "" {
+		return nil, errors.New("empty rootsPemFile")
+	}
+	return loadRoots(origin, pemFile, loadPEMFile)
+}
+
+// LoadRootsManifest loads the roots of the log identified by origin from a
+// roots manifest.
+func LoadRootsManifest(origin, manifestFile string) (*Roots, error) {
+	if manifestFile == "" {
+		return nil, errors.New("empty rootsManifestFile")
+	}
+	return loadRoots(origin, manifestFile, loadManifest)
+}
+
+func loadRoots(origin, path string, load func(string) ([]rootEntry, [sha256.Size]byte, error)) (*Roots, error) {
+	r := &Roots{origin: origin, path: path, load: load}
+	if _, err := r.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// loadPEMFile returns the roots in pemFile, and the hash of its contents.
+func loadPEMFile(pemFile string) ([]rootEntry, [sha256.Size]byte, error) {
+	pemData, err := os.ReadFile(pemFile)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, fmt.Errorf("failed to read trusted roots: %v", err)
+	}
+	pool := x509util.NewPEMCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, [sha256.Size]byte{}, errors.New("failed to read trusted roots: failed to parse PEM certs file")
+	}
+	entries := make([]rootEntry, 0, len(pool.RawCertificates()))
+	for _, cert := range pool.RawCertificates() {
+		entries = append(entries, rootEntry{cert: cert})
+	}
+	return entries, sha256.Sum256(pemData), nil
+}
+
+// Reload reads the roots file again, and swaps the roots in use if they have
+// changed. The roots in use are left untouched if the file can't be loaded.
+// It returns whether the roots changed.
+func (r *Roots) Reload(ctx context.Context) (bool, error) {
+	if r.load == nil {
+		return false, errors.New("roots were not loaded from a file")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, fileHash, err := r.load(r.path)
+	if err != nil {
+		return false, err
+	}
+	old := r.current.Load()
+	if old != nil && old.fileHash == fileHash {
+		return false, nil
+	}
+
+	var version int64 = 1
+	if old != nil {
+		version = old.version
+		added, removed := diffRoots(old.entries, entries)
+		if len(added) == 0 && len(removed) == 0 {
+			// The file changed, but not the roots it contains.
+			s := *old
+			s.fileHash = fileHash
+			r.current.Store(&s)
+			return false, nil
+		}
+		for _, e := range added {
+			klog.Infof("%s: added root %s", r.origin, e)
+		}
+		for _, e := range removed {
+			klog.Infof("%s: removed root %s", r.origin, e)
+		}
+		version++
+	}
+
+	s, err := newRootsSnapshot(entries, version)
+	if err != nil {
+		return false, err
+	}
+	s.fileHash = fileHash
+	r.current.Store(s)
+	klog.Infof("%s: loaded %d roots from %q, version %d", r.origin, len(entries), r.path, version)
+	rootsVersion.Record(ctx, version, metric.WithAttributes(originKey.String(r.origin)))
+	return true, nil
+}
+
+// Pool returns the roots accepted at time t.
+func (r *Roots) Pool(t time.Time) *x509util.PEMCertPool {
+	return r.current.Load().at(t).pool
+}
+
+// Version returns the version of the roots currently in use. It starts at 1,
+// and is incremented every time the roots change.
+func (r *Roots) Version() int64 {
+	return r.current.Load().version
+}
+
+// GetRootsResponse returns the get-roots response body for the roots
+// accepted at time t.
+func (r *Roots) GetRootsResponse(t time.Time) []byte {
+	return r.current.Load().at(t).getRootsRsp
+}
+
+// at returns the window containing time t.
+func (s *rootsSnapshot) at(t time.Time) *rootsWindow {
+	// Find the first window starting after t, the previous one contains t.
+	i := sort.Search(len(s.windows), func(i int) bool { return s.windows[i].start.After(t) })
+	if i == 0 {
+		return &s.windows[0]
+	}
+	return &s.windows[i-1]
+}
+
+func newRootsSnapshot(entries []rootEntry, version int64) (*rootsSnapshot, error) {
+	// The set of active roots only changes at the boundaries of their windows.
+	starts := []time.Time{{}}
+	for _, e := range entries {
+		for _, t := range []*time.Time{e.acceptFrom, e.acceptUntil} {
+			if t != nil {
+				starts = append(starts, *t)
+			}
+		}
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	starts = slices.CompactFunc(starts, func(a, b time.Time) bool { return a.Equal(b) })
+
+	windows := make([]rootsWindow, 0, len(starts))
+	for _, start := range starts {
+		pool := x509util.NewPEMCertPool()
+		for _, e := range entries {
+			if e.activeAt(start) {
+				pool.AddCert(e.cert)
+			}
+		}
+		rsp, err := getRootsResponse(pool)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, rootsWindow{start: start, pool: pool, getRootsRsp: rsp})
+	}
+	return &rootsSnapshot{entries: entries, windows: windows, version: version}, nil
+}
+
+// getRootsResponse returns a get-roots response body for the roots in pool.
+func getRootsResponse(pool *x509util.PEMCertPool) ([]byte, error) {
+	rawCerts := make([][]byte, 0, len(pool.RawCertificates()))
+	for _, cert := range pool.RawCertificates() {
+		rawCerts = append(rawCerts, cert.Raw)
+	}
+	rsp, err := json.Marshal(map[string]any{jsonMapKeyCertificates: rawCerts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal get-roots response: %v", err)
+	}
+	// Match the output of a json.Encoder.
+	return append(rsp, '\n'), nil
+}
+
+// diffRoots returns the roots added to, and removed from, old in new. A root
+// whose conditions changed is both removed and added.
+func diffRoots(old, new []rootEntry) (added, removed []rootEntry) {
+	contains := func(es []rootEntry, e rootEntry) bool {
+		return slices.ContainsFunc(es, e.equal)
+	}
+	for _, e := range new {
+		if !contains(old, e) {
+			added = append(added, e)
+		}
+	}
+	for _, e := range old {
+		if !contains(new, e) {
+			removed = append(removed, e)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/x509util"
+	"gopkg.in/yaml.v3"
+)
+
+// rootsManifest lists the roots accepted by a log. It is a YAML or JSON file.
+//
+// For example:
+//
+//	roots:
+//	  - pem_file: roots/root1.pem
+//	    labels: [program1, program2]
+//	  - pem_file: roots/root2.pem
+//	    accept_from: 2025-09-01T00:00:00Z
+//	    labels: [program1]
+//	  - pem: |
+//	      -----BEGIN CERTIFICATE-----
+//	      ...
+//	      -----END CERTIFICATE-----
+//	    accept_until: 2026-04-15T00:00:00Z
+type rootsManifest struct {
+	Roots []rootsManifestEntry `yaml:"roots"`
+}
+
+// rootsManifestEntry holds one or more roots sharing the same conditions.
+type rootsManifestEntry struct {
+	// PEM holds PEM encoded roots.
+	PEM string `yaml:"pem,omitempty"`
+	// PEMFile is the path to a file holding PEM encoded roots. Relative paths
+	// are relative to the directory of the manifest.
+	PEMFile string `yaml:"pem_file,omitempty"`
+	// AcceptFrom is the time from which the roots are accepted, inclusive.
+	AcceptFrom *time.Time `yaml:"accept_from,omitempty"`
+	// AcceptUntil is the time until which the roots are accepted, exclusive.
+	AcceptUntil *time.Time `yaml:"accept_until,omitempty"`
+	// Labels are free-form annotations, such as the root programs including
+	// the roots.
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+// loadManifest returns the roots listed in the manifest at path, and the hash
+// of the manifest and of the PEM files it references.
+func loadManifest(path string) ([]rootEntry, [sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	h := sha256.New()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, hash, fmt.Errorf("failed to read roots manifest: %v", err)
+	}
+	h.Write(b)
+
+	var m rootsManifest
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, hash, errors.New("empty roots manifest")
+		}
+		return nil, hash, fmt.Errorf("failed to parse roots manifest: %v", err)
+	}
+
+	var entries []rootEntry
+	seen := make(map[[sha256.Size]byte]int)
+	for i, me := range m.Roots {
+		pemData := []byte(me.PEM)
+		switch {
+		case me.PEM != "" && me.PEMFile != "":
+			return nil, hash, fmt.Errorf("roots[%d]: only one of pem and pem_file can be set", i)
+		case me.PEMFile != "":
+			p := me.PEMFile
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(filepath.Dir(path), p)
+			}
+			if pemData, err = os.ReadFile(p); err != nil {
+				return nil, hash, fmt.Errorf("roots[%d]: failed to read PEM file: %v", i, err)
+			}
+			h.Write(pemData)
+		case me.PEM == "":
+			return nil, hash, fmt.Errorf("roots[%d]: one of pem or pem_file must be set", i)
+		}
+		for name, t := range map[string]*time.Time{"accept_from": me.AcceptFrom, "accept_until": me.AcceptUntil} {
+			if t != nil && t.Location() != time.UTC {
+				return nil, hash, fmt.Errorf("roots[%d]: %s must be in UTC", i, name)
+			}
+		}
+		if me.AcceptFrom != nil && me.AcceptUntil != nil && !me.AcceptFrom.Before(*me.AcceptUntil) {
+			return nil, hash, fmt.Errorf("roots[%d]: accept_until %s is not after accept_from %s", i, me.AcceptUntil.Format(time.RFC3339), me.AcceptFrom.Format(time.RFC3339))
+		}
+
+		pool := x509util.NewPEMCertPool()
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, hash, fmt.Errorf("roots[%d]: failed to parse PEM certs", i)
+		}
+		for _, cert := range pool.RawCertificates() {
+			fp := sha256.Sum256(cert.Raw)
+			if j, ok := seen[fp]; ok {
+				return nil, hash, fmt.Errorf("roots[%d]: root %q is already listed in roots[%d]", i, cert.Subject, j)
+			}
+			seen[fp] = i
+			entries = append(entries, rootEntry{cert: cert, acceptFrom: me.AcceptFrom, acceptUntil: me.AcceptUntil, labels: me.Labels})
+		}
+	}
+	if len(entries) == 0 {
+		return nil, hash, errors.New("no root in roots manifest")
+	}
+
+	copy(hash[:], h.Sum(nil))
+	return entries, hash, nil
+}
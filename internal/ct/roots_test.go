@@ -0,0 +1,371 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/x509util"
+)
+
+const fakeCARootPath = "../testdata/fake-ca.cert"
+
+// mustNewRoots returns static roots from pool.
+func mustNewRoots(t *testing.T, pool *x509util.PEMCertPool) *Roots {
+	t.Helper()
+	r, err := NewRoots(pool)
+	if err != nil {
+		t.Fatalf("NewRoots(): %v", err)
+	}
+	return r
+}
+
+// writeRoots concatenates the PEM files at paths, and writes them to dst.
+func writeRoots(t *testing.T, dst string, paths ...string) {
+	t.Helper()
+	var pem []byte
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("ReadFile(%q): %v", p, err)
+		}
+		pem = append(pem, b...)
+	}
+	if err := os.WriteFile(dst, pem, 0o644); err != nil {
+		t.Fatalf("WriteFile(%q): %v", dst, err)
+	}
+}
+
+// getRootsCount returns the number of certificates in a get-roots response.
+func getRootsCount(t *testing.T, r *Roots) int {
+	t.Helper()
+	var rsp map[string][][]byte
+	if err := json.Unmarshal(r.GetRootsResponse(time.Now()), &rsp); err != nil {
+		t.Fatalf("Failed to unmarshal get-roots response: %v", err)
+	}
+	return len(rsp[jsonMapKeyCertificates])
+}
+
+func TestLoadRootsErrors(t *testing.T) {
+	dir := t.TempDir()
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("-----BEGIN CERTIFICATE-----\nZ2FyYmFnZQ==\n-----END CERTIFICATE-----\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		pemFile string
+		wantErr string
+	}{
+		{
+			desc:    "empty-path",
+			wantErr: "empty rootsPemFile",
+		},
+		{
+			desc:    "missing-file",
+			pemFile: filepath.Join(dir, "missing.pem"),
+			wantErr: "failed to read trusted roots",
+		},
+		{
+			desc:    "invalid-cert",
+			pemFile: garbage,
+			wantErr: "failed to parse PEM certs file",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := LoadRoots(origin, tc.pemFile)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadRoots()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRootsReload(t *testing.T) {
+	ctx := t.Context()
+	pemFile := filepath.Join(t.TempDir(), "roots.pem")
+	writeRoots(t, pemFile, fakeCARootPath)
+
+	r, err := LoadRoots(origin, pemFile)
+	if err != nil {
+		t.Fatalf("LoadRoots(): %v", err)
+	}
+	if got, want := r.Version(), int64(1); got != want {
+		t.Errorf("Version()=%d, want %d", got, want)
+	}
+	if got, want := getRootsCount(t, r), 1; got != want {
+		t.Errorf("get-roots returned %d roots, want %d", got, want)
+	}
+
+	// Reloading an unchanged file is a no-op.
+	if changed, err := r.Reload(ctx); err != nil || changed {
+		t.Errorf("Reload()=(%t, %v), want (false, nil)", changed, err)
+	}
+
+	// Adding a root swaps the pool and the get-roots response.
+	oldPool := r.Pool(time.Now())
+	writeRoots(t, pemFile, fakeCARootPath, testRootPath)
+	if changed, err := r.Reload(ctx); err != nil || !changed {
+		t.Fatalf("Reload()=(%t, %v), want (true, nil)", changed, err)
+	}
+	if got, want := r.Version(), int64(2); got != want {
+		t.Errorf("Version()=%d, want %d", got, want)
+	}
+	if got, want := getRootsCount(t, r), 2; got != want {
+		t.Errorf("get-roots returned %d roots, want %d", got, want)
+	}
+	if got, want := len(oldPool.RawCertificates()), 1; got != want {
+		t.Errorf("Reload() modified the previous pool: got %d roots, want %d", got, want)
+	}
+
+	// Reordering roots changes the file, but not the roots.
+	writeRoots(t, pemFile, testRootPath, fakeCARootPath)
+	if changed, err := r.Reload(ctx); err != nil || changed {
+		t.Errorf("Reload()=(%t, %v), want (false, nil)", changed, err)
+	}
+	if got, want := r.Version(), int64(2); got != want {
+		t.Errorf("Version()=%d, want %d", got, want)
+	}
+
+	// Invalid files are rejected, and the current roots are kept.
+	if err := os.WriteFile(pemFile, []byte("not a PEM file"), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	if _, err := r.Reload(ctx); err == nil {
+		t.Error("Reload()=nil, want err")
+	}
+	if got, want := getRootsCount(t, r), 2; got != want {
+		t.Errorf("get-roots returned %d roots, want %d", got, want)
+	}
+
+	// Removing a root.
+	writeRoots(t, pemFile, testRootPath)
+	if changed, err := r.Reload(ctx); err != nil || !changed {
+		t.Fatalf("Reload()=(%t, %v), want (true, nil)", changed, err)
+	}
+	if got, want := r.Version(), int64(3); got != want {
+		t.Errorf("Version()=%d, want %d", got, want)
+	}
+	if got, want := getRootsCount(t, r), 1; got != want {
+		t.Errorf("get-roots returned %d roots, want %d", got, want)
+	}
+}
+
+func TestStaticRootsReload(t *testing.T) {
+	r := mustNewRoots(t, x509util.NewPEMCertPool())
+	if _, err := r.Reload(t.Context()); err == nil {
+		t.Error("Reload()=nil, want err")
+	}
+}
+
+// writeManifest writes a roots manifest to dir, and returns its path.
+func writeManifest(t *testing.T, dir, manifest string) string {
+	t.Helper()
+	p := filepath.Join(dir, "roots.yaml")
+	if err := os.WriteFile(p, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("WriteFile(%q): %v", p, err)
+	}
+	return p
+}
+
+func TestRootsManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeRoots(t, filepath.Join(dir, "fake-ca.pem"), fakeCARootPath)
+	testRootPEM, err := os.ReadFile(testRootPath)
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	// fake-ca is always accepted, and the test root from 2025-07-01 until
+	// 2026-01-01.
+	manifest := writeManifest(t, dir, `
+roots:
+  - pem_file: fake-ca.pem
+    labels: [program1, program2]
+  - pem: |
+`+indent(string(testRootPEM), "      ")+`
+    accept_from: 2025-07-01T00:00:00Z
+    accept_until: 2026-01-01T00:00:00Z
+    labels: [program1]
+`)
+
+	r, err := LoadRootsManifest(origin, manifest)
+	if err != nil {
+		t.Fatalf("LoadRootsManifest(): %v", err)
+	}
+
+	leaf := mustParsePEMFile(t, "../testdata/test_leaf_cert_signed_by_root.pem")
+	for _, tc := range []struct {
+		now       string
+		wantRoots int
+		wantValid bool
+	}{
+		{now: "2025-06-30T23:59:59Z", wantRoots: 1, wantValid: false},
+		{now: "2025-07-01T00:00:00Z", wantRoots: 2, wantValid: true},
+		{now: "2025-12-31T23:59:59Z", wantRoots: 2, wantValid: true},
+		{now: "2026-01-01T00:00:00Z", wantRoots: 1, wantValid: false},
+	} {
+		t.Run(tc.now, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tc.now)
+			if err != nil {
+				t.Fatalf("time.Parse(): %v", err)
+			}
+			cv := NewChainValidator(r, ChainValidatorOptions{TimeSource: NewFixedTimeSource(now)})
+
+			var rsp map[string][][]byte
+			if err := json.Unmarshal(cv.GetRootsResponse(), &rsp); err != nil {
+				t.Fatalf("Failed to unmarshal get-roots response: %v", err)
+			}
+			if got := len(rsp[jsonMapKeyCertificates]); got != tc.wantRoots {
+				t.Errorf("get-roots returned %d roots, want %d", got, tc.wantRoots)
+			}
+			if got := len(cv.Roots()); got != tc.wantRoots {
+				t.Errorf("Roots() returned %d roots, want %d", got, tc.wantRoots)
+			}
+			_, err = cv.validate([][]byte{leaf.Raw})
+			if gotValid := err == nil; gotValid != tc.wantValid {
+				t.Errorf("validate()=%v, want valid %t", err, tc.wantValid)
+			}
+		})
+	}
+
+	// Changing a window is picked up by a reload.
+	writeManifest(t, dir, `
+roots:
+  - pem_file: fake-ca.pem
+    labels: [program1, program2]
+    accept_until: 2025-01-01T00:00:00Z
+`)
+	if changed, err := r.Reload(t.Context()); err != nil || !changed {
+		t.Fatalf("Reload()=(%t, %v), want (true, nil)", changed, err)
+	}
+	if got, want := len(r.Pool(time.Now()).RawCertificates()), 0; got != want {
+		t.Errorf("got %d roots, want %d", got, want)
+	}
+
+	// So are changes to the referenced PEM files.
+	writeRoots(t, filepath.Join(dir, "fake-ca.pem"), testRootPath)
+	if changed, err := r.Reload(t.Context()); err != nil || !changed {
+		t.Fatalf("Reload()=(%t, %v), want (true, nil)", changed, err)
+	}
+	if got, want := r.Version(), int64(3); got != want {
+		t.Errorf("Version()=%d, want %d", got, want)
+	}
+}
+
+func TestRootsManifestErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeRoots(t, filepath.Join(dir, "fake-ca.pem"), fakeCARootPath)
+
+	for _, tc := range []struct {
+		desc     string
+		manifest string
+		wantErr  string
+	}{
+		{
+			desc:     "empty",
+			manifest: "",
+			wantErr:  "empty roots manifest",
+		},
+		{
+			desc:     "no-root",
+			manifest: "roots: []",
+			wantErr:  "no root in roots manifest",
+		},
+		{
+			desc:     "unknown-field",
+			manifest: "roots:\n  - pem_file: fake-ca.pem\n    accept_after: 2025-01-01T00:00:00Z\n",
+			wantErr:  "field accept_after not found",
+		},
+		{
+			desc:     "no-pem",
+			manifest: "roots:\n  - labels: [program1]\n",
+			wantErr:  "one of pem or pem_file must be set",
+		},
+		{
+			desc:     "pem-and-pem-file",
+			manifest: "roots:\n  - pem_file: fake-ca.pem\n    pem: foo\n",
+			wantErr:  "only one of pem and pem_file can be set",
+		},
+		{
+			desc:     "missing-pem-file",
+			manifest: "roots:\n  - pem_file: missing.pem\n",
+			wantErr:  "failed to read PEM file",
+		},
+		{
+			desc:     "invalid-pem",
+			manifest: "roots:\n  - pem: foo\n",
+			wantErr:  "failed to parse PEM certs",
+		},
+		{
+			desc:     "empty-window",
+			manifest: "roots:\n  - pem_file: fake-ca.pem\n    accept_from: 2025-01-01T00:00:00Z\n    accept_until: 2025-01-01T00:00:00Z\n",
+			wantErr:  "is not after accept_from",
+		},
+		{
+			desc:     "non-utc",
+			manifest: "roots:\n  - pem_file: fake-ca.pem\n    accept_from: 2025-01-01T00:00:00+01:00\n",
+			wantErr:  "accept_from must be in UTC",
+		},
+		{
+			desc:     "duplicate-root",
+			manifest: "roots:\n  - pem_file: fake-ca.pem\n  - pem_file: fake-ca.pem\n    accept_from: 2025-01-01T00:00:00Z\n",
+			wantErr:  "is already listed in roots[0]",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := LoadRootsManifest(origin, writeManifest(t, dir, tc.manifest))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadRootsManifest()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// indent prefixes every non-empty line of s with prefix.
+func indent(s, prefix string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mustParsePEMFile parses the first certificate in a PEM file.
+func mustParsePEMFile(t *testing.T, path string) *x509.Certificate {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %v", path, err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		t.Fatalf("No PEM block in %q", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificate(): %v", err)
+	}
+	return cert
+}
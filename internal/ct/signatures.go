@@ -15,12 +15,15 @@
 package ct
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	tfl "github.com/transparency-dev/formats/log"
@@ -31,8 +34,33 @@ import (
 
 const nanosPerMilli int64 = int64(time.Millisecond / time.Nanosecond)
 
+// minRSAKeyBits is the minimum size of RSA log keys. RSA signatures are
+// RSASSA-PKCS1-v1_5 over SHA-256, as per RFC 6962 and RFC 5246.
+const minRSAKeyBits = 2048
+
 type sctSigner struct {
 	signer crypto.Signer
+	// deterministic controls whether signatures are requested without a
+	// source of randomness, such that they are deterministic.
+	deterministic bool
+}
+
+// checkDeterministic checks that signer produces deterministic signatures
+// when its source of randomness is nil.
+func checkDeterministic(signer crypto.Signer) error {
+	digest := sha256.Sum256([]byte("TesseraCT deterministic signer check"))
+	var sigs [2][]byte
+	for i := range sigs {
+		sig, err := signer.Sign(nil, digest[:], crypto.SHA256)
+		if err != nil {
+			return fmt.Errorf("signer doesn't support deterministic signatures: %v", err)
+		}
+		sigs[i] = sig
+	}
+	if !bytes.Equal(sigs[0], sigs[1]) {
+		return errors.New("signer doesn't produce deterministic signatures")
+	}
+	return nil
 }
 
 // serializeSCTSignatureInput serializes the passed in sct and log entry into
@@ -77,7 +105,12 @@ func (sctSigner *sctSigner) Sign(leaf *rfc6962.MerkleTreeLeaf) (*rfc6962.SignedC
 	}
 
 	h := sha256.Sum256(data)
-	signature, err := sctSigner.signer.Sign(rand.Reader, h[:], crypto.SHA256)
+	// A nil source of randomness requests a deterministic signature.
+	var r io.Reader = rand.Reader
+	if sctSigner.deterministic {
+		r = nil
+	}
+	signature, err := sctSigner.signer.Sign(r, h[:], crypto.SHA256)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign SCT data: %v", err)
 	}
@@ -16,7 +16,9 @@ package ct
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/hex"
@@ -25,10 +27,13 @@ import (
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
+	tfl "github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/tesseract/internal/client"
 	"github.com/transparency-dev/tesseract/internal/testdata"
 	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
 	"github.com/transparency-dev/tesseract/internal/types/tls"
 	"github.com/transparency-dev/tesseract/internal/x509util"
+	"golang.org/x/mod/sumdb/note"
 )
 
 var (
@@ -399,58 +404,203 @@ func setupSCTSigner(fakeSig []byte) (*sctSigner, error) {
 		return nil, err
 	}
 
-	return &sctSigner{testdata.NewSignerWithFixedSig(key, fakeSig)}, nil
+	return &sctSigner{signer: testdata.NewSignerWithFixedSig(key, fakeSig)}, nil
 }
 
 func TestBuildCp(t *testing.T) {
-	// Create a test signer.
-	ecdsaSigner, err := loadPEMPrivateKey("../testdata/test_ct_server_ecdsa_private_key.pem")
-	if err != nil {
-		t.Fatalf("Can't open key: %v", err)
-	}
-
-	// Define test data.
-	size := uint64(12345)
-	hash := []byte("test_hash_value_12345678901234567890")
-
-	// Build the checkpoint which is in the RFC6962NoteSignature format.
-	checkpoint, err := buildCp(ecdsaSigner, size, fixedTimeMillis, hash)
-	if err != nil {
-		t.Errorf("buildCp failed: %v", err)
+	for _, tc := range []struct {
+		desc    string
+		keyPath string
+		wantAlg tls.SignatureAlgorithm
+	}{
+		{
+			desc:    "ecdsa",
+			keyPath: "../testdata/test_ct_server_ecdsa_private_key.pem",
+			wantAlg: tls.ECDSA,
+		},
+		{
+			desc:    "rsa",
+			keyPath: "../testdata/test_ct_server_rsa_private_key.pem",
+			wantAlg: tls.RSA,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			// Create a test signer.
+			signer, err := loadPEMPrivateKey(tc.keyPath)
+			if err != nil {
+				t.Fatalf("Can't open key: %v", err)
+			}
+
+			// Define test data.
+			size := uint64(12345)
+			hash := []byte("test_hash_value_12345678901234567890")
+
+			// Build the checkpoint which is in the RFC6962NoteSignature format.
+			checkpoint, err := buildCp(signer, size, fixedTimeMillis, hash)
+			if err != nil {
+				t.Errorf("buildCp failed: %v", err)
+			}
+
+			// Verify whether the checkpoint is empty.
+			if len(checkpoint) == 0 {
+				t.Errorf("buildCp returned an empty checkpoint")
+			}
+
+			// Verify that the checkpoint can be parsed.
+			var sig rfc6962NoteSignature
+			_, err = tls.Unmarshal(checkpoint, &sig)
+			if err != nil {
+				t.Errorf("failed to unmarshal checkpoint: %v", err)
+			}
+			// Verify the timestamp in the note signature.
+			if sig.Timestamp != fixedTimeMillis {
+				t.Errorf("buildCp returned wrong timestamp, got %d, want %d", sig.Timestamp, fixedTimeMillis)
+			}
+			if got, want := sig.Signature.Algorithm.Signature, tc.wantAlg; got != want {
+				t.Errorf("buildCp returned wrong signature algorithm, got %v, want %v", got, want)
+			}
+
+			// Verify the signature using the public key.
+			sth := rfc6962.SignedTreeHead{
+				Version:   rfc6962.V1,
+				TreeSize:  size,
+				Timestamp: fixedTimeMillis,
+			}
+			copy(sth.SHA256RootHash[:], hash)
+
+			sthBytes, err := serializeSTHSignatureInput(sth)
+			if err != nil {
+				t.Fatalf("serializeSTHSignatureInput(): %v", err)
+			}
+
+			h := sha256.Sum256(sthBytes)
+			var valid bool
+			switch pub := signer.Public().(type) {
+			case *ecdsa.PublicKey:
+				valid = ecdsa.VerifyASN1(pub, h[:], sig.Signature.Signature)
+			case *rsa.PublicKey:
+				valid = rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig.Signature.Signature) == nil
+			}
+			if !valid {
+				t.Errorf("buildCp returned an invalid signature")
+			}
+		})
 	}
+}
 
-	// Verify whether the checkpoint is empty.
-	if len(checkpoint) == 0 {
-		t.Errorf("buildCp returned an empty checkpoint")
+func TestCpSignerVerifies(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		keyPath string
+	}{
+		{desc: "ecdsa", keyPath: "../testdata/test_ct_server_ecdsa_private_key.pem"},
+		{desc: "rsa", keyPath: "../testdata/test_ct_server_rsa_private_key.pem"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			signer, err := loadPEMPrivateKey(tc.keyPath)
+			if err != nil {
+				t.Fatalf("Can't open key: %v", err)
+			}
+			origin := "example.com/log"
+			cpSigner, err := NewCpSigner(signer, origin, newFakeTimeSource(fixedTime))
+			if err != nil {
+				t.Fatalf("NewCpSigner(): %v", err)
+			}
+			verifier, err := client.NewRFC6962Verifier(origin, signer.Public())
+			if err != nil {
+				t.Fatalf("NewRFC6962Verifier(): %v", err)
+			}
+			if got, want := cpSigner.KeyHash(), verifier.KeyHash(); got != want {
+				t.Errorf("Got key hash %08x, want %08x", got, want)
+			}
+
+			cp := tfl.Checkpoint{Origin: origin, Size: 12345, Hash: make([]byte, sha256.Size)}
+			msg, err := note.Sign(&note.Note{Text: string(cp.Marshal())}, cpSigner)
+			if err != nil {
+				t.Fatalf("note.Sign(): %v", err)
+			}
+			if _, err := note.Open(msg, note.VerifierList(verifier)); err != nil {
+				t.Errorf("note.Open(): %v", err)
+			}
+		})
 	}
+}
 
-	// Verify that the checkpoint can be parsed.
-	var sig rfc6962NoteSignature
-	_, err = tls.Unmarshal(checkpoint, &sig)
+func TestSignSCTDeterministic(t *testing.T) {
+	cert, err := x509util.CertificateFromPEM([]byte(testdata.LeafSignedByFakeIntermediateCertPEM))
 	if err != nil {
-		t.Errorf("failed to unmarshal checkpoint: %v", err)
-	}
-	// Verify the timestamp in the note signature.
-	if sig.Timestamp != fixedTimeMillis {
-		t.Errorf("buildCp returned wrong timestamp, got %d, want %d", sig.Timestamp, fixedTimeMillis)
-	}
-
-	// Verify the signature using the public key.
-	sth := rfc6962.SignedTreeHead{
-		Version:   rfc6962.V1,
-		TreeSize:  size,
-		Timestamp: fixedTimeMillis,
+		t.Fatalf("failed to set up test cert: %v", err)
 	}
-	copy(sth.SHA256RootHash[:], hash)
-
-	sthBytes, err := serializeSTHSignatureInput(sth)
+	entry, err := x509util.EntryFromChain([]*x509.Certificate{cert}, false, fixedTimeMillis)
 	if err != nil {
-		t.Fatalf("serializeSTHSignatureInput(): %v", err)
+		t.Fatalf("EntryFromChain(): %v", err)
+	}
+	var leaf rfc6962.MerkleTreeLeaf
+	if _, err := tls.Unmarshal(entry.MerkleTreeLeaf(uint64(fakeIndex)), &leaf); err != nil {
+		t.Fatalf("failed to reconstruct MerkleTreeLeaf: %s", err)
 	}
 
-	h := sha256.Sum256(sthBytes)
-	valid := ecdsa.VerifyASN1(ecdsaSigner.Public().(*ecdsa.PublicKey), h[:], sig.Signature.Signature)
-	if !valid {
-		t.Errorf("buildCp returned an invalid signature")
+	for _, tc := range []struct {
+		desc          string
+		keyPath       string
+		deterministic bool
+		wantIdentical bool
+	}{
+		{
+			desc:          "ecdsa-deterministic",
+			keyPath:       "../testdata/test_ct_server_ecdsa_private_key.pem",
+			deterministic: true,
+			wantIdentical: true,
+		},
+		{
+			desc:          "ecdsa-randomized",
+			keyPath:       "../testdata/test_ct_server_ecdsa_private_key.pem",
+			wantIdentical: false,
+		},
+		{
+			desc:          "rsa-deterministic",
+			keyPath:       "../testdata/test_ct_server_rsa_private_key.pem",
+			deterministic: true,
+			wantIdentical: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			signer, err := loadPEMPrivateKey(tc.keyPath)
+			if err != nil {
+				t.Fatalf("Can't open key: %v", err)
+			}
+			s := &sctSigner{signer: signer, deterministic: tc.deterministic}
+
+			var scts [2][]byte
+			for i := range scts {
+				sct, err := s.Sign(&leaf)
+				if err != nil {
+					t.Fatalf("Sign(): %v", err)
+				}
+				if scts[i], err = tls.Marshal(*sct); err != nil {
+					t.Fatalf("tls.Marshal(): %v", err)
+				}
+
+				// Check that the SCT verifies with the standard library.
+				data, err := serializeSCTSignatureInput(*sct, rfc6962.LogEntry{Leaf: leaf})
+				if err != nil {
+					t.Fatalf("serializeSCTSignatureInput(): %v", err)
+				}
+				h := sha256.Sum256(data)
+				var valid bool
+				switch pub := signer.Public().(type) {
+				case *ecdsa.PublicKey:
+					valid = ecdsa.VerifyASN1(pub, h[:], sct.Signature.Signature)
+				case *rsa.PublicKey:
+					valid = rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sct.Signature.Signature) == nil
+				}
+				if !valid {
+					t.Errorf("SCT signature doesn't verify")
+				}
+			}
+			if got := bytes.Equal(scts[0], scts[1]); got != tc.wantIdentical {
+				t.Errorf("Got identical SCTs %t, want %t", got, tc.wantIdentical)
+			}
+		})
 	}
 }
@@ -0,0 +1,114 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/transparency-dev/tesseract/internal/x509util"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Constants for validate-chain entrypoint names, as exposed in statistics/logging.
+const (
+	validateChainName = entrypointName("ValidateChain")
+)
+
+// validateChainPath is the validate-chain URI path, relative to the log
+// prefix. This endpoint is not part of RFC 6962.
+const validateChainPath = "/ct/v1/validate-chain"
+
+// validateChainEntrypoints is a list of validate-chain entrypoint names as exposed in statistics/logging.
+var validateChainEntrypoints = []entrypointName{validateChainName}
+
+// Entry types returned by validate-chain.
+const (
+	x509EntryType    = "x509_entry"
+	precertEntryType = "precert_entry"
+)
+
+// validateChainResponse is the JSON response to a validate-chain request.
+type validateChainResponse struct {
+	// Valid is true if the chain would be accepted by add-chain or
+	// add-pre-chain, depending on EntryType.
+	Valid bool `json:"valid"`
+	// EntryType is the type of entry the chain would be logged as, if it
+	// could be determined.
+	EntryType string `json:"entry_type,omitempty"`
+	// Path is the verified path from the leaf to an accepted root, if the
+	// chain is valid.
+	Path [][]byte `json:"path,omitempty"`
+	// Checks lists the result of each check run on the chain.
+	Checks []CheckResult `json:"checks"`
+}
+
+// NewValidateChainPathHandlers returns handlers validating chains without
+// logging them.
+//
+// POST <prefix>/ct/v1/validate-chain takes the same body as add-chain and
+// add-pre-chain, and returns the verified path of the chain, the type of
+// entry it would be logged as, and the result of each validation check.
+func NewValidateChainPathHandlers(ctx context.Context, opts *HandlerOptions, log *log) pathHandlers {
+	once.Do(func() { setupMetrics() })
+
+	prefix := logPrefix(log.origin)
+
+	return pathHandlers{
+		prefix + validateChainPath: appHandler{opts: opts, log: log, handler: validateChain, name: validateChainName, method: http.MethodPost},
+	}
+}
+
+func validateChain(ctx context.Context, opts *HandlerOptions, log *log, w http.ResponseWriter, r *http.Request) (int, []attribute.KeyValue, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.validateChain")
+	defer span.End()
+
+	req, err := parseBodyAsJSONChain(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, fmt.Errorf("%s: failed to parse validate-chain body: %s", log.origin, err)
+	}
+	for _, der := range req.Chain {
+		opts.RequestLog.AddDERToChain(ctx, der)
+	}
+
+	report := log.chainValidator.Check(req.Chain)
+	rsp := validateChainResponse{Checks: report.Checks}
+	if report.Precert == nil {
+		return writeJSONResponse(w, rsp)
+	}
+	isPrecert := *report.Precert
+	rsp.EntryType = x509EntryType
+	if isPrecert {
+		rsp.EntryType = precertEntryType
+	}
+
+	// Build the entry add-chain or add-pre-chain would log, stopping short
+	// of storing anything.
+	chain := report.Path
+	if chain == nil {
+		return writeJSONResponse(w, rsp)
+	}
+	_, err = x509util.EntryFromChain(chain, isPrecert, uint64(opts.TimeSource.Now().UnixMilli()))
+	rsp.Checks = append(rsp.Checks, newCheckResult("entry", err))
+	if err != nil {
+		return writeJSONResponse(w, rsp)
+	}
+	rsp.Valid = true
+	for _, cert := range chain {
+		rsp.Path = append(rsp.Path, cert.Raw)
+	}
+	return writeJSONResponse(w, rsp)
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/transparency-dev/tessera/ctonly"
+	"github.com/transparency-dev/tesseract/internal/testdata"
+)
+
+func TestValidateChainHandler(t *testing.T) {
+	log, _ := setupTestLog(t)
+	mux := http.NewServeMux()
+	handlers := NewValidateChainPathHandlers(t.Context(), &hOpts, log)
+	if got, want := len(handlers), len(validateChainEntrypoints); got != want {
+		t.Fatalf("len(handlers)=%d; want %d", got, want)
+	}
+	for p, h := range handlers {
+		mux.Handle(p, h)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for _, test := range []struct {
+		descr         string
+		chain         []string
+		wantValid     bool
+		wantEntryType string
+		wantPathLen   int
+		wantFailed    map[string]RejectionReason
+	}{
+		{
+			descr:         "cert",
+			chain:         []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM},
+			wantValid:     true,
+			wantEntryType: x509EntryType,
+			wantPathLen:   3,
+		},
+		{
+			descr:         "cert-without-root",
+			chain:         []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot},
+			wantValid:     true,
+			wantEntryType: x509EntryType,
+			wantPathLen:   3,
+		},
+		{
+			descr:         "precert",
+			chain:         []string{testdata.PrecertPEMValid, testdata.CACertPEM},
+			wantValid:     true,
+			wantEntryType: precertEntryType,
+			wantPathLen:   2,
+		},
+		{
+			descr:         "leaf-only",
+			chain:         []string{testdata.CertFromIntermediate},
+			wantEntryType: x509EntryType,
+			wantFailed:    map[string]RejectionReason{"path_to_root": ReasonUnknownRoot},
+		},
+		{
+			descr:         "leaf-signed-by-different",
+			chain:         []string{testdata.PrecertPEMValid, testdata.FakeIntermediateCertPEM},
+			wantEntryType: precertEntryType,
+			wantFailed:    map[string]RejectionReason{"chain_order": ReasonOutOfOrderChain},
+		},
+	} {
+		t.Run(test.descr, func(t *testing.T) {
+			resp, err := http.Post(server.URL+prefix+validateChainPath, "application/json", createJSONChain(t, *loadCertsIntoPoolOrDie(t, test.chain)))
+			if err != nil {
+				t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", validateChainPath, err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if got, want := resp.StatusCode, http.StatusOK; got != want {
+				t.Fatalf("http.Post(%s)=(%d,nil); want (%d,nil)", validateChainPath, got, want)
+			}
+			var rsp validateChainResponse
+			if err := json.NewDecoder(resp.Body).Decode(&rsp); err != nil {
+				t.Fatalf("json.Decode()=%v; want nil", err)
+			}
+
+			if got, want := rsp.Valid, test.wantValid; got != want {
+				t.Errorf("valid=%t; want %t", got, want)
+			}
+			if got, want := rsp.EntryType, test.wantEntryType; got != want {
+				t.Errorf("entry_type=%q; want %q", got, want)
+			}
+			if got, want := len(rsp.Path), test.wantPathLen; got != want {
+				t.Errorf("len(path)=%d; want %d", got, want)
+			}
+			failed := map[string]RejectionReason{}
+			for _, c := range rsp.Checks {
+				if !c.Passed {
+					failed[c.Name] = c.Reason
+				}
+			}
+			if diff := cmp.Diff(test.wantFailed, failed, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Failed checks mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid-body", func(t *testing.T) {
+		resp, err := http.Post(server.URL+prefix+validateChainPath, "application/json", strings.NewReader(`{"chain": "not a chain"}`))
+		if err != nil {
+			t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", validateChainPath, err)
+		}
+		_ = resp.Body.Close()
+		if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Errorf("http.Post(%s)=(%d,nil); want (%d,nil)", validateChainPath, got, want)
+		}
+	})
+}
+
+// recordingStorage is a Storage counting the calls storing data.
+type recordingStorage struct {
+	Storage
+	adds, issuerAdds int
+}
+
+func (s *recordingStorage) Add(ctx context.Context, e *ctonly.Entry) (uint64, uint64, error) {
+	s.adds++
+	return s.Storage.Add(ctx, e)
+}
+
+func (s *recordingStorage) AddIssuerChain(ctx context.Context, chain []*x509.Certificate) error {
+	s.issuerAdds++
+	return s.Storage.AddIssuerChain(ctx, chain)
+}
+
+func TestValidateChainHandlerStoresNothing(t *testing.T) {
+	log, _ := setupTestLog(t)
+	s := &recordingStorage{Storage: log.storage}
+	log.storage = s
+	knownIssuers := NewIssuerPool()
+	cv := log.chainValidator.(chainValidator)
+	cv.knownIssuers = knownIssuers
+	log.chainValidator = cv
+
+	mux := http.NewServeMux()
+	for p, h := range NewValidateChainPathHandlers(t.Context(), &hOpts, log) {
+		mux.Handle(p, h)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	chain := []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot, testdata.CACertPEM}
+	resp, err := http.Post(server.URL+prefix+validateChainPath, "application/json", createJSONChain(t, *loadCertsIntoPoolOrDie(t, chain)))
+	if err != nil {
+		t.Fatalf("http.Post(%s)=(_,%q); want (_,nil)", validateChainPath, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var rsp validateChainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rsp); err != nil {
+		t.Fatalf("json.Decode()=%v; want nil", err)
+	}
+	if !rsp.Valid {
+		t.Fatalf("valid=false; want true, checks: %+v", rsp.Checks)
+	}
+
+	if s.adds != 0 || s.issuerAdds != 0 {
+		t.Errorf("Storage called %d times to add entries and %d times to add issuers; want 0", s.adds, s.issuerAdds)
+	}
+	if got := knownIssuers.Len(); got != 0 {
+		t.Errorf("Known issuers after validate-chain: %d; want 0", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// minLeafRSAKeyBits is the minimum size of the RSA keys of submitted leaves
+// which are not considered weak, as per the CA/Browser Forum Baseline
+// Requirements. It is distinct from minRSAKeyBits, which applies to the keys
+// of the log itself, and may change independently.
+const minLeafRSAKeyBits = 2048
+
+// keyWeakness identifies why a key is weak.
+type keyWeakness string
+
+// Key weaknesses, as returned by (*WeakKeyDetector).detect.
+const (
+	weaknessSmallRSAKey   keyWeakness = "small_rsa_key"
+	weaknessDebianWeakKey keyWeakness = "debian_weak_key"
+	weaknessROCA          keyWeakness = "roca"
+	weaknessCompromised   keyWeakness = "compromised_key"
+)
+
+// rocaPrimes are the small primes used to fingerprint RSA moduli generated
+// by the Infineon library vulnerable to ROCA (CVE-2017-15361).
+var rocaPrimes = []int64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71, 73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149, 151, 157, 163, 167}
+
+// rocaResidues holds, for each of rocaPrimes, the residues of the powers of
+// 65537 modulo this prime. Vulnerable moduli are powers of 65537 modulo the
+// product of the primes, and therefore modulo each of them.
+var rocaResidues = func() [][]bool {
+	residues := make([][]bool, len(rocaPrimes))
+	for i, p := range rocaPrimes {
+		residues[i] = make([]bool, p)
+		for r := int64(1); !residues[i][r]; r = r * 65537 % p {
+			residues[i][r] = true
+		}
+	}
+	return residues
+}()
+
+// isROCAModulus returns whether n has the fingerprint of moduli vulnerable
+// to ROCA.
+func isROCAModulus(n *big.Int) bool {
+	m := new(big.Int)
+	for i, p := range rocaPrimes {
+		if !rocaResidues[i][m.Mod(n, big.NewInt(p)).Int64()] {
+			return false
+		}
+	}
+	return true
+}
+
+// debianWeakKeyFingerprint returns the fingerprint identifying n in the
+// blocklists of the Debian openssl-blacklist package: the last 20 hex
+// characters of the SHA-1 of its OpenSSL "Modulus=" line.
+func debianWeakKeyFingerprint(n *big.Int) string {
+	h := sha1.Sum([]byte("Modulus=" + strings.ToUpper(n.Text(16)) + "\n"))
+	return hex.EncodeToString(h[:])[20:]
+}
+
+// WeakKeyDetector detects weak and compromised keys in certificates: RSA keys
+// shorter than 2048 bits, RSA moduli vulnerable to ROCA, and keys listed in
+// blocklists.
+type WeakKeyDetector struct {
+	// reject indicates that certificates with weak keys are rejected, and
+	// not only counted.
+	reject bool
+	// debianWeakKeys holds the fingerprints of the RSA moduli generated by
+	// the vulnerable Debian OpenSSL package, see debianWeakKeyFingerprint.
+	debianWeakKeys map[string]bool
+	// compromisedKeys holds the SHA-256 of the SubjectPublicKeyInfo of
+	// compromised keys.
+	compromisedKeys map[[sha256.Size]byte]bool
+}
+
+// NewWeakKeyDetector returns a WeakKeyDetector, which rejects certificates
+// with weak keys if reject is true.
+//
+// debianWeakKeysFile, if not empty, is the path to a blocklist in the format
+// of the Debian openssl-blacklist package, with one fingerprint per line.
+// compromisedKeysFile, if not empty, is the path to a file with the hex
+// encoded SHA-256 of a SubjectPublicKeyInfo per line. Empty lines and lines
+// starting with '#' are ignored in both files.
+func NewWeakKeyDetector(reject bool, debianWeakKeysFile, compromisedKeysFile string) (*WeakKeyDetector, error) {
+	d := &WeakKeyDetector{
+		reject:          reject,
+		debianWeakKeys:  make(map[string]bool),
+		compromisedKeys: make(map[[sha256.Size]byte]bool),
+	}
+	if debianWeakKeysFile != "" {
+		lines, err := readBlocklist(debianWeakKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Debian weak keys: %v", err)
+		}
+		for _, l := range lines {
+			if _, err := hex.DecodeString(l); err != nil || len(l) != 20 {
+				return nil, fmt.Errorf("invalid Debian weak key fingerprint %q", l)
+			}
+			d.debianWeakKeys[strings.ToLower(l)] = true
+		}
+	}
+	if compromisedKeysFile != "" {
+		lines, err := readBlocklist(compromisedKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compromised keys: %v", err)
+		}
+		for _, l := range lines {
+			h, err := hex.DecodeString(l)
+			if err != nil || len(h) != sha256.Size {
+				return nil, fmt.Errorf("invalid compromised key hash %q", l)
+			}
+			d.compromisedKeys[[sha256.Size]byte(h)] = true
+		}
+	}
+	return d, nil
+}
+
+// readBlocklist returns the entries of the blocklist file at path, skipping
+// empty lines and comments.
+func readBlocklist(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines, s.Err()
+}
+
+// detect returns why the key of cert is weak, or an empty string if it
+// isn't known to be. A nil detector doesn't detect anything.
+func (d *WeakKeyDetector) detect(cert *x509.Certificate) keyWeakness {
+	if d == nil {
+		return ""
+	}
+	if d.compromisedKeys[sha256.Sum256(cert.RawSubjectPublicKeyInfo)] {
+		return weaknessCompromised
+	}
+	k, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ""
+	}
+	switch {
+	case k.N.BitLen() < minLeafRSAKeyBits:
+		return weaknessSmallRSAKey
+	case d.debianWeakKeys[debianWeakKeyFingerprint(k.N)]:
+		return weaknessDebianWeakKey
+	case isROCAModulus(k.N):
+		return weaknessROCA
+	}
+	return ""
+}
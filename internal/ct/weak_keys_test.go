@@ -0,0 +1,240 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ct
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/transparency-dev/tesseract/internal/testdata"
+	"github.com/transparency-dev/tesseract/internal/types/rfc6962"
+	"github.com/transparency-dev/tesseract/internal/x509util"
+)
+
+// rocaModulus returns a 2048 bit number with the ROCA fingerprint.
+func rocaModulus(t *testing.T) *big.Int {
+	t.Helper()
+	m := big.NewInt(1)
+	for _, p := range rocaPrimes {
+		m.Mul(m, big.NewInt(p))
+	}
+	// k*m is in [2^2047, 2^2048).
+	k, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 2046-uint(m.BitLen())))
+	if err != nil {
+		t.Fatalf("rand.Int(): %v", err)
+	}
+	k.Add(k, new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 2047), m))
+	k.Add(k, big.NewInt(1))
+	n := new(big.Int).Exp(big.NewInt(65537), big.NewInt(1234), m)
+	return n.Add(n, k.Mul(k, m))
+}
+
+// certWithKey returns a certificate for pub, signed by a throwaway key.
+func certWithKey(t *testing.T, pub crypto.PublicKey) *x509.Certificate {
+	t.Helper()
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "weak.example.com"},
+		NotBefore:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, signer)
+	if err != nil {
+		t.Fatalf("CreateCertificate(): %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate(): %v", err)
+	}
+	return cert
+}
+
+// writeBlocklist writes lines to a file in a temporary directory, and
+// returns its path.
+func writeBlocklist(t *testing.T, lines ...string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "blocklist")
+	if err := os.WriteFile(p, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatalf("WriteFile(%q): %v", p, err)
+	}
+	return p
+}
+
+func TestIsROCAModulus(t *testing.T) {
+	if n := rocaModulus(t); !isROCAModulus(n) {
+		t.Errorf("isROCAModulus(%x)=false, want true", n)
+	}
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	if isROCAModulus(k.N) {
+		t.Errorf("isROCAModulus(%x)=true, want false", k.N)
+	}
+}
+
+func TestWeakKeyDetector(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	debianKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	compromised := certWithKey(t, ecKey.Public())
+	compromisedHash := sha256.Sum256(compromised.RawSubjectPublicKeyInfo)
+
+	d, err := NewWeakKeyDetector(true,
+		writeBlocklist(t, "# Debian weak keys", strings.ToUpper(debianWeakKeyFingerprint(debianKey.N)), ""),
+		writeBlocklist(t, "# Compromised keys", hex.EncodeToString(compromisedHash[:])))
+	if err != nil {
+		t.Fatalf("NewWeakKeyDetector()=%v", err)
+	}
+
+	for _, test := range []struct {
+		desc string
+		cert *x509.Certificate
+		want keyWeakness
+	}{
+		{desc: "rsa", cert: certWithKey(t, rsaKey.Public())},
+		{desc: "ecdsa", cert: pemToCert(t, testdata.CertFromIntermediate)},
+		{desc: "small-rsa", cert: certWithKey(t, smallKey.Public()), want: weaknessSmallRSAKey},
+		{desc: "debian", cert: certWithKey(t, debianKey.Public()), want: weaknessDebianWeakKey},
+		{desc: "roca", cert: certWithKey(t, &rsa.PublicKey{N: rocaModulus(t), E: 65537}), want: weaknessROCA},
+		{desc: "compromised", cert: compromised, want: weaknessCompromised},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := d.detect(test.cert); got != test.want {
+				t.Errorf("detect()=%q, want %q", got, test.want)
+			}
+		})
+	}
+
+	var nilDetector *WeakKeyDetector
+	if got := nilDetector.detect(certWithKey(t, smallKey.Public())); got != "" {
+		t.Errorf("nil detect()=%q, want none", got)
+	}
+}
+
+func TestNewWeakKeyDetectorErrors(t *testing.T) {
+	for _, test := range []struct {
+		desc         string
+		debian       string
+		compromised  string
+		wantErrMatch string
+	}{
+		{
+			desc:         "missing-debian-file",
+			debian:       filepath.Join(t.TempDir(), "missing"),
+			wantErrMatch: "failed to read Debian weak keys",
+		},
+		{
+			desc:         "invalid-debian-fingerprint",
+			debian:       writeBlocklist(t, "0123456789abcdef"),
+			wantErrMatch: "invalid Debian weak key fingerprint",
+		},
+		{
+			desc:         "missing-compromised-file",
+			compromised:  filepath.Join(t.TempDir(), "missing"),
+			wantErrMatch: "failed to read compromised keys",
+		},
+		{
+			desc:         "invalid-compromised-hash",
+			compromised:  writeBlocklist(t, "not hex"),
+			wantErrMatch: "invalid compromised key hash",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := NewWeakKeyDetector(false, test.debian, test.compromised)
+			if err == nil || !strings.Contains(err.Error(), test.wantErrMatch) {
+				t.Errorf("NewWeakKeyDetector()=%v, want error containing %q", err, test.wantErrMatch)
+			}
+		})
+	}
+}
+
+func TestValidateWeakKey(t *testing.T) {
+	roots := x509util.NewPEMCertPool()
+	if !roots.AppendCertsFromPEM([]byte(testdata.CACertPEM)) {
+		t.Fatal("failed to load root")
+	}
+	leaf := pemToCert(t, testdata.CertFromIntermediate)
+	leafHash := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+	blocklist := writeBlocklist(t, hex.EncodeToString(leafHash[:]))
+	chain := []string{testdata.CertFromIntermediate, testdata.IntermediateFromRoot}
+
+	for _, test := range []struct {
+		desc       string
+		chain      []string
+		reject     bool
+		wantReason RejectionReason
+	}{
+		{desc: "flag", chain: chain},
+		{desc: "reject", chain: chain, reject: true, wantReason: ReasonWeakKey},
+		// Keys of leaves which don't chain to an accepted root aren't
+		// checked.
+		{desc: "reject-unknown-root", chain: chain[:1], reject: true, wantReason: ReasonUnknownRoot},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			d, err := NewWeakKeyDetector(test.reject, "", blocklist)
+			if err != nil {
+				t.Fatalf("NewWeakKeyDetector()=%v", err)
+			}
+			cv := chainValidator{
+				trustedRoots: mustNewRoots(t, roots),
+				currentTime:  time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+				weakKeys:     d,
+			}
+			_, err = cv.Validate(rfc6962.AddChainRequest{Chain: pemsToDERChain(t, test.chain)}, false)
+			if test.wantReason == "" {
+				if err != nil {
+					t.Fatalf("Validate()=%v, want nil", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) || vErr.Reason != test.wantReason {
+				t.Errorf("Validate()=%v, want reason %q", err, test.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fsck checks the consistency of a https://c2sp.org/static-ct-api log.
+//
+// It walks all the entries of a log, recomputes its tiles and root hash from
+// its entry bundles, and checks them against the tiles and checkpoint served
+// by the log. It also checks that entries' leaf_index extensions match their
+// position in the log, and that all the issuers they reference are stored.
+package fsck
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/ctonly"
+	"github.com/transparency-dev/tesseract/internal/client"
+	"github.com/transparency-dev/tesseract/internal/types/staticct"
+	"golang.org/x/mod/sumdb/note"
+	"k8s.io/klog/v2"
+)
+
+// Fetcher reads https://c2sp.org/static-ct-api objects from a log.
+type Fetcher interface {
+	// ReadCheckpoint returns the latest checkpoint published by the log.
+	ReadCheckpoint(ctx context.Context) ([]byte, error)
+	// ReadTile returns the tile at the given level and index, with partial size p.
+	ReadTile(ctx context.Context, l, i uint64, p uint8) ([]byte, error)
+	// ReadEntryBundle returns the entry bundle at the given index, with partial size p.
+	ReadEntryBundle(ctx context.Context, i uint64, p uint8) ([]byte, error)
+	// ReadIssuer returns the issuer certificate stored under its hex encoded
+	// sha256, or an error wrapping os.ErrNotExist if there is no such issuer.
+	ReadIssuer(ctx context.Context, key []byte) ([]byte, error)
+}
+
+// Kind is the kind of problem a Finding is about.
+type Kind string
+
+const (
+	// KindEntryBundle is reported when an entry bundle can't be fetched or
+	// parsed, or doesn't hold the expected number of entries.
+	KindEntryBundle Kind = "entry_bundle"
+	// KindEntry is reported when an entry can't be parsed.
+	KindEntry Kind = "entry"
+	// KindLeafIndex is reported when the leaf_index extension of an entry
+	// doesn't match its position in the log.
+	KindLeafIndex Kind = "leaf_index"
+	// KindTile is reported when a tile can't be fetched, or doesn't match the
+	// one recomputed from entry bundles.
+	KindTile Kind = "tile"
+	// KindRootHash is reported when the root hash of the checkpoint doesn't
+	// match the one recomputed from entry bundles.
+	KindRootHash Kind = "root_hash"
+	// KindIssuer is reported when an issuer referenced by an entry can't be
+	// fetched, or doesn't match its fingerprint.
+	KindIssuer Kind = "issuer"
+)
+
+// Finding is a problem found in a log.
+type Finding struct {
+	Kind Kind `json:"kind"`
+	// Path is the path of the object the finding is about, relative to the
+	// root of the log.
+	Path string `json:"path,omitempty"`
+	// Entry is the index of the entry the finding is about, if any.
+	Entry *uint64 `json:"entry,omitempty"`
+	// Message describes the finding.
+	Message string `json:"message"`
+}
+
+// State records how far a log has been checked, so that checks can be resumed.
+type State struct {
+	// Next is the index of the next entry to check.
+	Next uint64 `json:"next"`
+	// Range holds the hashes of the compact range covering entries [0, Next).
+	Range [][]byte `json:"range"`
+	// Tiles holds, for each tile level, the hashes of the partial tile at the
+	// right edge of the tree of size Next.
+	Tiles [][][]byte `json:"tiles"`
+}
+
+// Checker checks the consistency of a log.
+type Checker struct {
+	f           Fetcher
+	origin      string
+	verifier    note.Verifier
+	parallelism uint
+	report      func(Finding)
+	// issuers holds the fingerprints of the issuers which have been checked
+	// already.
+	issuers map[[32]byte]struct{}
+}
+
+// NewChecker returns a Checker for the log with the given origin, whose
+// checkpoints are verified with v.
+//
+// Up to parallelism entry bundles are fetched concurrently. Findings are
+// passed to report as they are found.
+func NewChecker(f Fetcher, origin string, v note.Verifier, parallelism uint, report func(Finding)) *Checker {
+	return &Checker{
+		f:           f,
+		origin:      origin,
+		verifier:    v,
+		parallelism: max(parallelism, 1),
+		report:      report,
+		issuers:     make(map[[32]byte]struct{}),
+	}
+}
+
+// Check checks the log from st up to its latest checkpoint.
+//
+// st is updated as entries are checked, and passed to save, if not nil, after
+// each entry bundle. It is only consistent when passed to save, or when Check
+// returns without an error. A zero State checks the log from the beginning.
+//
+// Problems found in the log are reported as findings. An error is returned
+// when the log can't be checked any further.
+func (c *Checker) Check(ctx context.Context, st *State, save func(*State) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cp, _, _, err := client.FetchCheckpoint(ctx, c.f.ReadCheckpoint, c.verifier, c.origin)
+	if err != nil {
+		return fmt.Errorf("failed to fetch checkpoint: %v", err)
+	}
+	if st.Next > cp.Size {
+		return fmt.Errorf("state at entry %d is ahead of the checkpoint of size %d", st.Next, cp.Size)
+	}
+	klog.Infof("Checking entries [%d, %d)", st.Next, cp.Size)
+
+	rf := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+	r, err := rf.NewRange(0, st.Next, st.Range)
+	if err != nil {
+		return fmt.Errorf("invalid state: %v", err)
+	}
+
+	for rc := range c.fetchBundles(ctx, st.Next/layout.EntryBundleWidth, cp.Size) {
+		b := <-rc
+		bPath := entryBundlePath(b.index, layout.PartialTileSize(0, b.index, cp.Size))
+		if b.err != nil {
+			c.report(Finding{Kind: KindEntryBundle, Path: bPath, Message: b.err.Error()})
+			return fmt.Errorf("failed to fetch entry bundle %d: %v", b.index, b.err)
+		}
+		want := min(cp.Size-b.index*layout.EntryBundleWidth, layout.EntryBundleWidth)
+		if got := uint64(len(b.entries)); got != want {
+			c.report(Finding{Kind: KindEntryBundle, Path: bPath, Message: fmt.Sprintf("got %d entries, want %d", got, want)})
+			if got < want {
+				return fmt.Errorf("entry bundle %d is missing entries", b.index)
+			}
+		}
+
+		for i, raw := range b.entries[:want] {
+			idx := b.index*layout.EntryBundleWidth + uint64(i)
+			if idx < st.Next {
+				continue
+			}
+			h, err := c.checkEntry(ctx, idx, raw)
+			if err != nil {
+				c.report(Finding{Kind: KindEntry, Path: bPath, Entry: &idx, Message: err.Error()})
+				return fmt.Errorf("failed to check entry %d: %v", idx, err)
+			}
+			if err := r.Append(h, nil); err != nil {
+				return fmt.Errorf("failed to append entry %d to compact range: %v", idx, err)
+			}
+			c.addNode(ctx, st, 0, idx, h)
+			st.Next = idx + 1
+		}
+		st.Range = r.Hashes()
+
+		klog.V(2).Infof("Checked entry bundle %d", b.index)
+		if save != nil {
+			if err := save(st); err != nil {
+				return fmt.Errorf("failed to save state: %w", err)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Only the partial tiles on the right edge of the tree are left to check.
+	for l, hashes := range st.Tiles {
+		if len(hashes) > 0 {
+			n := cp.Size >> (layout.TileHeight * l)
+			c.checkTile(ctx, uint64(l), n/layout.TileWidth, uint8(len(hashes)), hashes)
+		}
+	}
+
+	root := rfc6962.DefaultHasher.EmptyRoot()
+	if cp.Size > 0 {
+		if root, err = r.GetRootHash(nil); err != nil {
+			return fmt.Errorf("failed to compute root hash: %v", err)
+		}
+	}
+	if !bytes.Equal(root, cp.Hash) {
+		c.report(Finding{Kind: KindRootHash, Path: layout.CheckpointPath, Message: fmt.Sprintf("checkpoint root hash is %x, recomputed %x at size %d", cp.Hash, root, cp.Size)})
+	}
+	return nil
+}
+
+// checkEntry checks an entry at index idx, and returns its Merkle leaf hash.
+func (c *Checker) checkEntry(ctx context.Context, idx uint64, raw []byte) ([]byte, error) {
+	e := staticct.Entry{}
+	if err := e.UnmarshalText(raw); err != nil {
+		return nil, fmt.Errorf("failed to parse entry: %v", err)
+	}
+	if e.LeafIndex != idx {
+		c.report(Finding{Kind: KindLeafIndex, Entry: &idx, Message: fmt.Sprintf("leaf_index extension is %d", e.LeafIndex)})
+	}
+	for _, fp := range e.FingerprintsChain {
+		c.checkIssuer(ctx, idx, fp)
+	}
+
+	ctEntry := ctonly.Entry{
+		Timestamp:      e.Timestamp,
+		IsPrecert:      e.IsPrecert,
+		Certificate:    e.Certificate,
+		Precertificate: e.Precertificate,
+		IssuerKeyHash:  e.IssuerKeyHash,
+	}
+	// Hash the entry as it appears in its bundle, leaf_index extension
+	// included, so that tiles are checked against what bundles hold.
+	return ctEntry.MerkleLeafHash(e.LeafIndex), nil
+}
+
+// checkIssuer checks that the issuer with fingerprint fp, referenced by the
+// entry at index idx, is stored.
+//
+// Each issuer is only checked once.
+func (c *Checker) checkIssuer(ctx context.Context, idx uint64, fp [32]byte) {
+	if _, ok := c.issuers[fp]; ok {
+		return
+	}
+	c.issuers[fp] = struct{}{}
+
+	key := hex.EncodeToString(fp[:])
+	path := "issuer/" + key
+	iss, err := c.f.ReadIssuer(ctx, []byte(key))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		c.report(Finding{Kind: KindIssuer, Path: path, Entry: &idx, Message: "issuer not found"})
+	case err != nil:
+		c.report(Finding{Kind: KindIssuer, Path: path, Entry: &idx, Message: fmt.Sprintf("failed to fetch issuer: %v", err)})
+	case sha256.Sum256(iss) != fp:
+		c.report(Finding{Kind: KindIssuer, Path: path, Entry: &idx, Message: "issuer doesn't match its fingerprint"})
+	}
+}
+
+// addNode adds the hash h of the n-th node at tile level l to st, and checks
+// tiles as they get full.
+func (c *Checker) addNode(ctx context.Context, st *State, l int, n uint64, h []byte) {
+	for len(st.Tiles) <= l {
+		st.Tiles = append(st.Tiles, nil)
+	}
+	st.Tiles[l] = append(st.Tiles[l], h)
+	if len(st.Tiles[l]) < layout.TileWidth {
+		return
+	}
+
+	tile := st.Tiles[l]
+	st.Tiles[l] = nil
+	c.checkTile(ctx, uint64(l), n/layout.TileWidth, 0, tile)
+	c.addNode(ctx, st, l+1, n/layout.TileWidth, subtreeRoot(tile))
+}
+
+// checkTile checks that the tile at level l and index i, with partial size p,
+// holds hashes.
+func (c *Checker) checkTile(ctx context.Context, l, i uint64, p uint8, hashes [][]byte) {
+	path := layout.TilePath(l, i, p)
+	tile, err := c.f.ReadTile(ctx, l, i, p)
+	if err != nil {
+		c.report(Finding{Kind: KindTile, Path: path, Message: fmt.Sprintf("failed to fetch tile: %v", err)})
+		return
+	}
+	if want := bytes.Join(hashes, nil); !bytes.Equal(tile, want) {
+		c.report(Finding{Kind: KindTile, Path: path, Message: "tile doesn't match entry bundles"})
+	}
+}
+
+// subtreeRoot returns the root hash of a perfect subtree with leaves hashes.
+func subtreeRoot(hashes [][]byte) []byte {
+	for len(hashes) > 1 {
+		next := make([][]byte, 0, len(hashes)/2)
+		for i := 0; i < len(hashes); i += 2 {
+			next = append(next, rfc6962.DefaultHasher.HashChildren(hashes[i], hashes[i+1]))
+		}
+		hashes = next
+	}
+	return hashes[0]
+}
+
+// bundleResult holds the outcome of fetching an entry bundle.
+type bundleResult struct {
+	index   uint64
+	entries [][]byte
+	err     error
+}
+
+// fetchBundles fetches the entry bundles of a log of the given size, starting
+// from the first one.
+//
+// Results are returned in order, each on its own channel, while up to
+// c.parallelism bundles are fetched concurrently.
+func (c *Checker) fetchBundles(ctx context.Context, first, size uint64) <-chan chan bundleResult {
+	out := make(chan chan bundleResult, c.parallelism)
+	go func() {
+		defer close(out)
+		for i := first; i*layout.EntryBundleWidth < size; i++ {
+			rc := make(chan bundleResult, 1)
+			select {
+			case out <- rc:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				b, err := client.GetEntryBundle(ctx, c.f.ReadEntryBundle, i, size)
+				rc <- bundleResult{index: i, entries: b.Entries, err: err}
+			}()
+		}
+	}()
+	return out
+}
+
+func entryBundlePath(i uint64, p uint8) string {
+	return fmt.Sprintf("tile/data/%s", layout.NWithSuffix(0, i, p))
+}
@@ -0,0 +1,352 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsck
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/ctonly"
+	posixTessera "github.com/transparency-dev/tessera/storage/posix"
+	"github.com/transparency-dev/tesseract/internal/client"
+	"github.com/transparency-dev/tesseract/internal/ct"
+	"github.com/transparency-dev/tesseract/internal/types/staticct"
+	"github.com/transparency-dev/tesseract/storage"
+	"github.com/transparency-dev/tesseract/storage/posix"
+	"golang.org/x/mod/sumdb/note"
+)
+
+const (
+	testOrigin = "example.com/fsck"
+	// testLogSize spans two full entry bundles and a partial one, and a
+	// partial level 1 tile.
+	testLogSize = 2*layout.EntryBundleWidth + 88
+)
+
+var testIssuers = [][]byte{[]byte("issuer 0"), []byte("issuer 1")}
+
+type systemTimeSource struct{}
+
+func (systemTimeSource) Now() time.Time {
+	return time.Now()
+}
+
+// newTestLog creates a POSIX log with size entries in dir, and returns a
+// verifier for its checkpoints.
+//
+// Entries alternate between referencing each of the testIssuers.
+func newTestLog(t *testing.T, dir string, size int) note.Verifier {
+	t.Helper()
+	ctx := t.Context()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer, err := ct.NewCpSigner(key, testOrigin, systemTimeSource{})
+	if err != nil {
+		t.Fatalf("NewCpSigner(): %v", err)
+	}
+	driver, err := posixTessera.New(ctx, dir)
+	if err != nil {
+		t.Fatalf("Failed to initialize POSIX Tessera storage driver: %v", err)
+	}
+	opts := tessera.NewAppendOptions().
+		WithCheckpointSigner(signer).
+		WithCTLayout().
+		WithCheckpointInterval(time.Second)
+	appender, shutdown, reader, err := tessera.NewAppender(ctx, driver, opts)
+	if err != nil {
+		t.Fatalf("Failed to initialize POSIX Tessera appender: %v", err)
+	}
+
+	issuerStorage, err := posix.NewIssuerStorage(filepath.Join(dir, "issuer"))
+	if err != nil {
+		t.Fatalf("Failed to initialize issuer storage: %v", err)
+	}
+	fps := [][32]byte{}
+	for _, iss := range testIssuers {
+		fp := sha256.Sum256(iss)
+		fps = append(fps, fp)
+		if err := issuerStorage.AddIssuersIfNotExist(ctx, []storage.KV{{K: []byte(hex.EncodeToString(fp[:])), V: iss}}); err != nil {
+			t.Fatalf("AddIssuersIfNotExist(): %v", err)
+		}
+	}
+
+	add := tessera.NewCertificateTransparencyAppender(appender)
+	futures := []tessera.IndexFuture{}
+	for i := range size {
+		futures = append(futures, add(ctx, &ctonly.Entry{
+			Timestamp:         uint64(i + 1),
+			Certificate:       fmt.Appendf(nil, "certificate %d", i),
+			FingerprintsChain: [][32]byte{fps[i%len(fps)]},
+		}))
+	}
+	awaiter := tessera.NewPublicationAwaiter(ctx, reader.ReadCheckpoint, 10*time.Millisecond)
+	for _, f := range futures {
+		if _, _, err := awaiter.Await(ctx, f); err != nil {
+			t.Fatalf("Await(): %v", err)
+		}
+	}
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown(): %v", err)
+	}
+
+	v, err := client.NewRFC6962Verifier(testOrigin, key.Public())
+	if err != nil {
+		t.Fatalf("NewRFC6962Verifier(): %v", err)
+	}
+	return v
+}
+
+// check runs a Checker from st, and returns the kinds of problems it found.
+func check(t *testing.T, dir string, v note.Verifier, st *State, save func(*State) error) ([]Kind, error) {
+	t.Helper()
+	kinds := []Kind{}
+	report := func(f Finding) {
+		t.Logf("Finding: %+v", f)
+		if !slices.Contains(kinds, f.Kind) {
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	c := NewChecker(client.FileFetcher{Root: dir}, testOrigin, v, 4, report)
+	err := c.Check(t.Context(), st, save)
+	slices.Sort(kinds)
+	return kinds, err
+}
+
+func TestCheck(t *testing.T) {
+	for _, size := range []int{0, 1, layout.EntryBundleWidth, testLogSize} {
+		t.Run(fmt.Sprintf("size-%d", size), func(t *testing.T) {
+			dir := t.TempDir()
+			v := newTestLog(t, dir, size)
+
+			st := &State{}
+			kinds, err := check(t, dir, v, st, nil)
+			if err != nil {
+				t.Fatalf("Check(): %v", err)
+			}
+			if len(kinds) > 0 {
+				t.Errorf("Check() found %v, want no findings", kinds)
+			}
+			if got, want := st.Next, uint64(size); got != want {
+				t.Errorf("Check() stopped at %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckResume(t *testing.T) {
+	dir := t.TempDir()
+	v := newTestLog(t, dir, testLogSize)
+
+	// Stop after the first entry bundle, saving the state as JSON.
+	var saved []byte
+	errStop := errors.New("stop")
+	st := &State{}
+	_, err := check(t, dir, v, st, func(st *State) error {
+		var err error
+		if saved, err = json.Marshal(st); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Check()=%v, want %v", err, errStop)
+	}
+
+	st = &State{}
+	if err := json.Unmarshal(saved, st); err != nil {
+		t.Fatalf("Failed to unmarshal state: %v", err)
+	}
+	if got, want := st.Next, uint64(layout.EntryBundleWidth); got != want {
+		t.Fatalf("Saved state at %d, want %d", got, want)
+	}
+	kinds, err := check(t, dir, v, st, nil)
+	if err != nil {
+		t.Fatalf("Check(): %v", err)
+	}
+	if len(kinds) > 0 {
+		t.Errorf("Check() found %v, want no findings", kinds)
+	}
+	if got, want := st.Next, uint64(testLogSize); got != want {
+		t.Errorf("Check() stopped at %d, want %d", got, want)
+	}
+
+	// Resuming from the end of the log is a no-op.
+	kinds, err = check(t, dir, v, st, nil)
+	if err != nil || len(kinds) > 0 {
+		t.Errorf("Check()=%v, %v, want no findings", kinds, err)
+	}
+}
+
+func TestCheckFindings(t *testing.T) {
+	logDir := t.TempDir()
+	v := newTestLog(t, logDir, testLogSize)
+	issuerPath := func(i int) string {
+		fp := sha256.Sum256(testIssuers[i])
+		return filepath.Join("issuer", hex.EncodeToString(fp[:]))
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		corrupt func(t *testing.T, dir string)
+		want    []Kind
+		wantErr bool
+	}{
+		{
+			desc: "missing-issuer",
+			corrupt: func(t *testing.T, dir string) {
+				remove(t, dir, issuerPath(1))
+			},
+			want: []Kind{KindIssuer},
+		},
+		{
+			desc: "wrong-issuer",
+			corrupt: func(t *testing.T, dir string) {
+				write(t, dir, issuerPath(0), []byte("not issuer 0"))
+			},
+			want: []Kind{KindIssuer},
+		},
+		{
+			desc: "wrong-full-tile",
+			corrupt: func(t *testing.T, dir string) {
+				flipByte(t, dir, layout.TilePath(0, 1, 0))
+			},
+			want: []Kind{KindTile},
+		},
+		{
+			desc: "wrong-partial-tile",
+			corrupt: func(t *testing.T, dir string) {
+				flipByte(t, dir, layout.TilePath(1, 0, 2))
+			},
+			want: []Kind{KindTile},
+		},
+		{
+			desc: "missing-tile",
+			corrupt: func(t *testing.T, dir string) {
+				remove(t, dir, layout.TilePath(0, 2, 88))
+			},
+			want: []Kind{KindTile},
+		},
+		{
+			desc: "wrong-leaf-index",
+			corrupt: func(t *testing.T, dir string) {
+				relogEntry(t, dir, layout.EntryBundleWidth+3, layout.EntryBundleWidth+4)
+			},
+			want: []Kind{KindLeafIndex, KindRootHash, KindTile},
+		},
+		{
+			desc: "missing-entry-bundle",
+			corrupt: func(t *testing.T, dir string) {
+				remove(t, dir, entryBundlePath(1, 0))
+			},
+			want:    []Kind{KindEntryBundle},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.CopyFS(dir, os.DirFS(logDir)); err != nil {
+				t.Fatalf("Failed to copy log: %v", err)
+			}
+			tc.corrupt(t, dir)
+
+			kinds, err := check(t, dir, v, &State{}, nil)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("Check()=%v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, kinds); diff != "" {
+				t.Errorf("Finding kinds mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func remove(t *testing.T, dir, p string) {
+	t.Helper()
+	if err := os.Remove(filepath.Join(dir, p)); err != nil {
+		t.Fatalf("Failed to remove %q: %v", p, err)
+	}
+}
+
+func write(t *testing.T, dir, p string, d []byte) {
+	t.Helper()
+	p = filepath.Join(dir, p)
+	// Files in the log may be read-only.
+	if err := os.Remove(p); err != nil {
+		t.Fatalf("Failed to remove %q: %v", p, err)
+	}
+	if err := os.WriteFile(p, d, 0o644); err != nil {
+		t.Fatalf("Failed to write %q: %v", p, err)
+	}
+}
+
+func flipByte(t *testing.T, dir, p string) {
+	t.Helper()
+	d, err := os.ReadFile(filepath.Join(dir, p))
+	if err != nil {
+		t.Fatalf("Failed to read %q: %v", p, err)
+	}
+	d[len(d)/2] ^= 0xff
+	write(t, dir, p, d)
+}
+
+// relogEntry rewrites the entry at index idx in its entry bundle, with a
+// leaf_index extension of leafIndex.
+func relogEntry(t *testing.T, dir string, idx, leafIndex uint64) {
+	t.Helper()
+	bIdx := idx / layout.EntryBundleWidth
+	p := entryBundlePath(bIdx, 0)
+	d, err := os.ReadFile(filepath.Join(dir, p))
+	if err != nil {
+		t.Fatalf("Failed to read %q: %v", p, err)
+	}
+	b := staticct.EntryBundle{}
+	if err := b.UnmarshalText(d); err != nil {
+		t.Fatalf("Failed to parse %q: %v", p, err)
+	}
+
+	out := []byte{}
+	for i, raw := range b.Entries {
+		if uint64(i) == idx%layout.EntryBundleWidth {
+			e := staticct.Entry{}
+			if err := e.UnmarshalText(raw); err != nil {
+				t.Fatalf("Failed to parse entry %d: %v", idx, err)
+			}
+			raw = (&ctonly.Entry{
+				Timestamp:         e.Timestamp,
+				Certificate:       e.Certificate,
+				FingerprintsChain: e.FingerprintsChain,
+			}).LeafData(leafIndex)
+		}
+		out = append(out, raw...)
+	}
+	write(t, dir, p, out)
+}
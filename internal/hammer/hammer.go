@@ -40,7 +40,6 @@ import (
 	"sync"
 	"time"
 
-	tdnote "github.com/transparency-dev/formats/note"
 	"github.com/transparency-dev/tesseract/internal/client"
 	"github.com/transparency-dev/tesseract/internal/client/gcp"
 	"github.com/transparency-dev/tesseract/internal/hammer/loadtest"
@@ -388,11 +387,7 @@ func logSigVerifier(origin, b64PubKey string) (note.Verifier, error) {
 		return nil, fmt.Errorf("error parsing public key: %v", err)
 	}
 
-	verifierKey, err := tdnote.RFC6962VerifierString(origin, pub)
-	if err != nil {
-		return nil, fmt.Errorf("error creating RFC6962 verifier string: %v", err)
-	}
-	logSigV, err := tdnote.NewVerifier(verifierKey)
+	logSigV, err := client.NewRFC6962Verifier(origin, pub)
 	if err != nil {
 		return nil, fmt.Errorf("error creating verifier: %v", err)
 	}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rotatingfile implements files which are rotated once they reach a
+// maximum size.
+package rotatingfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// File is an io.WriteCloser appending to a file, which is rotated once it
+// reaches a maximum size.
+//
+// Rotated files are renamed with a .1, .2, ... suffix, .1 being the most
+// recent one, and only a maximum number of them are kept.
+type File struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+// New opens the file at path for appending. It is rotated before it would
+// grow past maxSize bytes, keeping up to maxBackups rotated files. A maxSize
+// of 0 disables rotation.
+func New(path string, maxSize int64, maxBackups int) (*File, error) {
+	f, size, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		f:          f,
+		size:       size,
+	}, nil
+}
+
+// open opens the file at path for appending, and returns its size.
+func open(path string) (*os.File, int64, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %q: %v", path, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, fmt.Errorf("failed to stat %q: %v", path, err)
+	}
+	return f, fi.Size(), nil
+}
+
+// Write appends p to the file, rotating it first if it would grow too large.
+//
+// If the file can't be rotated, p is appended to the current file, and
+// rotation is attempted again on the next write.
+func (r *File) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			klog.Warningf("Failed to rotate %q: %v", r.path, err)
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate renames the current file and the existing rotated ones, dropping
+// the oldest, and opens a new file.
+//
+// The current file is kept open until the new one is, so that writes can
+// carry on if rotation fails.
+func (r *File) rotate() error {
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", r.path, i) }
+
+	if err := os.Remove(backup(r.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove oldest rotated file: %v", err)
+	}
+	for i := r.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rename rotated file: %v", err)
+		}
+	}
+	rename := os.Remove
+	if r.maxBackups > 0 {
+		rename = func(p string) error { return os.Rename(p, backup(1)) }
+	}
+	if err := rename(r.path); err != nil {
+		return fmt.Errorf("failed to rename %q: %v", r.path, err)
+	}
+	f, size, err := open(r.path)
+	if err != nil {
+		return err
+	}
+	old := r.f
+	r.f, r.size = f, size
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("failed to close rotated file: %v", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (r *File) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
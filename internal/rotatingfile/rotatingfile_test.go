@@ -0,0 +1,109 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotatingfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.log")
+	// Existing content counts towards the size of the file.
+	if err := os.WriteFile(path, []byte("0000\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	r, err := New(path, 10, 2)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	for _, l := range []string{"1111\n", "2222\n", "3333\n", "4444\n", "5555\n", "6666\n", "77777777777\n"} {
+		if _, err := r.Write([]byte(l)); err != nil {
+			t.Fatalf("Write(%q): %v", l, err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if _, err := r.Write([]byte("8888\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write() after Close()=%v, want %v", err, os.ErrClosed)
+	}
+
+	for p, want := range map[string]string{
+		path:        "77777777777\n",
+		path + ".1": "6666\n",
+		path + ".2": "4444\n5555\n",
+	} {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("ReadFile(%q): %v", p, err)
+		}
+		if string(got) != want {
+			t.Errorf("%q holds %q, want %q", p, got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat(%q)=%v, want %v", path+".3", err, os.ErrNotExist)
+	}
+}
+
+func TestFileRotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.log")
+	r, err := New(path, 10, 1)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			t.Errorf("Close(): %v", err)
+		}
+	}()
+	// A non-empty directory can't replace the oldest rotated file.
+	if err := os.MkdirAll(filepath.Join(path+".1", "dir"), 0o755); err != nil {
+		t.Fatalf("MkdirAll(): %v", err)
+	}
+
+	for _, l := range []string{"1111\n", "2222\n", "3333\n"} {
+		if _, err := r.Write([]byte(l)); err != nil {
+			t.Fatalf("Write(%q): %v", l, err)
+		}
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "1111\n2222\n3333\n" {
+		t.Errorf("ReadFile(%q)=%q, %v, want all writes", path, got, err)
+	}
+
+	// Rotation succeeds once the obstacle is gone.
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatalf("RemoveAll(): %v", err)
+	}
+	if _, err := r.Write([]byte("4444\n")); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	for p, want := range map[string]string{
+		path:        "4444\n",
+		path + ".1": "1111\n2222\n3333\n",
+	} {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("ReadFile(%q): %v", p, err)
+		}
+		if string(got) != want {
+			t.Errorf("%q holds %q, want %q", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// NewFileSigner creates a new signer that uses the ECDSA or RSA private key
+// stored in a PEM file for signing digests.
+//
+// If publicKeyFile is not empty, the private key must match the PEM encoded
+// public key it holds.
+func NewFileSigner(privateKeyFile, publicKeyFile string) (crypto.Signer, error) {
+	if privateKeyFile == "" {
+		return nil, errors.New("empty private key file path")
+	}
+	b, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file %q: %w", privateKeyFile, err)
+	}
+	privateKey, err := ParsePrivateKeyPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key file %q: %w", privateKeyFile, err)
+	}
+
+	publicKey := privateKey.Public()
+	if publicKeyFile != "" {
+		b, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key file %q: %w", publicKeyFile, err)
+		}
+		if publicKey, err = ParsePublicKeyPEM(b); err != nil {
+			return nil, fmt.Errorf("failed to parse public key file %q: %w", publicKeyFile, err)
+		}
+	}
+
+	return NewSHA256Signer(publicKey, privateKey)
+}
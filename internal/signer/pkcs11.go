@@ -0,0 +1,31 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+// PKCS11Config configures a signer using a key held by a PKCS #11 token,
+// such as a hardware security module.
+type PKCS11Config struct {
+	// ModulePath is the path to the PKCS #11 library of the token.
+	ModulePath string
+	// TokenLabel is the label of the token holding the key.
+	TokenLabel string
+	// PIN is the user PIN of the token.
+	PIN string
+	// KeyLabel is the label of the key pair. At least one of KeyLabel and
+	// KeyID must be set.
+	KeyLabel string
+	// KeyID is the ID of the key pair.
+	KeyID []byte
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+var oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+// pkcs11Signer implements crypto.Signer using an ECDSA key held by a PKCS #11
+// token. Only crypto.SHA256 is supported.
+type pkcs11Signer struct {
+	ctx       *pkcs11.Ctx
+	publicKey *ecdsa.PublicKey
+	key       pkcs11.ObjectHandle
+
+	// mu guards session, which can't be used concurrently.
+	mu      sync.Mutex
+	session pkcs11.SessionHandle
+}
+
+// NewPKCS11Signer creates a new signer that uses an ECDSA key pair held by a
+// PKCS #11 token for signing digests. The token session is kept open for the
+// lifetime of the process.
+func NewPKCS11Signer(cfg PKCS11Config) (crypto.Signer, error) {
+	if cfg.ModulePath == "" || cfg.TokenLabel == "" {
+		return nil, errors.New("missing PKCS #11 module path or token label")
+	}
+	if cfg.KeyLabel == "" && len(cfg.KeyID) == 0 {
+		return nil, errors.New("missing PKCS #11 key label or ID")
+	}
+
+	ctx, err := loadModule(cfg.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	s := &pkcs11Signer{ctx: ctx}
+	if err := s.open(cfg); err != nil {
+		s.close()
+		return nil, err
+	}
+	if err := SelfTest(s); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+var (
+	modulesMu sync.Mutex
+	// modules holds initialized PKCS #11 modules keyed by path, since a
+	// module can only be initialized once per process, but shared by
+	// several signers.
+	modules = make(map[string]*pkcs11.Ctx)
+)
+
+// loadModule loads and initializes the PKCS #11 module at path, or returns
+// it if it was already loaded.
+func loadModule(path string) (*pkcs11.Ctx, error) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	if ctx, ok := modules[path]; ok {
+		return ctx, nil
+	}
+	ctx := pkcs11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS #11 module %q", path)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS #11 module %q: %v", path, err)
+	}
+	modules[path] = ctx
+	return ctx, nil
+}
+
+// close closes the session of a signer which failed to open.
+func (s *pkcs11Signer) close() {
+	if s.session != 0 {
+		_ = s.ctx.CloseSession(s.session)
+	}
+}
+
+// open logs into the token, and looks up the key pair.
+func (s *pkcs11Signer) open(cfg PKCS11Config) error {
+	slot, err := findSlot(s.ctx, cfg.TokenLabel)
+	if err != nil {
+		return err
+	}
+	if s.session, err = s.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION); err != nil {
+		return fmt.Errorf("failed to open PKCS #11 session: %v", err)
+	}
+	// Logins are shared by all the sessions of an application.
+	if err := s.ctx.Login(s.session, pkcs11.CKU_USER, cfg.PIN); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		return fmt.Errorf("failed to log into PKCS #11 token %q: %v", cfg.TokenLabel, err)
+	}
+
+	if s.key, err = s.findObject(pkcs11.CKO_PRIVATE_KEY, cfg); err != nil {
+		return fmt.Errorf("failed to find private key: %v", err)
+	}
+	pub, err := s.findObject(pkcs11.CKO_PUBLIC_KEY, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to find public key: %v", err)
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, pub, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %v", err)
+	}
+	if s.publicKey, err = parseECPublicKey(attrs[0].Value, attrs[1].Value); err != nil {
+		return fmt.Errorf("failed to parse public key: %v", err)
+	}
+	return nil
+}
+
+// findSlot returns the slot holding the token with the given label.
+func findSlot(ctx *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list PKCS #11 slots: %v", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get info of PKCS #11 token in slot %d: %v", slot, err)
+		}
+		if strings.TrimRight(info.Label, " \x00") == label {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("no PKCS #11 token with label %q", label)
+}
+
+// findObject returns the single EC key object of the given class matching the
+// label and ID of cfg.
+func (s *pkcs11Signer) findObject(class uint, cfg PKCS11Config) (pkcs11.ObjectHandle, error) {
+	tmpl := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+	}
+	if cfg.KeyLabel != "" {
+		tmpl = append(tmpl, pkcs11.NewAttribute(pkcs11.CKA_LABEL, cfg.KeyLabel))
+	}
+	if len(cfg.KeyID) > 0 {
+		tmpl = append(tmpl, pkcs11.NewAttribute(pkcs11.CKA_ID, cfg.KeyID))
+	}
+	if err := s.ctx.FindObjectsInit(s.session, tmpl); err != nil {
+		return 0, err
+	}
+	objs, _, err := s.ctx.FindObjects(s.session, 2)
+	if fErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = fErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	switch len(objs) {
+	case 0:
+		return 0, errors.New("no matching EC key")
+	case 1:
+		return objs[0], nil
+	default:
+		return 0, errors.New("more than one matching EC key")
+	}
+}
+
+// parseECPublicKey parses an ECDSA public key from its PKCS #11 CKA_EC_PARAMS
+// and CKA_EC_POINT attributes.
+func parseECPublicKey(params, point []byte) (*ecdsa.PublicKey, error) {
+	// CKA_EC_POINT should be a DER encoded OCTET STRING, but some tokens
+	// return the raw point.
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err != nil || len(rest) > 0 {
+		raw = point
+	}
+	spki, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PublicKey: asn1.BitString{Bytes: raw, BitLength: 8 * len(raw)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(spki)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an ECDSA key")
+	}
+	return ecdsaKey, nil
+}
+
+// Public returns the public key of the token key pair.
+func (s *pkcs11Signer) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs digest with the private key held by the token.
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := checkDigest(digest, opts); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, s.key); err != nil {
+		return nil, fmt.Errorf("failed to initialize PKCS #11 signature: %v", err)
+	}
+	sig, err := s.ctx.Sign(s.session, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with PKCS #11 token: %v", err)
+	}
+
+	// PKCS #11 ECDSA signatures are the concatenation of r and s.
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return nil, fmt.Errorf("invalid PKCS #11 ECDSA signature length %d", len(sig))
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(sig[:len(sig)/2]),
+		S: new(big.Int).SetBytes(sig[len(sig)/2:]),
+	})
+}
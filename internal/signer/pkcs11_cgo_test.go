@@ -0,0 +1,223 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+)
+
+var oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+func TestParseECPublicKey(t *testing.T) {
+	key := mustGenerateKey(t)
+	params, err := asn1.Marshal(oidNamedCurveP256)
+	if err != nil {
+		t.Fatalf("asn1.Marshal(): %v", err)
+	}
+	ecdhKey, err := key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatalf("ECDH(): %v", err)
+	}
+	raw := ecdhKey.Bytes()
+	der, err := asn1.Marshal(raw)
+	if err != nil {
+		t.Fatalf("asn1.Marshal(): %v", err)
+	}
+
+	for _, point := range [][]byte{der, raw} {
+		got, err := parseECPublicKey(params, point)
+		if err != nil {
+			t.Fatalf("parseECPublicKey(): %v", err)
+		}
+		if !key.PublicKey.Equal(got) {
+			t.Errorf("parseECPublicKey()=%v, want %v", got, key.PublicKey)
+		}
+	}
+	if _, err := parseECPublicKey(params, raw[:10]); err == nil {
+		t.Error("parseECPublicKey(truncated point)=nil, want err")
+	}
+}
+
+// softHSMModule returns the path to the SoftHSM PKCS #11 module, from the
+// SOFTHSM2_MODULE environment variable or well-known locations, and skips
+// the test if it can't be found.
+func softHSMModule(t *testing.T) string {
+	t.Helper()
+	paths := []string{
+		os.Getenv("SOFTHSM2_MODULE"),
+		"/usr/lib/softhsm/libsofthsm2.so",
+		"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+		"/usr/local/lib/softhsm/libsofthsm2.so",
+		"/opt/homebrew/lib/softhsm/libsofthsm2.so",
+	}
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	t.Skip("SoftHSM is not installed, set SOFTHSM2_MODULE to the path of libsofthsm2.so")
+	return ""
+}
+
+// setupSoftHSMToken initializes a SoftHSM token with the given label and user
+// PIN, and generates a P-256 key pair in it.
+func setupSoftHSMToken(t *testing.T, module, tokenLabel, pin, keyLabel string, keyID []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "softhsm2.conf")
+	if err := os.WriteFile(conf, []byte(fmt.Sprintf("directories.tokendir = %s\nobjectstore.backend = file\n", dir)), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	t.Setenv("SOFTHSM2_CONF", conf)
+
+	// The module is initialized once per process, and shared with signers.
+	ctx, err := loadModule(module)
+	if err != nil {
+		t.Fatalf("loadModule(): %v", err)
+	}
+	slots, err := ctx.GetSlotList(false)
+	if err != nil || len(slots) == 0 {
+		t.Fatalf("GetSlotList()=%v, %v", slots, err)
+	}
+	soPIN := "so-" + pin
+	if err := ctx.InitToken(slots[0], soPIN, tokenLabel); err != nil {
+		t.Fatalf("InitToken(): %v", err)
+	}
+	// SoftHSM moves initialized tokens to a new slot.
+	slot, err := findSlot(ctx, tokenLabel)
+	if err != nil {
+		t.Fatalf("findSlot(): %v", err)
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatalf("OpenSession(): %v", err)
+	}
+	defer func() { _ = ctx.CloseSession(session) }()
+	if err := ctx.Login(session, pkcs11.CKU_SO, soPIN); err != nil {
+		t.Fatalf("Login(SO): %v", err)
+	}
+	if err := ctx.InitPIN(session, pin); err != nil {
+		t.Fatalf("InitPIN(): %v", err)
+	}
+	if err := ctx.Logout(session); err != nil {
+		t.Fatalf("Logout(): %v", err)
+	}
+	if err := ctx.Login(session, pkcs11.CKU_USER, pin); err != nil {
+		t.Fatalf("Login(): %v", err)
+	}
+	defer func() { _ = ctx.Logout(session) }()
+
+	params, err := asn1.Marshal(oidNamedCurveP256)
+	if err != nil {
+		t.Fatalf("asn1.Marshal(): %v", err)
+	}
+	if _, _, err := ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, keyID),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, keyID),
+		},
+	); err != nil {
+		t.Fatalf("GenerateKeyPair(): %v", err)
+	}
+}
+
+func TestNewPKCS11Signer(t *testing.T) {
+	module := softHSMModule(t)
+	setupSoftHSMToken(t, module, "tesseract", "1234", "log-key", []byte{1, 2})
+
+	for _, tc := range []struct {
+		desc    string
+		cfg     PKCS11Config
+		wantErr string
+	}{
+		{
+			desc: "by-label",
+			cfg:  PKCS11Config{ModulePath: module, TokenLabel: "tesseract", PIN: "1234", KeyLabel: "log-key"},
+		},
+		{
+			desc: "by-id",
+			cfg:  PKCS11Config{ModulePath: module, TokenLabel: "tesseract", PIN: "1234", KeyID: []byte{1, 2}},
+		},
+		{
+			desc:    "unknown-token",
+			cfg:     PKCS11Config{ModulePath: module, TokenLabel: "other", PIN: "1234", KeyLabel: "log-key"},
+			wantErr: `no PKCS #11 token with label "other"`,
+		},
+		{
+			desc:    "unknown-key",
+			cfg:     PKCS11Config{ModulePath: module, TokenLabel: "tesseract", PIN: "1234", KeyLabel: "other"},
+			wantErr: "failed to find private key",
+		},
+		{
+			desc:    "missing-key",
+			cfg:     PKCS11Config{ModulePath: module, TokenLabel: "tesseract", PIN: "1234"},
+			wantErr: "missing PKCS #11 key label or ID",
+		},
+		{
+			desc:    "unknown-module",
+			cfg:     PKCS11Config{ModulePath: filepath.Join(t.TempDir(), "missing.so"), TokenLabel: "tesseract", KeyLabel: "log-key"},
+			wantErr: "failed to load PKCS #11 module",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewPKCS11Signer(tc.cfg)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("NewPKCS11Signer()=%v, want err containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewPKCS11Signer(): %v", err)
+			}
+			digest := sha256.Sum256([]byte("message"))
+			sig, err := s.Sign(rand.Reader, digest[:], crypto.SHA256)
+			if err != nil {
+				t.Fatalf("Sign(): %v", err)
+			}
+			if !ecdsa.VerifyASN1(s.Public().(*ecdsa.PublicKey), digest[:], sig) {
+				t.Error("Signature doesn't verify")
+			}
+		})
+	}
+}
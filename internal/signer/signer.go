@@ -0,0 +1,227 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signer provides crypto.Signer implementations for the keys signing
+// TesseraCT checkpoints and SCTs.
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ECDSAWithSHA256Signer implements crypto.Signer using an ECDSA key held in
+// memory. Only crypto.SHA256 is supported.
+type ECDSAWithSHA256Signer struct {
+	publicKey  *ecdsa.PublicKey
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewECDSAWithSHA256Signer returns a signer using privateKey, after checking
+// that it matches publicKey.
+func NewECDSAWithSHA256Signer(publicKey *ecdsa.PublicKey, privateKey *ecdsa.PrivateKey) (*ECDSAWithSHA256Signer, error) {
+	if publicKey == nil || privateKey == nil {
+		return nil, errors.New("missing public or private key")
+	}
+	s := &ECDSAWithSHA256Signer{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}
+	if err := SelfTest(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Public returns the public key stored in the Signer object.
+func (s *ECDSAWithSHA256Signer) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs digest with the private key. If rand is nil, the signature is
+// deterministic, as per RFC 6979.
+func (s *ECDSAWithSHA256Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := checkDigest(digest, opts); err != nil {
+		return nil, err
+	}
+	return s.privateKey.Sign(rand, digest, opts)
+}
+
+// RSAWithSHA256Signer implements crypto.Signer using an RSA key held in
+// memory. Signatures are RSASSA-PKCS1-v1_5, and only crypto.SHA256 is
+// supported.
+type RSAWithSHA256Signer struct {
+	publicKey  *rsa.PublicKey
+	privateKey *rsa.PrivateKey
+}
+
+// NewRSAWithSHA256Signer returns a signer using privateKey, after checking
+// that it matches publicKey.
+func NewRSAWithSHA256Signer(publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey) (*RSAWithSHA256Signer, error) {
+	if publicKey == nil || privateKey == nil {
+		return nil, errors.New("missing public or private key")
+	}
+	s := &RSAWithSHA256Signer{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}
+	if err := SelfTest(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Public returns the public key stored in the Signer object.
+func (s *RSAWithSHA256Signer) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs digest with the private key. RSASSA-PKCS1-v1_5 signatures are
+// deterministic, and rand is ignored.
+func (s *RSAWithSHA256Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := checkDigest(digest, opts); err != nil {
+		return nil, err
+	}
+	return rsa.SignPKCS1v15(rand, s.privateKey, crypto.SHA256, digest)
+}
+
+// NewSHA256Signer returns an ECDSA or RSA signer using privateKey, after
+// checking that it matches publicKey.
+func NewSHA256Signer(publicKey crypto.PublicKey, privateKey crypto.PrivateKey) (crypto.Signer, error) {
+	switch priv := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		pub, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key type %T doesn't match ECDSA private key", publicKey)
+		}
+		return NewECDSAWithSHA256Signer(pub, priv)
+	case *rsa.PrivateKey:
+		pub, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key type %T doesn't match RSA private key", publicKey)
+		}
+		return NewRSAWithSHA256Signer(pub, priv)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// checkDigest verifies that digest is a SHA-256 digest.
+func checkDigest(digest []byte, opts crypto.SignerOpts) error {
+	if opts == nil {
+		return errors.New("opts cannot be nil")
+	}
+	if opts.HashFunc() != crypto.SHA256 {
+		return fmt.Errorf("unsupported hash func: %v", opts.HashFunc())
+	}
+	if len(digest) != opts.HashFunc().Size() {
+		return fmt.Errorf("digest bytes length %d does not match hash function bytes length %d", len(digest), opts.HashFunc().Size())
+	}
+	return nil
+}
+
+// SelfTest checks that s produces signatures which verify with its public
+// key, i.e. that its private and public keys match.
+func SelfTest(s crypto.Signer) error {
+	digest := sha256.Sum256([]byte("TesseraCT signer self-test"))
+	sig, err := s.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("signer self-test: failed to sign: %v", err)
+	}
+	switch pub := s.Public().(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+			return errors.New("signer self-test: signer key pair doesn't match")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("signer self-test: signer key pair doesn't match")
+		}
+	default:
+		return fmt.Errorf("signer self-test: unsupported public key type %T", pub)
+	}
+	return nil
+}
+
+// ParsePublicKeyPEM parses a PEM encoded ECDSA or RSA public key.
+func ParsePublicKeyPEM(b []byte) (crypto.PublicKey, error) {
+	block, err := decodePEM(b)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unsupported PEM type: %s", block.Type)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded private key: an ECDSA key in SEC 1
+// form, an RSA key in PKCS #1 form, or either in PKCS #8 form.
+func ParsePrivateKeyPEM(b []byte) (crypto.Signer, error) {
+	block, err := decodePEM(b)
+	if err != nil {
+		return nil, err
+	}
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// decodePEM decodes a single PEM block.
+func decodePEM(b []byte) (*pem.Block, error) {
+	block, rest := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM")
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("extra data after decoding PEM: %v", rest)
+	}
+	return block, nil
+}
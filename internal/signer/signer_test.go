@@ -0,0 +1,277 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return k
+}
+
+func mustGenerateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	return k
+}
+
+// writePEM writes a PEM block to a file in dir, and returns its path.
+func writePEM(t *testing.T, dir, name, typ string, der []byte) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	return p
+}
+
+func TestNewFileSigner(t *testing.T) {
+	dir := t.TempDir()
+	key := mustGenerateKey(t)
+	sec1, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey(): %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey(): %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	otherPub, err := x509.MarshalPKIXPublicKey(&mustGenerateKey(t).PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	rsaKey := mustGenerateRSAKey(t)
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey(): %v", err)
+	}
+	rsaPub, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+
+	sec1File := writePEM(t, dir, "sec1.pem", "EC PRIVATE KEY", sec1)
+	pkcs8File := writePEM(t, dir, "pkcs8.pem", "PRIVATE KEY", pkcs8)
+	pubFile := writePEM(t, dir, "pub.pem", "PUBLIC KEY", pub)
+	otherPubFile := writePEM(t, dir, "other.pem", "PUBLIC KEY", otherPub)
+	certFile := writePEM(t, dir, "cert.pem", "CERTIFICATE", pub)
+	rsaPKCS1File := writePEM(t, dir, "rsa-pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	rsaPKCS8File := writePEM(t, dir, "rsa-pkcs8.pem", "PRIVATE KEY", rsaPKCS8)
+	rsaPubFile := writePEM(t, dir, "rsa-pub.pem", "PUBLIC KEY", rsaPub)
+
+	for _, tc := range []struct {
+		desc           string
+		privateKeyFile string
+		publicKeyFile  string
+		wantPublic     interface{ Equal(crypto.PublicKey) bool }
+		wantErr        string
+	}{
+		{
+			desc:           "sec1",
+			privateKeyFile: sec1File,
+			wantPublic:     &key.PublicKey,
+		},
+		{
+			desc:           "pkcs8",
+			privateKeyFile: pkcs8File,
+			wantPublic:     &key.PublicKey,
+		},
+		{
+			desc:           "matching-public-key",
+			privateKeyFile: sec1File,
+			publicKeyFile:  pubFile,
+			wantPublic:     &key.PublicKey,
+		},
+		{
+			desc:           "rsa-pkcs1",
+			privateKeyFile: rsaPKCS1File,
+			publicKeyFile:  rsaPubFile,
+			wantPublic:     &rsaKey.PublicKey,
+		},
+		{
+			desc:           "rsa-pkcs8",
+			privateKeyFile: rsaPKCS8File,
+			wantPublic:     &rsaKey.PublicKey,
+		},
+		{
+			desc:           "mismatched-key-types",
+			privateKeyFile: rsaPKCS1File,
+			publicKeyFile:  pubFile,
+			wantErr:        "doesn't match RSA private key",
+		},
+		{
+			desc:           "mismatched-public-key",
+			privateKeyFile: sec1File,
+			publicKeyFile:  otherPubFile,
+			wantErr:        "signer key pair doesn't match",
+		},
+		{
+			desc:    "missing-private-key-file",
+			wantErr: "empty private key file path",
+		},
+		{
+			desc:           "wrong-pem-type",
+			privateKeyFile: certFile,
+			wantErr:        "unsupported PEM type",
+		},
+		{
+			desc:           "not-found",
+			privateKeyFile: filepath.Join(dir, "missing.pem"),
+			wantErr:        "failed to read private key file",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewFileSigner(tc.privateKeyFile, tc.publicKeyFile)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("NewFileSigner()=%v, want err containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewFileSigner(): %v", err)
+			}
+			if !tc.wantPublic.Equal(s.Public()) {
+				t.Error("Public() doesn't match the private key")
+			}
+		})
+	}
+}
+
+func TestECDSAWithSHA256Signer(t *testing.T) {
+	key := mustGenerateKey(t)
+	s, err := NewECDSAWithSHA256Signer(&key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("NewECDSAWithSHA256Signer(): %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := s.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig) {
+		t.Error("Signature doesn't verify")
+	}
+
+	if _, err := s.Sign(rand.Reader, digest[:], crypto.SHA384); err == nil {
+		t.Error("Sign(SHA384)=nil, want err")
+	}
+	if _, err := s.Sign(rand.Reader, digest[:10], crypto.SHA256); err == nil {
+		t.Error("Sign(short digest)=nil, want err")
+	}
+	if _, err := NewECDSAWithSHA256Signer(&mustGenerateKey(t).PublicKey, key); err == nil {
+		t.Error("NewECDSAWithSHA256Signer(mismatched keys)=nil, want err")
+	}
+}
+
+func TestRSAWithSHA256Signer(t *testing.T) {
+	key := mustGenerateRSAKey(t)
+	s, err := NewRSAWithSHA256Signer(&key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("NewRSAWithSHA256Signer(): %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := s.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("Signature doesn't verify: %v", err)
+	}
+
+	if _, err := s.Sign(rand.Reader, digest[:], crypto.SHA384); err == nil {
+		t.Error("Sign(SHA384)=nil, want err")
+	}
+	if _, err := NewRSAWithSHA256Signer(&mustGenerateRSAKey(t).PublicKey, key); err == nil {
+		t.Error("NewRSAWithSHA256Signer(mismatched keys)=nil, want err")
+	}
+}
+
+func TestDeterministicSignatures(t *testing.T) {
+	ecdsaKey := mustGenerateKey(t)
+	ecdsaSigner, err := NewECDSAWithSHA256Signer(&ecdsaKey.PublicKey, ecdsaKey)
+	if err != nil {
+		t.Fatalf("NewECDSAWithSHA256Signer(): %v", err)
+	}
+	rsaKey := mustGenerateRSAKey(t)
+	rsaSigner, err := NewRSAWithSHA256Signer(&rsaKey.PublicKey, rsaKey)
+	if err != nil {
+		t.Fatalf("NewRSAWithSHA256Signer(): %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("message"))
+	for _, tc := range []struct {
+		desc   string
+		signer crypto.Signer
+		verify func(sig []byte) bool
+	}{
+		{
+			desc:   "ecdsa",
+			signer: ecdsaSigner,
+			verify: func(sig []byte) bool { return ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], sig) },
+		},
+		{
+			desc:   "rsa",
+			signer: rsaSigner,
+			verify: func(sig []byte) bool {
+				return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], sig) == nil
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			sig1, err := tc.signer.Sign(nil, digest[:], crypto.SHA256)
+			if err != nil {
+				t.Fatalf("Sign(): %v", err)
+			}
+			sig2, err := tc.signer.Sign(nil, digest[:], crypto.SHA256)
+			if err != nil {
+				t.Fatalf("Sign(): %v", err)
+			}
+			if !bytes.Equal(sig1, sig2) {
+				t.Error("Signatures with a nil rand differ")
+			}
+			if !tc.verify(sig1) {
+				t.Error("Signature doesn't verify")
+			}
+		})
+	}
+}
@@ -281,6 +281,19 @@ type MerkleTreeLeaf struct {
 	TimestampedEntry *TimestampedEntry `tls:"selector:LeafType,val:0"`
 }
 
+// CertificateChain holds a chain of certificates, as returned as extra data
+// for get-entries (section 4.6).
+type CertificateChain struct {
+	Entries []ASN1Cert `tls:"minlen:0,maxlen:16777215"`
+}
+
+// PrecertChainEntry holds an precertificate together with a validation chain
+// for it; see section 3.1.
+type PrecertChainEntry struct {
+	PreCertificate   ASN1Cert   `tls:"minlen:1,maxlen:16777215"`
+	CertificateChain []ASN1Cert `tls:"minlen:0,maxlen:16777215"`
+}
+
 // Precertificate represents the parsed CT Precertificate structure.
 type Precertificate struct {
 	// DER-encoded pre-certificate as originally added, which includes a
@@ -302,18 +315,28 @@ type APIEndpoint string
 // WARNING: Should match the URI paths without the "/ct/v1/" prefix.  If
 // changing these constants, may need to change those too.
 const (
-	AddChainStr    APIEndpoint = "add-chain"
-	AddPreChainStr APIEndpoint = "add-pre-chain"
-	GetRootsStr    APIEndpoint = "get-roots"
+	AddChainStr          APIEndpoint = "add-chain"
+	AddPreChainStr       APIEndpoint = "add-pre-chain"
+	GetSTHStr            APIEndpoint = "get-sth"
+	GetSTHConsistencyStr APIEndpoint = "get-sth-consistency"
+	GetProofByHashStr    APIEndpoint = "get-proof-by-hash"
+	GetEntriesStr        APIEndpoint = "get-entries"
+	GetRootsStr          APIEndpoint = "get-roots"
+	GetEntryAndProofStr  APIEndpoint = "get-entry-and-proof"
 )
 
 // URI paths for Log requests; see section 4.
 // WARNING: Should match the API endpoints, with the "/ct/v1/" prefix.  If
 // changing these constants, may need to change those too.
 const (
-	AddChainPath    = "/ct/v1/add-chain"
-	AddPreChainPath = "/ct/v1/add-pre-chain"
-	GetRootsPath    = "/ct/v1/get-roots"
+	AddChainPath          = "/ct/v1/add-chain"
+	AddPreChainPath       = "/ct/v1/add-pre-chain"
+	GetSTHPath            = "/ct/v1/get-sth"
+	GetSTHConsistencyPath = "/ct/v1/get-sth-consistency"
+	GetProofByHashPath    = "/ct/v1/get-proof-by-hash"
+	GetEntriesPath        = "/ct/v1/get-entries"
+	GetRootsPath          = "/ct/v1/get-roots"
+	GetEntryAndProofPath  = "/ct/v1/get-entry-and-proof"
 )
 
 // AddChainRequest represents the JSON request body sent to the add-chain and
@@ -338,3 +361,50 @@ type AddChainResponse struct {
 type GetRootsResponse struct {
 	Certificates []string `json:"certificates"`
 }
+
+// GetSTHResponse respresents the JSON response to the get-sth GET method from section 4.3.
+type GetSTHResponse struct {
+	TreeSize          uint64 `json:"tree_size"`           // Number of certs in the current tree
+	Timestamp         uint64 `json:"timestamp"`           // Time that the tree was created
+	SHA256RootHash    []byte `json:"sha256_root_hash"`    // Root hash of the tree
+	TreeHeadSignature []byte `json:"tree_head_signature"` // Log signature for this STH
+}
+
+// GetSTHConsistencyResponse represents the JSON response to the get-sth-consistency
+// GET method from section 4.4.  (The corresponding GET request has parameters 'first' and
+// 'second'.)
+type GetSTHConsistencyResponse struct {
+	Consistency [][]byte `json:"consistency"`
+}
+
+// GetProofByHashResponse represents the JSON response to the get-proof-by-hash GET
+// method from section 4.5.  (The corresponding GET request has parameters 'hash'
+// and 'tree_size'.)
+type GetProofByHashResponse struct {
+	LeafIndex int64    `json:"leaf_index"` // The 0-based index of the end entity corresponding to the "hash" parameter.
+	AuditPath [][]byte `json:"audit_path"` // An array of base64-encoded Merkle Tree nodes proving the inclusion of the chosen certificate.
+}
+
+// LeafEntry represents a leaf in the Log's Merkle tree, as returned by the get-entries
+// GET method from section 4.6.
+type LeafEntry struct {
+	// LeafInput is a TLS-encoded MerkleTreeLeaf
+	LeafInput []byte `json:"leaf_input"`
+	// ExtraData holds (unsigned) extra data, normally the cert validation chain.
+	ExtraData []byte `json:"extra_data"`
+}
+
+// GetEntriesResponse respresents the JSON response to the get-entries GET method
+// from section 4.6.
+type GetEntriesResponse struct {
+	Entries []LeafEntry `json:"entries"` // the list of returned entries
+}
+
+// GetEntryAndProofResponse represents the JSON response to the get-entry-and-proof
+// GET method from section 4.8. (The corresponding GET request has parameters 'leaf_index'
+// and 'tree_size'.)
+type GetEntryAndProofResponse struct {
+	LeafInput []byte   `json:"leaf_input"` // the entry itself
+	ExtraData []byte   `json:"extra_data"` // any chain provided when the entry was added to the log
+	AuditPath [][]byte `json:"audit_path"` // the corresponding proof
+}
@@ -17,6 +17,7 @@ package tls
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/rsa"
 	"fmt"
 )
 
@@ -67,6 +68,7 @@ type SignatureAlgorithm Enum
 // SignatureAlgorithm constants from RFC 5246 s7.4.1.4.1.
 const (
 	Anonymous SignatureAlgorithm = 0
+	RSA       SignatureAlgorithm = 1
 	ECDSA     SignatureAlgorithm = 3
 )
 
@@ -74,6 +76,8 @@ func (s SignatureAlgorithm) String() string {
 	switch s {
 	case Anonymous:
 		return "Anonymous"
+	case RSA:
+		return "RSA"
 	case ECDSA:
 		return "ECDSA"
 	default:
@@ -82,9 +86,11 @@ func (s SignatureAlgorithm) String() string {
 }
 
 // SignatureAlgorithmFromPubKey returns the algorithm used for this public key.
-// ECDSA, RSA, and DSA keys are supported. Other key types will return Anonymous.
+// ECDSA and RSA keys are supported. Other key types will return Anonymous.
 func SignatureAlgorithmFromPubKey(k crypto.PublicKey) SignatureAlgorithm {
 	switch k.(type) {
+	case *rsa.PublicKey:
+		return RSA
 	case *ecdsa.PublicKey:
 		return ECDSA
 	default:
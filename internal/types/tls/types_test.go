@@ -17,6 +17,7 @@ package tls
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/rsa"
 	"testing"
 )
 
@@ -43,6 +44,7 @@ func TestSignatureAlgorithmString(t *testing.T) {
 		want string
 	}{
 		{Anonymous, "Anonymous"},
+		{RSA, "RSA"},
 		{ECDSA, "ECDSA"},
 		{99, "UNKNOWN(99)"},
 	}
@@ -80,6 +82,7 @@ func TestSignatureAlgorithm(t *testing.T) {
 		key  crypto.PublicKey
 		want SignatureAlgorithm
 	}{
+		{name: "RSA", key: new(rsa.PublicKey), want: RSA},
 		{name: "ECDSA", key: new(ecdsa.PublicKey), want: ECDSA},
 		{name: "Other", key: "foo", want: Anonymous},
 	} {
@@ -0,0 +1,296 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ccadbPEMColumns are the names of CCADB report columns holding PEM
+	// encoded certificates, in order of preference.
+	ccadbPEMColumns = []string{"PEM Info", "PEM", "X.509 Certificate (PEM)"}
+	// ccadbTrustColumns are the names of CCADB report columns holding trust
+	// bits or trusted EKUs, separated by semicolons.
+	ccadbTrustColumns = []string{"Trust Bits", "Derived Trust Bits", "EKU Trust", "Microsoft EKUs"}
+	// ccadbNameColumns are the names of CCADB report columns identifying
+	// certificates, in order of preference.
+	ccadbNameColumns = []string{"Common Name or Certificate Name", "Certificate Name", "SHA-256 Fingerprint"}
+)
+
+// ErrDuplicateRoot is returned for CCADB records holding a certificate that
+// was already imported from a previous record.
+var ErrDuplicateRoot = errors.New("duplicate root")
+
+// CCADBOptions configures how CCADB reports are imported.
+type CCADBOptions struct {
+	// TrustBits, if not empty, restricts imported roots to those trusted for
+	// at least one of these trust bits or EKUs, e.g. "Websites" or
+	// "Server Authentication". Matching is case-insensitive.
+	TrustBits []string
+}
+
+// CCADBRecordError describes a CCADB record that could not be imported.
+type CCADBRecordError struct {
+	// Record is the 1-based index of the record in the report, headers
+	// excluded.
+	Record int
+	// Name identifies the record, if the report has a name column.
+	Name string
+	Err  error
+}
+
+func (e *CCADBRecordError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("record %d (%s): %v", e.Record, e.Name, e.Err)
+	}
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *CCADBRecordError) Unwrap() error {
+	return e.Err
+}
+
+// CCADBImport is the result of importing a CCADB report.
+type CCADBImport struct {
+	// Pool holds the imported roots.
+	Pool *PEMCertPool
+	// Untrusted is the number of records skipped because they are not
+	// trusted for any of the requested trust bits.
+	Untrusted int
+	// Errors lists records that could not be imported: unparsable ones, and
+	// duplicates, which wrap ErrDuplicateRoot.
+	Errors []*CCADBRecordError
+}
+
+// LoadCCADBFile imports roots from a CCADB report file. Files with a .json
+// extension are parsed with ParseCCADBJSON, others with ParseCCADBCSV.
+func LoadCCADBFile(path string, opts CCADBOptions) (*CCADBImport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CCADB report: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseCCADBJSON(f, opts)
+	}
+	return ParseCCADBCSV(f, opts)
+}
+
+// ParseCCADBCSV imports roots from a CCADB CSV report, with a header row.
+//
+// Certificates are read from the first present PEM column, such as
+// "PEM Info", and trust bits from all the present trust columns, such as
+// "Trust Bits" or "Microsoft EKUs".
+//
+// Unlike PEMCertPool.AppendCertsFromPEM, records which can't be imported
+// are reported rather than dropped. An error is only returned if the report
+// itself can't be read.
+func ParseCCADBCSV(r io.Reader, opts CCADBOptions) (*CCADBImport, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CCADB report header: %v", err)
+	}
+	// Strip the BOM often found in CSV exports.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+
+	var records []map[string][]string
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CCADB report: %v", err)
+		}
+		rec := make(map[string][]string, len(cols))
+		for name, i := range cols {
+			if i < len(row) {
+				rec[name] = []string{row[i]}
+			}
+		}
+		records = append(records, rec)
+	}
+	return importCCADB(cols, records, opts)
+}
+
+// ParseCCADBJSON imports roots from a CCADB JSON report: an array of objects
+// with the same keys as the columns of CSV reports. Trust bits can either be
+// a semicolon separated string, or an array of strings.
+func ParseCCADBJSON(r io.Reader, opts CCADBOptions) (*CCADBImport, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse CCADB report: %v", err)
+	}
+
+	cols := make(map[string]int)
+	records := make([]map[string][]string, 0, len(raw))
+	for _, obj := range raw {
+		rec := make(map[string][]string, len(obj))
+		for k, v := range obj {
+			var s string
+			var ss []string
+			switch {
+			case json.Unmarshal(v, &s) == nil:
+				rec[k] = []string{s}
+			case json.Unmarshal(v, &ss) == nil:
+				rec[k] = ss
+			default:
+				// Other values, such as numbers, aren't used.
+				continue
+			}
+			cols[k] = 0
+		}
+		records = append(records, rec)
+	}
+	return importCCADB(cols, records, opts)
+}
+
+// importCCADB imports roots from CCADB records, mapping column names to
+// their values. cols holds the names of the columns present in the report.
+func importCCADB(cols map[string]int, records []map[string][]string, opts CCADBOptions) (*CCADBImport, error) {
+	pemCol := firstColumn(cols, ccadbPEMColumns)
+	if pemCol == "" {
+		return nil, fmt.Errorf("CCADB report has none of the PEM columns %q", ccadbPEMColumns)
+	}
+	var trustCols []string
+	for _, c := range ccadbTrustColumns {
+		if _, ok := cols[c]; ok {
+			trustCols = append(trustCols, c)
+		}
+	}
+	if len(opts.TrustBits) > 0 && len(trustCols) == 0 {
+		return nil, fmt.Errorf("CCADB report has none of the trust columns %q", ccadbTrustColumns)
+	}
+	nameCol := firstColumn(cols, ccadbNameColumns)
+
+	res := &CCADBImport{Pool: NewPEMCertPool()}
+	seen := make(map[[sha256.Size]byte]int)
+	for i, rec := range records {
+		recErr := func(err error) *CCADBRecordError {
+			e := &CCADBRecordError{Record: i + 1, Err: err}
+			if nameCol != "" && len(rec[nameCol]) > 0 {
+				e.Name = rec[nameCol][0]
+			}
+			return e
+		}
+
+		if !trusted(rec, trustCols, opts.TrustBits) {
+			res.Untrusted++
+			continue
+		}
+		var pemData string
+		if len(rec[pemCol]) > 0 {
+			pemData = rec[pemCol][0]
+		}
+		cert, err := parseCCADBCert(pemData)
+		if err != nil {
+			res.Errors = append(res.Errors, recErr(err))
+			continue
+		}
+		fp := sha256.Sum256(cert.Raw)
+		if j, ok := seen[fp]; ok {
+			res.Errors = append(res.Errors, recErr(fmt.Errorf("%w, already imported from record %d", ErrDuplicateRoot, j)))
+			continue
+		}
+		seen[fp] = i + 1
+		res.Pool.AddCert(cert)
+	}
+	return res, nil
+}
+
+// firstColumn returns the first of names present in cols, or "" if none is.
+func firstColumn(cols map[string]int, names []string) string {
+	for _, n := range names {
+		if _, ok := cols[n]; ok {
+			return n
+		}
+	}
+	return ""
+}
+
+// trusted returns whether a record is trusted for at least one of
+// trustBits, in any of trustCols. All records are trusted if trustBits is
+// empty.
+func trusted(rec map[string][]string, trustCols []string, trustBits []string) bool {
+	if len(trustBits) == 0 {
+		return true
+	}
+	for _, c := range trustCols {
+		for _, v := range rec[c] {
+			for _, bit := range strings.FieldsFunc(v, func(r rune) bool { return r == ';' || r == ',' }) {
+				for _, want := range trustBits {
+					if strings.EqualFold(strings.TrimSpace(bit), strings.TrimSpace(want)) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// parseCCADBCert parses a single certificate from a CCADB PEM field. These
+// fields are sometimes wrapped in single quotes, or hold raw base64 DER.
+func parseCCADBCert(field string) (*x509.Certificate, error) {
+	field = strings.Trim(field, "' \t\r\n")
+	if field == "" {
+		return nil, errors.New("missing PEM certificate")
+	}
+
+	var der []byte
+	if strings.Contains(field, "-----BEGIN") {
+		block, rest := pem.Decode([]byte(field))
+		if block == nil {
+			return nil, errors.New("failed to decode PEM certificate")
+		}
+		if block.Type != pemCertificateBlockType {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+		if len(bytes.TrimSpace(rest)) > 0 {
+			return nil, errors.New("trailing data after PEM certificate")
+		}
+		der = block.Bytes
+	} else {
+		var err error
+		if der, err = base64.StdEncoding.DecodeString(field); err != nil {
+			return nil, fmt.Errorf("failed to decode certificate: %v", err)
+		}
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	return cert, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/transparency-dev/tesseract/internal/x509util"
+)
+
+// ccadbCSV returns a CCADB CSV report with the given header and rows.
+func ccadbCSV(t *testing.T, header []string, rows ...[]string) string {
+	t.Helper()
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(append([][]string{header}, rows...)); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	return b.String()
+}
+
+// quoted wraps a PEM certificate in single quotes, like CCADB reports do.
+func quoted(pem string) string {
+	return "'" + strings.TrimSpace(pem) + "'"
+}
+
+func TestParseCCADBCSV(t *testing.T) {
+	header := []string{"Common Name or Certificate Name", "Trust Bits", "PEM Info"}
+	report := ccadbCSV(t, header,
+		[]string{"CA", "Websites;Email", quoted(pemCACert)},
+		[]string{"Fake CA", "Email", quoted(pemFakeCACert)},
+		[]string{"Bad CA", "Websites", quoted(pemCACertBad)},
+		[]string{"CA again", "Websites", pemCACert},
+		[]string{"No PEM", "Websites", ""},
+	)
+
+	for _, tc := range []struct {
+		desc          string
+		trustBits     []string
+		wantRoots     int
+		wantUntrusted int
+		wantErrs      []string
+	}{
+		{
+			desc:      "all",
+			wantRoots: 2,
+			wantErrs: []string{
+				"record 3 (Bad CA): failed to decode PEM certificate",
+				"record 4 (CA again): duplicate root, already imported from record 1",
+				"record 5 (No PEM): missing PEM certificate",
+			},
+		},
+		{
+			desc:          "websites",
+			trustBits:     []string{"websites"},
+			wantRoots:     1,
+			wantUntrusted: 1,
+			wantErrs: []string{
+				"record 3 (Bad CA): failed to decode PEM certificate",
+				"record 4 (CA again): duplicate root, already imported from record 1",
+				"record 5 (No PEM): missing PEM certificate",
+			},
+		},
+		{
+			desc:          "code-signing",
+			trustBits:     []string{"Code Signing"},
+			wantUntrusted: 5,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := x509util.ParseCCADBCSV(strings.NewReader(report), x509util.CCADBOptions{TrustBits: tc.trustBits})
+			if err != nil {
+				t.Fatalf("x509util.ParseCCADBCSV(): %v", err)
+			}
+			if got := len(res.Pool.RawCertificates()); got != tc.wantRoots {
+				t.Errorf("got %d roots, want %d", got, tc.wantRoots)
+			}
+			if res.Untrusted != tc.wantUntrusted {
+				t.Errorf("got %d untrusted records, want %d", res.Untrusted, tc.wantUntrusted)
+			}
+			if got, want := len(res.Errors), len(tc.wantErrs); got != want {
+				t.Fatalf("got %d errors (%v), want %d", got, res.Errors, want)
+			}
+			for i, want := range tc.wantErrs {
+				if got := res.Errors[i].Error(); !strings.HasPrefix(got, want) {
+					t.Errorf("got error %q, want prefix %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCCADBCSVDuplicateIsErrDuplicateRoot(t *testing.T) {
+	report := ccadbCSV(t, []string{"PEM"}, []string{pemCACert}, []string{pemCACert})
+	res, err := x509util.ParseCCADBCSV(strings.NewReader(report), x509util.CCADBOptions{})
+	if err != nil {
+		t.Fatalf("x509util.ParseCCADBCSV(): %v", err)
+	}
+	if len(res.Errors) != 1 || !errors.Is(res.Errors[0], x509util.ErrDuplicateRoot) {
+		t.Errorf("got errors %v, want one x509util.ErrDuplicateRoot", res.Errors)
+	}
+}
+
+func TestParseCCADBCSVErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		report  string
+		opts    x509util.CCADBOptions
+		wantErr string
+	}{
+		{
+			desc:    "empty",
+			report:  "",
+			wantErr: "failed to read CCADB report header",
+		},
+		{
+			desc:    "no-pem-column",
+			report:  ccadbCSV(t, []string{"Trust Bits"}, []string{"Websites"}),
+			wantErr: "none of the PEM columns",
+		},
+		{
+			desc:    "no-trust-column",
+			report:  ccadbCSV(t, []string{"PEM Info"}, []string{quoted(pemCACert)}),
+			opts:    x509util.CCADBOptions{TrustBits: []string{"Websites"}},
+			wantErr: "none of the trust columns",
+		},
+		{
+			desc:    "malformed-csv",
+			report:  "PEM Info\n\"unterminated\n",
+			wantErr: "failed to read CCADB report",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := x509util.ParseCCADBCSV(strings.NewReader(tc.report), tc.opts)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("x509util.ParseCCADBCSV()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCCADBJSON(t *testing.T) {
+	block, _ := pem.Decode([]byte(pemFakeCACert))
+	report, err := json.Marshal([]map[string]any{
+		{"Certificate Name": "CA", "Derived Trust Bits": []string{"Server Authentication"}, "PEM": pemCACert},
+		// Raw base64 DER is accepted too.
+		{"Certificate Name": "Fake CA", "Derived Trust Bits": "Client Authentication;Server Authentication", "PEM": block.Bytes},
+		{"Certificate Name": "Untrusted", "Derived Trust Bits": "Secure Email", "PEM": pemCACertBad},
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal(): %v", err)
+	}
+
+	res, err := x509util.ParseCCADBJSON(strings.NewReader(string(report)), x509util.CCADBOptions{TrustBits: []string{"Server Authentication"}})
+	if err != nil {
+		t.Fatalf("x509util.ParseCCADBJSON(): %v", err)
+	}
+	if got, want := len(res.Pool.RawCertificates()), 2; got != want {
+		t.Errorf("got %d roots, want %d", got, want)
+	}
+	if got, want := res.Untrusted, 1; got != want {
+		t.Errorf("got %d untrusted records, want %d", got, want)
+	}
+	if len(res.Errors) != 0 {
+		t.Errorf("got errors %v, want none", res.Errors)
+	}
+
+	if _, err := x509util.ParseCCADBJSON(strings.NewReader(`{"PEM": "foo"}`), x509util.CCADBOptions{}); err == nil {
+		t.Error("x509util.ParseCCADBJSON(object)=nil, want err")
+	}
+}
+
+func TestLoadCCADBFile(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "roots.csv")
+	if err := os.WriteFile(csvPath, []byte("\ufeff"+ccadbCSV(t, []string{"PEM Info"}, []string{quoted(pemCACert)})), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	jsonPath := filepath.Join(dir, "roots.JSON")
+	if err := os.WriteFile(jsonPath, []byte(`[{"PEM Info": `+jsonString(t, pemFakeCACert)+`}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	for _, path := range []string{csvPath, jsonPath} {
+		res, err := x509util.LoadCCADBFile(path, x509util.CCADBOptions{})
+		if err != nil {
+			t.Fatalf("x509util.LoadCCADBFile(%q): %v", path, err)
+		}
+		if got, want := len(res.Pool.RawCertificates()), 1; got != want {
+			t.Errorf("x509util.LoadCCADBFile(%q): got %d roots, want %d", path, got, want)
+		}
+	}
+
+	if _, err := x509util.LoadCCADBFile(filepath.Join(dir, "missing.csv"), x509util.CCADBOptions{}); err == nil {
+		t.Error("x509util.LoadCCADBFile(missing)=nil, want err")
+	}
+}
+
+func jsonString(t *testing.T, s string) string {
+	t.Helper()
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal(): %v", err)
+	}
+	return string(b)
+}
@@ -19,11 +19,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 	"github.com/transparency-dev/tesseract/storage"
 	"k8s.io/klog/v2"
@@ -61,6 +65,50 @@ func (s *IssuersStorage) keyToObjName(key []byte) string {
 	return path.Join(s.prefix, string(key))
 }
 
+// Get returns the issuer stored under key.
+//
+// Returns an error wrapping os.ErrNotExist if there is no such issuer.
+func (s *IssuersStorage) Get(ctx context.Context, key []byte) ([]byte, error) {
+	objName := s.keyToObjName(key)
+	out, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objName),
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, fmt.Errorf("object %q not found in bucket %q: %w", objName, s.bucket, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read object %q from bucket %q: %v", objName, s.bucket, err)
+	}
+	defer func() {
+		if err := out.Body.Close(); err != nil {
+			klog.Warningf("Get: failed to close body of %q: %v", objName, err)
+		}
+	}()
+	d, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %q from bucket %q: %v", objName, s.bucket, err)
+	}
+	return d, nil
+}
+
+// Exists returns whether an issuer is stored under key.
+func (s *IssuersStorage) Exists(ctx context.Context, key []byte) (bool, error) {
+	objName := s.keyToObjName(key)
+	if _, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objName),
+	}); err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head object %q in bucket %q: %v", objName, s.bucket, err)
+	}
+	return true, nil
+}
+
 // AddIssuers stores Issuers values under their Key if there isn't an object under Key already.
 func (s *IssuersStorage) AddIssuersIfNotExist(ctx context.Context, kv []storage.KV) error {
 	// We first try and see if this issuer cert has already been stored since reads
@@ -80,9 +128,9 @@ func (s *IssuersStorage) AddIssuersIfNotExist(ctx context.Context, kv []storage.
 		// If so, we can consider this write to be idempotently successful.
 		if _, err := s.s3Client.PutObject(ctx, put); err != nil {
 			var apiErr smithy.APIError
-			if errors.As(err, &apiErr); apiErr.ErrorCode() == "PreconditionFailed" {
+			if errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
 				klog.V(2).Infof("AddIssuersIfNotExist: object %q already exists in bucket %q, continuing", objName, s.bucket)
-				return nil
+				continue
 			}
 			return fmt.Errorf("failed to write object %q to bucket %q: %w", objName, s.bucket, err)
 		}
@@ -90,3 +138,29 @@ func (s *IssuersStorage) AddIssuersIfNotExist(ctx context.Context, kv []storage.
 	}
 	return nil
 }
+
+// List returns the keys of all the issuers stored in the bucket under prefix.
+func (s *IssuersStorage) List(ctx context.Context) ([][]byte, error) {
+	prefix := s.keyToObjName(nil)
+	if prefix != "" && prefix != "." {
+		prefix += "/"
+	} else {
+		prefix = ""
+	}
+
+	keys := [][]byte{}
+	p := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects with prefix %q in bucket %q: %v", prefix, s.bucket, err)
+		}
+		for _, o := range page.Contents {
+			keys = append(keys, []byte(strings.TrimPrefix(aws.ToString(o.Key), prefix)))
+		}
+	}
+	return keys, nil
+}
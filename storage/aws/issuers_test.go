@@ -0,0 +1,168 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/transparency-dev/tesseract/storage"
+)
+
+const testBucket = "bucket"
+
+// fakeS3 serves a single bucket from memory, over the subset of the S3 API
+// used by IssuersStorage.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name, ok := strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	d, exists := f.objects[name]
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		_, _ = w.Write(d)
+	case http.MethodHead:
+		if !exists {
+			// HEAD responses have no body to carry an error code.
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(d)))
+	case http.MethodPut:
+		if exists && r.Header.Get("If-None-Match") == "*" {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		d, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.objects[name] = d
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, code int, s3Code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", s3Code, s3Code)
+}
+
+// newTestIssuerStorage returns an IssuersStorage backed by a fakeS3 holding
+// objects.
+func newTestIssuerStorage(t *testing.T, objects map[string][]byte) *IssuersStorage {
+	t.Helper()
+	srv := httptest.NewServer(&fakeS3{objects: objects})
+	t.Cleanup(srv.Close)
+	return &IssuersStorage{
+		s3Client: s3.New(s3.Options{
+			BaseEndpoint: aws.String(srv.URL),
+			Credentials:  aws.AnonymousCredentials{},
+			Region:       "us-east-1",
+			UsePathStyle: true,
+		}),
+		bucket:      testBucket,
+		prefix:      "fingerprints",
+		contentType: "application/pkix-cert",
+	}
+}
+
+func TestAddIssuersIfNotExist(t *testing.T) {
+	objects := map[string][]byte{"fingerprints/a": []byte("a data")}
+	s := newTestIssuerStorage(t, objects)
+
+	kvs := []storage.KV{
+		{K: []byte("a"), V: []byte("a data")},
+		{K: []byte("b"), V: []byte("b data")},
+	}
+	if err := s.AddIssuersIfNotExist(t.Context(), kvs); err != nil {
+		t.Fatalf("AddIssuersIfNotExist(): %v", err)
+	}
+	// Issuers following an existing one are stored too.
+	if got, want := string(objects["fingerprints/b"]), "b data"; got != want {
+		t.Errorf("fingerprints/b holds %q, want %q", got, want)
+	}
+}
+
+func TestAddIssuersIfNotExistError(t *testing.T) {
+	// Requests fail before reaching S3, with an error which isn't an API
+	// error.
+	s := &IssuersStorage{
+		s3Client: s3.New(s3.Options{
+			BaseEndpoint:     aws.String("http://127.0.0.1:0"),
+			Credentials:      aws.AnonymousCredentials{},
+			Region:           "us-east-1",
+			UsePathStyle:     true,
+			RetryMaxAttempts: 1,
+		}),
+		bucket: testBucket,
+	}
+	if err := s.AddIssuersIfNotExist(t.Context(), []storage.KV{{K: []byte("a"), V: []byte("a data")}}); err == nil {
+		t.Error("AddIssuersIfNotExist()=nil, want error")
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := newTestIssuerStorage(t, map[string][]byte{"fingerprints/a": []byte("a data")})
+
+	got, err := s.Get(t.Context(), []byte("a"))
+	if err != nil {
+		t.Fatalf("Get(a): %v", err)
+	}
+	if want := "a data"; string(got) != want {
+		t.Errorf("Get(a)=%q, want %q", got, want)
+	}
+	if _, err := s.Get(t.Context(), []byte("b")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get(b)=%v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestExists(t *testing.T) {
+	s := newTestIssuerStorage(t, map[string][]byte{"fingerprints/a": []byte("a data")})
+
+	for key, want := range map[string]bool{"a": true, "b": false} {
+		got, err := s.Exists(t.Context(), []byte(key))
+		if err != nil {
+			t.Fatalf("Exists(%s): %v", key, err)
+		}
+		if got != want {
+			t.Errorf("Exists(%s)=%t, want %t", key, got, want)
+		}
+	}
+}
@@ -16,13 +16,18 @@ package gcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path"
+	"strings"
 
 	gcs "cloud.google.com/go/storage"
 	"github.com/transparency-dev/tesseract/storage"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 	"k8s.io/klog/v2"
 )
 
@@ -56,6 +61,42 @@ func (s *IssuersStorage) keyToObjName(key []byte) string {
 	return path.Join(s.prefix, string(key))
 }
 
+// Get returns the issuer stored under key.
+//
+// Returns an error wrapping os.ErrNotExist if there is no such issuer.
+func (s *IssuersStorage) Get(ctx context.Context, key []byte) ([]byte, error) {
+	objName := s.keyToObjName(key)
+	r, err := s.bucket.Object(objName).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return nil, fmt.Errorf("object %q not found in bucket %q: %w", objName, s.bucket.BucketName(), os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read object %q from bucket %q: %v", objName, s.bucket.BucketName(), err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			klog.Warningf("Get: failed to close reader on %q: %v", objName, err)
+		}
+	}()
+	d, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %q from bucket %q: %v", objName, s.bucket.BucketName(), err)
+	}
+	return d, nil
+}
+
+// Exists returns whether an issuer is stored under key.
+func (s *IssuersStorage) Exists(ctx context.Context, key []byte) (bool, error) {
+	objName := s.keyToObjName(key)
+	if _, err := s.bucket.Object(objName).Attrs(ctx); err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get attributes of object %q in bucket %q: %v", objName, s.bucket.BucketName(), err)
+	}
+	return true, nil
+}
+
 // AddIssuers stores Issuers values under their Key if there isn't an object under Key already.
 func (s *IssuersStorage) AddIssuersIfNotExist(ctx context.Context, kv []storage.KV) error {
 	// We first try and see if this issuer cert has already been stored since reads
@@ -73,13 +114,9 @@ func (s *IssuersStorage) AddIssuersIfNotExist(ctx context.Context, kv []storage.
 		}
 
 		if err := w.Close(); err != nil {
-			if ee, ok := err.(*googleapi.Error); ok && ee.Code == http.StatusPreconditionFailed {
-				for _, e := range ee.Errors {
-					if e.Reason == "conditionNotMet" {
-						klog.V(2).Infof("AddIssuersIfNotExist: object %q already exists in bucket %q, continuing", objName, s.bucket.BucketName())
-						return nil
-					}
-				}
+			if isConditionNotMet(err) {
+				klog.V(2).Infof("AddIssuersIfNotExist: object %q already exists in bucket %q, continuing", objName, s.bucket.BucketName())
+				continue
 			}
 
 			return fmt.Errorf("failed to close write on %q: %v", objName, err)
@@ -89,3 +126,43 @@ func (s *IssuersStorage) AddIssuersIfNotExist(ctx context.Context, kv []storage.
 	}
 	return nil
 }
+
+// isConditionNotMet returns true if err is due to a GCS precondition not being met.
+func isConditionNotMet(err error) bool {
+	if ee, ok := err.(*googleapi.Error); ok && ee.Code == http.StatusPreconditionFailed {
+		for _, e := range ee.Errors {
+			if e.Reason == "conditionNotMet" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// List returns the keys of all the issuers stored in the bucket under prefix.
+func (s *IssuersStorage) List(ctx context.Context) ([][]byte, error) {
+	prefix := s.keyToObjName(nil)
+	if prefix != "" && prefix != "." {
+		prefix += "/"
+	} else {
+		prefix = ""
+	}
+	q := &gcs.Query{Prefix: prefix}
+	if err := q.SetAttrSelection([]string{"Name"}); err != nil {
+		return nil, fmt.Errorf("failed to set query attributes: %v", err)
+	}
+
+	keys := [][]byte{}
+	it := s.bucket.Objects(ctx, q)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects with prefix %q in bucket %q: %v", prefix, s.bucket.BucketName(), err)
+		}
+		keys = append(keys, []byte(strings.TrimPrefix(attrs.Name, prefix)))
+	}
+	return keys, nil
+}
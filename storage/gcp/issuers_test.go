@@ -0,0 +1,187 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/transparency-dev/tesseract/storage"
+	"google.golang.org/api/option"
+)
+
+const testBucket = "bucket"
+
+// fakeGCS serves a single bucket from memory, over the subset of the GCS
+// JSON API used by IssuersStorage.
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objPrefix := "/storage/v1/b/" + testBucket + "/o/"
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, objPrefix):
+		name := strings.TrimPrefix(r.URL.Path, objPrefix)
+		d, ok := f.objects[name]
+		if !ok {
+			writeGCSError(w, http.StatusNotFound, "notFound")
+			return
+		}
+		if r.URL.Query().Get("alt") == "media" {
+			_, _ = w.Write(d)
+			return
+		}
+		writeGCSObject(w, name, d)
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/"+testBucket+"/o":
+		name, d, err := readUpload(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, ok := f.objects[name]; ok && r.URL.Query().Get("ifGenerationMatch") == "0" {
+			writeGCSError(w, http.StatusPreconditionFailed, "conditionNotMet")
+			return
+		}
+		f.objects[name] = d
+		writeGCSObject(w, name, d)
+	default:
+		http.Error(w, "unsupported request", http.StatusNotImplemented)
+	}
+}
+
+// readUpload returns the name and content of the object uploaded by a
+// multipart upload request.
+func readUpload(r *http.Request) (string, []byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", nil, err
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	p, err := mr.NextPart()
+	if err != nil {
+		return "", nil, err
+	}
+	var attrs struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(p).Decode(&attrs); err != nil {
+		return "", nil, err
+	}
+	if p, err = mr.NextPart(); err != nil {
+		return "", nil, err
+	}
+	d, err := io.ReadAll(p)
+	return attrs.Name, d, err
+}
+
+func writeGCSObject(w http.ResponseWriter, name string, d []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"bucket": testBucket,
+		"name":   name,
+		"size":   fmt.Sprint(len(d)),
+	})
+}
+
+func writeGCSError(w http.ResponseWriter, code int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"message": reason,
+			"errors":  []map[string]string{{"reason": reason}},
+		},
+	})
+}
+
+// newTestIssuerStorage returns an IssuersStorage backed by a fakeGCS holding
+// objects.
+func newTestIssuerStorage(t *testing.T, objects map[string][]byte) *IssuersStorage {
+	t.Helper()
+	srv := httptest.NewServer(&fakeGCS{objects: objects})
+	t.Cleanup(srv.Close)
+	c, err := gcs.NewClient(t.Context(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication(), gcs.WithJSONReads())
+	if err != nil {
+		t.Fatalf("NewClient(): %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return &IssuersStorage{
+		bucket:      c.Bucket(testBucket),
+		prefix:      "fingerprints",
+		contentType: "application/pkix-cert",
+	}
+}
+
+func TestAddIssuersIfNotExist(t *testing.T) {
+	objects := map[string][]byte{"fingerprints/a": []byte("a data")}
+	s := newTestIssuerStorage(t, objects)
+
+	kvs := []storage.KV{
+		{K: []byte("a"), V: []byte("a data")},
+		{K: []byte("b"), V: []byte("b data")},
+	}
+	if err := s.AddIssuersIfNotExist(t.Context(), kvs); err != nil {
+		t.Fatalf("AddIssuersIfNotExist(): %v", err)
+	}
+	// Issuers following an existing one are stored too.
+	if got, want := string(objects["fingerprints/b"]), "b data"; got != want {
+		t.Errorf("fingerprints/b holds %q, want %q", got, want)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := newTestIssuerStorage(t, map[string][]byte{"fingerprints/a": []byte("a data")})
+
+	got, err := s.Get(t.Context(), []byte("a"))
+	if err != nil {
+		t.Fatalf("Get(a): %v", err)
+	}
+	if want := "a data"; string(got) != want {
+		t.Errorf("Get(a)=%q, want %q", got, want)
+	}
+	if _, err := s.Get(t.Context(), []byte("b")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get(b)=%v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestExists(t *testing.T) {
+	s := newTestIssuerStorage(t, map[string][]byte{"fingerprints/a": []byte("a data")})
+
+	for key, want := range map[string]bool{"a": true, "b": false} {
+		got, err := s.Exists(t.Context(), []byte(key))
+		if err != nil {
+			t.Fatalf("Exists(%s): %v", key, err)
+		}
+		if got != want {
+			t.Errorf("Exists(%s)=%t, want %t", key, got, want)
+		}
+	}
+}
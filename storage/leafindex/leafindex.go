@@ -0,0 +1,307 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leafindex maintains a mapping from Merkle leaf hashes to their index
+// in a https://c2sp.org/static-ct-api log.
+//
+// Static CT logs do not store this mapping, which is needed to serve RFC 6962
+// get-proof-by-hash requests, or to check whether a certificate has already
+// been logged. The index is populated by following the log's entry bundles,
+// and stored in BadgerDB (https://github.com/hypermodeinc/badger).
+package leafindex
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/ctonly"
+	"github.com/transparency-dev/tesseract/internal/types/staticct"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultPollInterval is the default interval between two checks for new
+	// entries in the log.
+	DefaultPollInterval = time.Second
+)
+
+var (
+	// nextKey stores the index of the next log entry to add to the index.
+	// It can't collide with leaf hash keys, which are 32 bytes long.
+	nextKey = []byte("@nextIdx")
+)
+
+// LogReader provides the functions needed to follow a log.
+//
+// It is implemented by tessera.LogReader.
+type LogReader interface {
+	// IntegratedSize returns the current size of the integrated tree.
+	IntegratedSize(ctx context.Context) (uint64, error)
+	// ReadEntryBundle returns the entry bundle at the given index, with partial size p.
+	ReadEntryBundle(ctx context.Context, index uint64, p uint8) ([]byte, error)
+}
+
+// Options allows configuration of some tunable options.
+type Options struct {
+	// PollInterval is the interval between two checks for new entries in the
+	// log, once the index has caught up with it.
+	PollInterval time.Duration
+}
+
+// Index maps Merkle leaf hashes to their index in a log.
+type Index struct {
+	opts Options
+	db   *badger.DB
+	// stopGC stops the value log garbage collection of db, and gcDone is
+	// closed once it has stopped.
+	stopGC context.CancelFunc
+	gcDone chan struct{}
+}
+
+// New returns an Index stored in a Badger database located at path. The
+// database is created if it doesn't exist.
+//
+// The index is only populated once Follow is called. The database is garbage
+// collected in the background, until ctx is done or the index is closed.
+func New(ctx context.Context, path string, opts Options) (*Index, error) {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	db, err := badger.Open(badger.DefaultOptions(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open badger: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	i := &Index{opts: opts, db: db, stopGC: cancel, gcDone: make(chan struct{})}
+	go i.runValueLogGC(ctx)
+	return i, nil
+}
+
+// runValueLogGC periodically garbage collects the value log of the database,
+// until ctx is done.
+func (i *Index) runValueLogGC(ctx context.Context) {
+	defer close(i.gcDone)
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for i.db.RunValueLogGC(0.7) == nil {
+		}
+	}
+}
+
+// Close stops the garbage collection of the underlying database, and closes
+// it.
+//
+// The index must not be used after it has been closed, and Follow must have
+// returned.
+func (i *Index) Close() error {
+	i.stopGC()
+	<-i.gcDone
+	return i.db.Close()
+}
+
+// LeafIndex returns the index of the entry with the given Merkle leaf hash, or
+// an error wrapping os.ErrNotExist if the index does not know about it.
+func (i *Index) LeafIndex(ctx context.Context, leafHash []byte) (uint64, error) {
+	_, span := tracer.Start(ctx, "tesseract.leafindex.LeafIndex")
+	defer span.End()
+
+	var idx uint64
+	err := i.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(leafHash)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return fmt.Errorf("leaf hash %x: %w", leafHash, os.ErrNotExist)
+		} else if err != nil {
+			return fmt.Errorf("failed to get leaf hash %x: %v", leafHash, err)
+		}
+		return item.Value(func(v []byte) error {
+			idx = binary.BigEndian.Uint64(v)
+			return nil
+		})
+	})
+	return idx, err
+}
+
+// EntriesProcessed returns the number of log entries that have been added to
+// the index.
+//
+// Entries are added in order: every entry with an index lower than the
+// returned value can be looked up.
+func (i *Index) EntriesProcessed(ctx context.Context) (uint64, error) {
+	var next uint64
+	err := i.db.View(func(txn *badger.Txn) error {
+		var err error
+		next, err = readNext(txn)
+		return err
+	})
+	return next, err
+}
+
+// Follow populates the index with entries from the log read by lr, until ctx
+// is done.
+//
+// Following resumes from the last entry added to the index, so that it can
+// be restarted without re-processing the whole log.
+func (i *Index) Follow(ctx context.Context, lr LogReader) {
+	t := time.NewTicker(i.opts.PollInterval)
+	defer t.Stop()
+	for {
+		size, err := lr.IntegratedSize(ctx)
+		if err != nil {
+			klog.Errorf("leafindex: IntegratedSize(): %v", err)
+		}
+		// Busy loop while there's work to be done.
+		for err == nil {
+			var added uint64
+			added, err = i.indexBundle(ctx, lr, size)
+			if err != nil {
+				klog.Errorf("leafindex: failed to index entries: %v", err)
+			}
+			if added == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Start follows the log read by lr in the background, until the returned
+// function is called. This function stops following, waits for Follow to
+// return, and closes the index.
+func (i *Index) Start(ctx context.Context, lr LogReader) func() error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		i.Follow(ctx, lr)
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return i.Close()
+	}
+}
+
+// indexBundle adds the next entries to the index, up to the end of their
+// entry bundle, or to size. It returns the number of entries added.
+func (i *Index) indexBundle(ctx context.Context, lr LogReader, size uint64) (uint64, error) {
+	ctx, span := tracer.Start(ctx, "tesseract.leafindex.indexBundle")
+	defer span.End()
+
+	var added uint64
+	err := i.db.Update(func(txn *badger.Txn) error {
+		next, err := readNext(txn)
+		if err != nil {
+			return err
+		}
+		if next >= size {
+			return nil
+		}
+
+		bundleIdx := next / layout.EntryBundleWidth
+		p := layout.PartialTileSize(0, bundleIdx, size)
+		raw, err := lr.ReadEntryBundle(ctx, bundleIdx, p)
+		if err != nil {
+			return fmt.Errorf("failed to read entry bundle %d.%d: %v", bundleIdx, p, err)
+		}
+		eb := staticct.EntryBundle{}
+		if err := eb.UnmarshalText(raw); err != nil {
+			return fmt.Errorf("failed to parse entry bundle %d.%d: %v", bundleIdx, p, err)
+		}
+
+		for j := next % layout.EntryBundleWidth; j < uint64(len(eb.Entries)); j++ {
+			idx := bundleIdx*layout.EntryBundleWidth + j
+			leafIdx, leafHash, err := merkleLeafHash(eb.Entries[j])
+			if err != nil {
+				return fmt.Errorf("entry %d: %v", idx, err)
+			}
+			if leafIdx != idx {
+				return fmt.Errorf("entry %d has leaf index %d", idx, leafIdx)
+			}
+			v := make([]byte, 8)
+			binary.BigEndian.PutUint64(v, idx)
+			if err := txn.Set(leafHash, v); err != nil {
+				return fmt.Errorf("failed to set leaf hash for entry %d: %v", idx, err)
+			}
+			added++
+		}
+
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, next+added)
+		if err := txn.Set(nextKey, v); err != nil {
+			return fmt.Errorf("failed to update follower state: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// readNext returns the index of the next log entry to add to the index.
+func readNext(txn *badger.Txn) (uint64, error) {
+	item, err := txn.Get(nextKey)
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound):
+		// Nothing has been indexed yet.
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("failed to get nextIdx: %v", err)
+	}
+	var next uint64
+	if err := item.Value(func(v []byte) error {
+		next = binary.BigEndian.Uint64(v)
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("failed to get nextIdx value: %v", err)
+	}
+	return next, nil
+}
+
+// merkleLeafHash returns the leaf index and the RFC 6962 Merkle leaf hash of
+// a https://c2sp.org/static-ct-api entry.
+func merkleLeafHash(raw []byte) (uint64, []byte, error) {
+	e := staticct.Entry{}
+	if err := e.UnmarshalText(raw); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse entry: %v", err)
+	}
+	ctEntry := ctonly.Entry{
+		Timestamp:      e.Timestamp,
+		IsPrecert:      e.IsPrecert,
+		Certificate:    e.Certificate,
+		Precertificate: e.Precertificate,
+		IssuerKeyHash:  e.IssuerKeyHash,
+	}
+	return e.LeafIndex, ctEntry.MerkleLeafHash(e.LeafIndex), nil
+}
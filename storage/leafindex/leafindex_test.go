@@ -0,0 +1,168 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leafindex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/ctonly"
+)
+
+// fakeLog is an in-memory LogReader.
+type fakeLog struct {
+	mu      sync.Mutex
+	entries []ctonly.Entry
+}
+
+func (f *fakeLog) add(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for range n {
+		i := len(f.entries)
+		f.entries = append(f.entries, ctonly.Entry{
+			Timestamp:   uint64(1000 + i),
+			Certificate: fmt.Appendf(nil, "cert %d", i),
+		})
+	}
+}
+
+func (f *fakeLog) leafHash(i uint64) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.entries[i].MerkleLeafHash(i)
+}
+
+func (f *fakeLog) IntegratedSize(_ context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return uint64(len(f.entries)), nil
+}
+
+func (f *fakeLog) ReadEntryBundle(_ context.Context, index uint64, p uint8) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	start := index * layout.EntryBundleWidth
+	end := start + uint64(p)
+	if p == 0 {
+		end = start + layout.EntryBundleWidth
+	}
+	if end > uint64(len(f.entries)) {
+		return nil, os.ErrNotExist
+	}
+	var b bytes.Buffer
+	for i := start; i < end; i++ {
+		b.Write(f.entries[i].LeafData(i))
+	}
+	return b.Bytes(), nil
+}
+
+// waitForEntries waits until the index has processed at least n entries.
+func waitForEntries(t *testing.T, idx *Index, n uint64) {
+	t.Helper()
+	for {
+		got, err := idx.EntriesProcessed(t.Context())
+		if err != nil {
+			t.Fatalf("EntriesProcessed(): %v", err)
+		}
+		if got >= n {
+			return
+		}
+		select {
+		case <-t.Context().Done():
+			t.Fatalf("index did not process %d entries: %v", n, t.Context().Err())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{PollInterval: 10 * time.Millisecond}
+	log := &fakeLog{}
+	// Cross a bundle boundary, and leave a partial bundle.
+	log.add(300)
+
+	idx, err := New(t.Context(), dir, opts)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	stop := idx.Start(t.Context(), log)
+	waitForEntries(t, idx, 300)
+
+	for _, i := range []uint64{0, 1, 255, 256, 299} {
+		got, err := idx.LeafIndex(t.Context(), log.leafHash(i))
+		if err != nil {
+			t.Errorf("LeafIndex(%d): %v", i, err)
+		} else if got != i {
+			t.Errorf("LeafIndex(%d): got %d", i, got)
+		}
+	}
+	if _, err := idx.LeafIndex(t.Context(), make([]byte, 32)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LeafIndex(unknown): got err %v, want %v", err, os.ErrNotExist)
+	}
+
+	// Grow the log while being followed.
+	log.add(10)
+	waitForEntries(t, idx, 310)
+	if got, err := idx.LeafIndex(t.Context(), log.leafHash(309)); err != nil || got != 309 {
+		t.Errorf("LeafIndex(309): got (%d, %v), want (309, nil)", got, err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("stop(): %v", err)
+	}
+
+	// Restart, and check that following resumes where it left off.
+	log.add(5)
+	idx, err = New(t.Context(), dir, opts)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if got, err := idx.EntriesProcessed(t.Context()); err != nil || got != 310 {
+		_ = idx.Close()
+		t.Fatalf("EntriesProcessed() after restart: got (%d, %v), want (310, nil)", got, err)
+	}
+	stop = idx.Start(t.Context(), log)
+	defer func() { _ = stop() }()
+	waitForEntries(t, idx, 315)
+	for _, i := range []uint64{0, 309, 314} {
+		if got, err := idx.LeafIndex(t.Context(), log.leafHash(i)); err != nil || got != i {
+			t.Errorf("LeafIndex(%d): got (%d, %v), want (%d, nil)", i, got, err, i)
+		}
+	}
+}
+
+func TestCloseStopsGC(t *testing.T) {
+	// The parent context stays live after Close.
+	idx, err := New(t.Context(), t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	select {
+	case <-idx.gcDone:
+	default:
+		t.Error("garbage collection still running after Close()")
+	}
+}
@@ -16,10 +16,34 @@ package storage
 
 import (
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"k8s.io/klog/v2"
 )
 
 const name = "github.com/transparency-dev/tesseract/storage"
 
 var (
+	meter  = otel.Meter(name)
 	tracer = otel.Tracer(name)
 )
+
+var (
+	successKey = attribute.Key("tesseract.success")
+	hitKey     = attribute.Key("tesseract.cache.hit")
+)
+
+var shutdownDuration = mustCreate(meter.Float64Histogram("tesseract.storage.shutdown.duration",
+	metric.WithDescription("Time taken to integrate outstanding entries and publish a checkpoint on shutdown"),
+	metric.WithUnit("s")))
+
+var timestampCacheLookups = mustCreate(meter.Int64Counter("tesseract.storage.timestamp_cache.lookups",
+	metric.WithDescription("Number of lookups of the timestamp of an entry in the cache, when handling a duplicate submission"),
+	metric.WithUnit("{lookup}")))
+
+func mustCreate[T any](t T, err error) T {
+	if err != nil {
+		klog.Exit(err.Error())
+	}
+	return t
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package posix implements an issuer storage system on a local filesystem.
+package posix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/transparency-dev/tesseract/storage"
+	"k8s.io/klog/v2"
+)
+
+const (
+	dirPerm  = 0o755
+	filePerm = 0o644
+)
+
+// IssuersStorage is a key value store backed by the local filesystem to store issuer chains.
+type IssuersStorage string
+
+// NewIssuerStorage creates a new IssuerStorage.
+//
+// It creates the underying directory if it does not exist already.
+func NewIssuerStorage(path string) (IssuersStorage, error) {
+	// Does nothing if the dictory already exists.
+	if err := os.MkdirAll(path, dirPerm); err != nil {
+		return "", fmt.Errorf("failed to create path %q: %v", path, err)
+	}
+	return IssuersStorage(path), nil
+}
+
+// keyToObjName converts bytes to filesystem path.
+//
+// empty keys, and keys including a '/' character are not allowed to avoid
+// confusion with directory names. This list of exclusions is not exhaustive,
+// and does not guarantee that it will fit all filesystems.
+func (s IssuersStorage) keyToObjName(key []byte) (string, error) {
+	if string(key) == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+	if strings.Contains(string(key), string(os.PathSeparator)) {
+		return "", fmt.Errorf("key %q cannot contain '/'", string(key))
+	}
+	return path.Join(string(s), string(key)), nil
+}
+
+// Get returns the issuer stored under key.
+//
+// Returns an error wrapping os.ErrNotExist if there is no such issuer.
+func (s IssuersStorage) Get(_ context.Context, key []byte) ([]byte, error) {
+	objName, err := s.keyToObjName(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert key to object name: %v", err)
+	}
+	d, err := os.ReadFile(objName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %q: %w", objName, err)
+	}
+	return d, nil
+}
+
+// Exists returns whether an issuer is stored under key.
+func (s IssuersStorage) Exists(_ context.Context, key []byte) (bool, error) {
+	objName, err := s.keyToObjName(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert key to object name: %v", err)
+	}
+	if _, err := os.Stat(objName); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat object %q: %v", objName, err)
+	}
+	return true, nil
+}
+
+// List returns the keys of all the stored issuers.
+//
+// Temporary files left behind by interrupted writes are skipped.
+func (s IssuersStorage) List(_ context.Context) ([][]byte, error) {
+	entries, err := os.ReadDir(string(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %q: %v", string(s), err)
+	}
+	keys := make([][]byte, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".tmp-") {
+			continue
+		}
+		keys = append(keys, []byte(e.Name()))
+	}
+	return keys, nil
+}
+
+// AddIssuers stores Issuers values under their Key if there isn't an object under Key already.
+//
+// Objects are written to a temporary file first, and then atomically linked
+// under their final name. This guarantees that readers never observe partially
+// written objects, and that concurrent writers, including other processes
+// sharing the same directory, can't overwrite each other.
+func (s IssuersStorage) AddIssuersIfNotExist(_ context.Context, kv []storage.KV) error {
+	for _, kv := range kv {
+		objName, err := s.keyToObjName(kv.K)
+		if err != nil {
+			return fmt.Errorf("failed to convert key to object name: %v", err)
+		}
+		// We first try and see if this issuer cert has already been stored.
+		if f, err := os.ReadFile(objName); err == nil {
+			if bytes.Equal(f, kv.V) {
+				klog.V(2).Infof("AddIssuersIfNotExist: object %q already exists with identical contents, continuing", objName)
+				continue
+			}
+			return fmt.Errorf("object %q already exists with different content", objName)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read object %q: %v", objName, err)
+		}
+
+		if err := s.createExclusive(objName, kv.V); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				// Someone else wrote this object in the meantime, check that they
+				// wrote the same thing.
+				f, err := os.ReadFile(objName)
+				if err != nil {
+					return fmt.Errorf("failed to read object %q: %v", objName, err)
+				}
+				if !bytes.Equal(f, kv.V) {
+					return fmt.Errorf("object %q already exists with different content", objName)
+				}
+				klog.V(2).Infof("AddIssuersIfNotExist: object %q concurrently added with identical contents, continuing", objName)
+				continue
+			}
+			return fmt.Errorf("failed to write object %q: %w", objName, err)
+		}
+		klog.V(2).Infof("AddIssuersIfNotExist: added %q", objName)
+	}
+	return nil
+}
+
+// createExclusive atomically creates a file at objName with contents d.
+//
+// Returns an error wrapping os.ErrExist if a file already exists at objName.
+func (s IssuersStorage) createExclusive(objName string, d []byte) (err error) {
+	tmp, err := os.CreateTemp(string(s), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer func() {
+		if rErr := os.Remove(tmp.Name()); rErr != nil && !errors.Is(rErr, os.ErrNotExist) {
+			klog.Warningf("failed to remove temporary file %q: %v", tmp.Name(), rErr)
+		}
+	}()
+
+	if _, err := tmp.Write(d); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file %q: %v", tmp.Name(), err)
+	}
+	if err := tmp.Chmod(filePerm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to chmod temporary file %q: %v", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temporary file %q: %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file %q: %v", tmp.Name(), err)
+	}
+
+	// Unlike rename, link fails if the target already exists.
+	if err := os.Link(tmp.Name(), objName); err != nil {
+		return err
+	}
+	return syncDir(string(s))
+}
+
+// syncDir makes sure that directory entries in dir are persisted.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %q: %v", dir, err)
+	}
+	if err := d.Sync(); err != nil {
+		_ = d.Close()
+		return fmt.Errorf("failed to sync directory %q: %v", dir, err)
+	}
+	return d.Close()
+}
@@ -16,10 +16,12 @@ package posix
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/transparency-dev/tesseract/storage"
@@ -213,3 +215,121 @@ func TestAddIssuersIfNotExist(t *testing.T) {
 		})
 	}
 }
+
+func TestAddIssuersIfNotExistConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	s, err := NewIssuerStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewIssuerStorage() failed: %v", err)
+	}
+
+	kv := []storage.KV{{K: []byte("issuer1"), V: []byte("issuer1 data")}}
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.AddIssuersIfNotExist(context.Background(), kv)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("AddIssuersIfNotExist() error = %v", err)
+		}
+	}
+
+	// Only the issuer should be left, no temporary files.
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory %q: %v", tmpDir, err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "issuer1" {
+		t.Errorf("Unexpected directory contents: %v", entries)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s, err := NewIssuerStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewIssuerStorage() failed: %v", err)
+	}
+	if err := s.AddIssuersIfNotExist(context.Background(), []storage.KV{{K: []byte("issuer1"), V: []byte("issuer1 data")}}); err != nil {
+		t.Fatalf("AddIssuersIfNotExist() failed: %v", err)
+	}
+
+	got, err := s.Get(context.Background(), []byte("issuer1"))
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if want := []byte("issuer1 data"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %q, want %q", got, want)
+	}
+
+	if _, err := s.Get(context.Background(), []byte("issuer2")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get() on missing issuer: got err %v, want os.ErrNotExist", err)
+	}
+	if _, err := s.Get(context.Background(), []byte("dir1/issuer1")); err == nil {
+		t.Error("Get() with invalid key: got nil error, want error")
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewIssuerStorage(dir)
+	if err != nil {
+		t.Fatalf("NewIssuerStorage() failed: %v", err)
+	}
+	if err := s.AddIssuersIfNotExist(context.Background(), []storage.KV{
+		{K: []byte("issuer1"), V: []byte("issuer1 data")},
+		{K: []byte("issuer2"), V: []byte("issuer2 data")},
+	}); err != nil {
+		t.Fatalf("AddIssuersIfNotExist() failed: %v", err)
+	}
+	// Leftovers from interrupted writes, and directories, are not issuers.
+	if err := os.WriteFile(filepath.Join(dir, ".tmp-123"), []byte("partial"), filePerm); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "dir1"), dirPerm); err != nil {
+		t.Fatalf("Mkdir() failed: %v", err)
+	}
+
+	got, err := s.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if want := [][]byte{[]byte("issuer1"), []byte("issuer2")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %q, want %q", got, want)
+	}
+}
+
+func TestExists(t *testing.T) {
+	s, err := NewIssuerStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewIssuerStorage() failed: %v", err)
+	}
+	if err := s.AddIssuersIfNotExist(context.Background(), []storage.KV{{K: []byte("issuer1"), V: []byte("issuer1 data")}}); err != nil {
+		t.Fatalf("AddIssuersIfNotExist() failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		key     string
+		want    bool
+		wantErr bool
+	}{
+		{key: "issuer1", want: true},
+		{key: "issuer2", want: false},
+		{key: "dir1/issuer1", wantErr: true},
+	} {
+		got, err := s.Exists(context.Background(), []byte(tc.key))
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("Exists(%q) = %v, want error %t", tc.key, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Exists(%q) = %t, want %t", tc.key, got, tc.want)
+		}
+	}
+}
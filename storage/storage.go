@@ -24,13 +24,16 @@ import (
 	"fmt"
 	"os"
 	"strconv"
-	"sync"
+	"sync/atomic"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/transparency-dev/tessera"
 	"github.com/transparency-dev/tessera/api/layout"
 	"github.com/transparency-dev/tessera/ctonly"
 	"github.com/transparency-dev/tesseract/internal/types/staticct"
+	"github.com/transparency-dev/tesseract/storage/leafindex"
+	"go.opentelemetry.io/otel/metric"
 	"golang.org/x/mod/sumdb/note"
 	"k8s.io/klog/v2"
 )
@@ -45,6 +48,14 @@ const (
 	maxCachedIssuerKeys = 1 << 20
 )
 
+// ErrShuttingDown is returned when adding entries or issuers to a CTStorage
+// which is shutting down.
+var ErrShuttingDown = errors.New("storage is shutting down")
+
+// ErrLeafIndexNotSupported is returned when looking up leaves by hash in a
+// CTStorage without a LeafIndexReader.
+var ErrLeafIndexNotSupported = errors.New("storage does not support leaf index lookups")
+
 type KV struct {
 	K []byte
 	V []byte
@@ -53,26 +64,67 @@ type KV struct {
 // IssuerStorage issuer certificates under their hex encoded sha256.
 type IssuerStorage interface {
 	AddIssuersIfNotExist(ctx context.Context, kv []KV) error
+	// Get returns the issuer stored under key, or an error wrapping
+	// os.ErrNotExist if there is no such issuer.
+	Get(ctx context.Context, key []byte) ([]byte, error)
+	// Exists returns whether an issuer is stored under key.
+	Exists(ctx context.Context, key []byte) (bool, error)
+	// List returns the keys of all the stored issuers.
+	List(ctx context.Context) ([][]byte, error)
+}
+
+// LeafIndexReader maps Merkle leaf hashes to their index in a log.
+type LeafIndexReader interface {
+	// LeafIndex returns the index of the entry with the given Merkle leaf
+	// hash, or an error wrapping os.ErrNotExist if there is no such entry.
+	LeafIndex(ctx context.Context, leafHash []byte) (uint64, error)
 }
 
 // CTStorage implements ct.Storage and tessera.LogReader.
 type CTStorage struct {
-	storeData     func(context.Context, *ctonly.Entry) tessera.IndexFuture
-	storeIssuers  func(context.Context, []KV) error
+	storeData    func(context.Context, *ctonly.Entry) tessera.IndexFuture
+	storeIssuers func(context.Context, []KV) error
+	issuers      IssuerStorage
+	// issuerKeys lists the issuers stored when the CTStorage was created.
+	issuerKeys *issuerListing
+	leafIndex  LeafIndexReader
+	// stopLeafIndex stops following the log with leafIndex, and closes it.
+	stopLeafIndex func() error
 	reader        tessera.LogReader
 	awaiter       *tessera.PublicationAwaiter
 	enableAwaiter bool
+	// shutdown is the shutdown function of the Tessera appender.
+	shutdown func(context.Context) error
+	// stopped is set once Shutdown has been called.
+	stopped atomic.Bool
+	// timestamps caches entry timestamps, to answer duplicate submissions.
+	timestamps *timestampCache
 }
 
 // NewCTStorage instantiates a CTStorage object.
-func NewCTStorage(ctx context.Context, logStorage *tessera.Appender, issuerStorage IssuerStorage, reader tessera.LogReader, enableAwaiter bool) (*CTStorage, error) {
+//
+// shutdown is the shutdown function returned by tessera.NewAppender along with
+// logStorage.
+func NewCTStorage(ctx context.Context, logStorage *tessera.Appender, shutdown func(context.Context) error, issuerStorage IssuerStorage, reader tessera.LogReader, enableAwaiter bool) (*CTStorage, error) {
+	if shutdown == nil {
+		return nil, errors.New("nil shutdown function")
+	}
 	awaiter := tessera.NewPublicationAwaiter(ctx, reader.ReadCheckpoint, 200*time.Millisecond)
+	timestamps, err := newTimestampCache(DefaultTimestampCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	issuerKeys := listIssuers(ctx, issuerStorage)
 	ctStorage := &CTStorage{
+		shutdown:      shutdown,
 		storeData:     tessera.NewCertificateTransparencyAppender(logStorage),
-		storeIssuers:  cachedStoreIssuers(issuerStorage),
+		storeIssuers:  cachedStoreIssuers(ctx, issuerStorage, issuerKeys, maxCachedIssuerKeys),
+		issuers:       issuerStorage,
+		issuerKeys:    issuerKeys,
 		reader:        reader,
 		awaiter:       awaiter,
 		enableAwaiter: enableAwaiter,
+		timestamps:    timestamps,
 	}
 	return ctStorage, nil
 }
@@ -81,11 +133,82 @@ func (cts *CTStorage) ReadCheckpoint(ctx context.Context) ([]byte, error) {
 	return cts.reader.ReadCheckpoint(ctx)
 }
 
-// TODO(phbnf): cache timestamps (or more) to avoid reparsing the entire leaf bundle
-func (cts *CTStorage) dedupFuture(ctx context.Context, f tessera.IndexFuture) (index, timestamp uint64, err error) {
-	ctx, span := tracer.Start(ctx, "tesseract.storage.dedupFuture")
+// ReadTile returns the raw bytes of the tile at the given level and index.
+//
+// p is the partial size of the tile, or 0 for a full tile.
+func (cts *CTStorage) ReadTile(ctx context.Context, level, index uint64, p uint8) ([]byte, error) {
+	return cts.reader.ReadTile(ctx, level, index, p)
+}
+
+// ReadEntryBundle returns the raw bytes of the entry bundle at the given index.
+//
+// p is the partial size of the bundle, or 0 for a full bundle.
+func (cts *CTStorage) ReadEntryBundle(ctx context.Context, index uint64, p uint8) ([]byte, error) {
+	return cts.reader.ReadEntryBundle(ctx, index, p)
+}
+
+// ReadIssuer returns the issuer certificate stored under the hex encoded sha256 key.
+func (cts *CTStorage) ReadIssuer(ctx context.Context, key []byte) ([]byte, error) {
+	return cts.issuers.Get(ctx, key)
+}
+
+// ListIssuers returns the hex encoded sha256 keys of the issuer certificates
+// stored when the CTStorage was created.
+//
+// Storage is only listed once, and the keys are shared with the local issuer
+// key cache, which is warmed up with them.
+func (cts *CTStorage) ListIssuers(ctx context.Context) ([][]byte, error) {
+	return cts.issuerKeys.wait(ctx)
+}
+
+// FollowLeafIndex opens the leaf index stored in the Badger database at path,
+// and uses it to look up entries by their Merkle leaf hash. The index follows
+// the log in the background, until the storage is shut down.
+func (cts *CTStorage) FollowLeafIndex(ctx context.Context, path string) error {
+	li, err := leafindex.New(ctx, path, leafindex.Options{})
+	if err != nil {
+		return fmt.Errorf("failed to open leaf index: %v", err)
+	}
+	cts.leafIndex = li
+	cts.stopLeafIndex = li.Start(ctx, cts.reader)
+	return nil
+}
+
+// SetTimestampCacheSize sets the maximum number of entry timestamps cached to
+// answer duplicate submissions, DefaultTimestampCacheSize by default. It must
+// be called before any entry is added.
+func (cts *CTStorage) SetTimestampCacheSize(size int) error {
+	timestamps, err := newTimestampCache(size)
+	if err != nil {
+		return err
+	}
+	cts.timestamps = timestamps
+	return nil
+}
+
+// LeafIndex returns the index of the entry with the given Merkle leaf hash.
+//
+// Returns ErrLeafIndexNotSupported if FollowLeafIndex hasn't been called.
+func (cts *CTStorage) LeafIndex(ctx context.Context, leafHash []byte) (uint64, error) {
+	if cts.leafIndex == nil {
+		return 0, ErrLeafIndexNotSupported
+	}
+	return cts.leafIndex.LeafIndex(ctx, leafHash)
+}
+
+// dedup returns the index and timestamp of the entry that the duplicate
+// entry which got idx from future f is a duplicate of.
+//
+// Timestamps are served from cache if possible. Otherwise, dedup waits for the
+// original entry to be integrated, and reads it back from its entry bundle.
+func (cts *CTStorage) dedup(ctx context.Context, idx tessera.Index, f tessera.IndexFuture) (index, timestamp uint64, err error) {
+	ctx, span := tracer.Start(ctx, "tesseract.storage.dedup")
 	defer span.End()
 
+	if t, ok := cts.timestamps.get(ctx, idx.Index); ok {
+		return idx.Index, t, nil
+	}
+
 	idx, cpRaw, err := cts.awaiter.Await(ctx, f)
 	if err != nil {
 		return 0, 0, fmt.Errorf("error waiting for Tessera index future and its integration: %w", err)
@@ -116,22 +239,51 @@ func (cts *CTStorage) dedupFuture(ctx context.Context, f tessera.IndexFuture) (i
 
 	eIdx := idx.Index % layout.EntryBundleWidth
 	if uint64(len(eb.Entries)) <= eIdx {
-		return 0, 0, fmt.Errorf("entry bundle at index %d has only %d entries, but wanted at least %d", eBIdx, eIdx, eBIdx)
+		return 0, 0, fmt.Errorf("entry bundle at index %d has only %d entries, but wanted at least %d", eBIdx, len(eb.Entries), eIdx+1)
 	}
-	e := staticct.Entry{}
-	t, err := staticct.UnmarshalTimestamp([]byte(eb.Entries[eIdx]))
+	t, err := staticct.UnmarshalTimestamp(eb.Entries[eIdx])
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to extract timestamp from entry %d in entry bundle %d: %v", eIdx, eBIdx, e)
+		return 0, 0, fmt.Errorf("failed to extract timestamp from entry %d in entry bundle %d: %v", eIdx, eBIdx, err)
 	}
+	cts.timestamps.addBundle(eBIdx, &eb)
 
 	return idx.Index, t, nil
 }
 
+// Shutdown stops accepting new entries and issuers, then waits for all the
+// entries that have been assigned an index to be integrated, and for a
+// checkpoint committing to them to be published, or for ctx to be done.
+func (cts *CTStorage) Shutdown(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "tesseract.storage.Shutdown")
+	defer span.End()
+
+	cts.stopped.Store(true)
+	start := time.Now()
+	err := cts.shutdown(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to shut down Tessera appender: %v", err)
+	}
+	// Only stop following the log once everything has been integrated.
+	if cts.stopLeafIndex != nil {
+		if lErr := cts.stopLeafIndex(); lErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close leaf index: %v", lErr))
+		}
+	}
+	shutdownDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(successKey.Bool(err == nil)))
+	return err
+}
+
 // Add stores CT entries.
+//
+// Returns ErrShuttingDown once Shutdown has been called.
 func (cts *CTStorage) Add(ctx context.Context, entry *ctonly.Entry) (uint64, uint64, error) {
 	ctx, span := tracer.Start(ctx, "tesseract.storage.Add")
 	defer span.End()
 
+	if cts.stopped.Load() {
+		return 0, 0, ErrShuttingDown
+	}
+
 	var idx tessera.Index
 	var err error
 	future := cts.storeData(ctx, entry)
@@ -148,19 +300,25 @@ func (cts *CTStorage) Add(ctx context.Context, entry *ctonly.Entry) (uint64, uin
 		}
 	}
 	if idx.IsDup {
-		return cts.dedupFuture(ctx, future)
+		return cts.dedup(ctx, idx, future)
 	}
 
+	cts.timestamps.add(idx.Index, entry.Timestamp)
 	return idx.Index, entry.Timestamp, nil
 }
 
 // AddIssuerChain stores every chain certificate under its sha256.
 //
 // If an object is already stored under this hash, continues.
+// Returns ErrShuttingDown once Shutdown has been called.
 func (cts *CTStorage) AddIssuerChain(ctx context.Context, chain []*x509.Certificate) error {
 	ctx, span := tracer.Start(ctx, "tesseract.storage.AddIssuerChain")
 	defer span.End()
 
+	if cts.stopped.Load() {
+		return ErrShuttingDown
+	}
+
 	kvs := []KV{}
 	for _, c := range chain {
 		id := sha256.Sum256(c.Raw)
@@ -176,17 +334,20 @@ func (cts *CTStorage) AddIssuerChain(ctx context.Context, chain []*x509.Certific
 // cachedStoreIssuers returns a caching wrapper for an IssuerStorage
 //
 // This is intended to make querying faster. It does not keep a copy of the certs, only sha256.
-// Only up to maxCachedIssuerKeys keys will be stored locally.
-func cachedStoreIssuers(s IssuerStorage) func(context.Context, []KV) error {
-	var mu sync.RWMutex
-	m := make(map[string]struct{})
+// Up to size keys are stored locally, the least recently used ones being evicted first.
+//
+// The cache is warmed up in the background with the keys of the issuers s
+// already stores, from listing, until ctx is done.
+func cachedStoreIssuers(ctx context.Context, s IssuerStorage, listing *issuerListing, size int) func(context.Context, []KV) error {
+	m, err := lru.New[string, struct{}](size)
+	if err != nil {
+		klog.Exitf("cachedStoreIssuers wrapper: failed to create issuer cache: %v", err)
+	}
+	go warmIssuerCache(ctx, listing, m, size)
 	return func(ctx context.Context, kv []KV) error {
 		req := []KV{}
 		for _, kv := range kv {
-			mu.RLock()
-			_, ok := m[string(kv.K)]
-			mu.RUnlock()
-			if ok {
+			if m.Contains(string(kv.K)) {
 				klog.V(2).Infof("cachedStoreIssuers wrapper: found %q in local key cache", kv.K)
 				continue
 			}
@@ -196,14 +357,63 @@ func cachedStoreIssuers(s IssuerStorage) func(context.Context, []KV) error {
 			return fmt.Errorf("AddIssuersIfNotExist()s: error storing issuer data in the underlying IssuerStorage: %v", err)
 		}
 		for _, kv := range req {
-			if len(m) >= maxCachedIssuerKeys {
-				klog.V(2).Infof("cachedStoreIssuers wrapper: local issuer cache full, will stop caching issuers.")
-				return nil
-			}
-			mu.Lock()
-			m[string(kv.K)] = struct{}{}
-			mu.Unlock()
+			m.Add(string(kv.K), struct{}{})
 		}
 		return nil
 	}
 }
+
+// issuerListing holds the keys of the issuers stored by an IssuerStorage,
+// listed once in the background.
+type issuerListing struct {
+	// done is closed once keys and err are set.
+	done chan struct{}
+	keys [][]byte
+	err  error
+}
+
+// listIssuers starts listing the issuers stored by s, until ctx is done.
+func listIssuers(ctx context.Context, s IssuerStorage) *issuerListing {
+	l := &issuerListing{done: make(chan struct{})}
+	go func() {
+		defer close(l.done)
+		start := time.Now()
+		l.keys, l.err = s.List(ctx)
+		if l.err == nil {
+			klog.Infof("Listed %d stored issuers in %s", len(l.keys), time.Since(start))
+		}
+	}()
+	return l
+}
+
+// wait returns the listed keys once the listing is done, or an error if ctx
+// is done first.
+func (l *issuerListing) wait(ctx context.Context) ([][]byte, error) {
+	select {
+	case <-l.done:
+		return l.keys, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// warmIssuerCache adds the keys of the issuers in listing to m, as long as
+// it holds less than size keys.
+//
+// Keys which are already cached are left untouched, so that they are not
+// evicted in favour of listed ones.
+func warmIssuerCache(ctx context.Context, listing *issuerListing, m *lru.Cache[string, struct{}], size int) {
+	start := time.Now()
+	keys, err := listing.wait(ctx)
+	if err != nil {
+		klog.Warningf("cachedStoreIssuers wrapper: failed to list issuers to warm up the local key cache: %v", err)
+		return
+	}
+	for _, k := range keys {
+		if m.Len() >= size {
+			break
+		}
+		m.ContainsOrAdd(string(k), struct{}{})
+	}
+	klog.Infof("cachedStoreIssuers wrapper: warmed up the local key cache with %d of %d listed issuers in %s", m.Len(), len(keys), time.Since(start))
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tesseract/storage/leafindex"
+)
+
+// fakeIssuerStorage records the keys it is asked to store, and lists a fixed
+// set of keys.
+type fakeIssuerStorage struct {
+	mu      sync.Mutex
+	added   []string
+	listed  [][]byte
+	listErr error
+	// lists counts the calls to List.
+	lists atomic.Int32
+}
+
+func (s *fakeIssuerStorage) AddIssuersIfNotExist(_ context.Context, kv []KV) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, kv := range kv {
+		s.added = append(s.added, string(kv.K))
+	}
+	return nil
+}
+
+func (s *fakeIssuerStorage) addedKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.added...)
+}
+
+func (s *fakeIssuerStorage) Get(_ context.Context, key []byte) ([]byte, error) {
+	return nil, fmt.Errorf("issuer %q: %w", key, os.ErrNotExist)
+}
+
+func (s *fakeIssuerStorage) Exists(_ context.Context, _ []byte) (bool, error) {
+	return false, nil
+}
+
+func (s *fakeIssuerStorage) List(_ context.Context) ([][]byte, error) {
+	s.lists.Add(1)
+	return s.listed, s.listErr
+}
+
+func kvs(keys ...string) []KV {
+	r := []KV{}
+	for _, k := range keys {
+		r = append(r, KV{K: []byte(k), V: []byte(k + " data")})
+	}
+	return r
+}
+
+func TestCachedStoreIssuers(t *testing.T) {
+	ctx := t.Context()
+	s := &fakeIssuerStorage{}
+	store := cachedStoreIssuers(ctx, s, listIssuers(ctx, s), 2)
+
+	for _, keys := range [][]string{
+		{"a", "b"},
+		// Both are cached.
+		{"a", "b"},
+		// a gets evicted.
+		{"c"},
+		{"a", "c"},
+	} {
+		if err := store(ctx, kvs(keys...)); err != nil {
+			t.Fatalf("store(%q): %v", keys, err)
+		}
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c", "a"}, s.addedKeys()); diff != "" {
+		t.Errorf("Stored keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWarmIssuerCache(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		cached  []string
+		listed  []string
+		listErr error
+		size    int
+		want    []string
+	}{
+		{
+			desc:   "all-listed",
+			listed: []string{"a", "b"},
+			size:   10,
+			want:   []string{"a", "b"},
+		},
+		{
+			desc:   "cache-full",
+			cached: []string{"c"},
+			listed: []string{"a", "b", "d"},
+			size:   2,
+			want:   []string{"c", "a"},
+		},
+		{
+			desc:   "already-cached",
+			cached: []string{"b"},
+			listed: []string{"a", "b"},
+			size:   10,
+			want:   []string{"b", "a"},
+		},
+		{
+			desc:    "list-error",
+			cached:  []string{"c"},
+			listed:  []string{"a", "b"},
+			listErr: errors.New("boom"),
+			size:    10,
+			want:    []string{"c"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			m, err := lru.New[string, struct{}](tc.size)
+			if err != nil {
+				t.Fatalf("lru.New(): %v", err)
+			}
+			for _, k := range tc.cached {
+				m.Add(k, struct{}{})
+			}
+			s := &fakeIssuerStorage{listErr: tc.listErr}
+			for _, k := range tc.listed {
+				s.listed = append(s.listed, []byte(k))
+			}
+
+			warmIssuerCache(t.Context(), listIssuers(t.Context(), s), m, tc.size)
+			if diff := cmp.Diff(tc.want, m.Keys()); diff != "" {
+				t.Errorf("Cached keys mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListIssuersOnce(t *testing.T) {
+	ctx := t.Context()
+	s := &fakeIssuerStorage{listed: [][]byte{[]byte("a"), []byte("b")}}
+	listing := listIssuers(ctx, s)
+	cachedStoreIssuers(ctx, s, listing, 10)
+	cts := &CTStorage{issuers: s, issuerKeys: listing}
+
+	for range 2 {
+		keys, err := cts.ListIssuers(ctx)
+		if err != nil {
+			t.Fatalf("ListIssuers(): %v", err)
+		}
+		if diff := cmp.Diff(s.listed, keys); diff != "" {
+			t.Errorf("Listed keys mismatch (-want +got):\n%s", diff)
+		}
+	}
+	// The issuer key cache and ListIssuers share a single listing.
+	if got, want := s.lists.Load(), int32(1); got != want {
+		t.Errorf("List() called %d times, want %d", got, want)
+	}
+}
+
+// emptyLogReader reads an empty log.
+type emptyLogReader struct {
+	tessera.LogReader
+}
+
+func (emptyLogReader) IntegratedSize(_ context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func TestShutdownClosesLeafIndex(t *testing.T) {
+	path := t.TempDir()
+	appenderDone := false
+	cts := &CTStorage{
+		reader: emptyLogReader{},
+		shutdown: func(context.Context) error {
+			appenderDone = true
+			return nil
+		},
+	}
+	if err := cts.FollowLeafIndex(t.Context(), path); err != nil {
+		t.Fatalf("FollowLeafIndex(): %v", err)
+	}
+	if err := cts.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown(): %v", err)
+	}
+	if !appenderDone {
+		t.Error("Shutdown() didn't shut down the appender")
+	}
+	// The database can only be opened again once it has been closed.
+	li, err := leafindex.New(t.Context(), path, leafindex.Options{})
+	if err != nil {
+		t.Fatalf("leafindex.New() after Shutdown(): %v", err)
+	}
+	if err := li.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+}
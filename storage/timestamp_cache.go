@@ -0,0 +1,74 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tesseract/internal/types/staticct"
+	"go.opentelemetry.io/otel/metric"
+	"k8s.io/klog/v2"
+)
+
+// DefaultTimestampCacheSize is the default maximum number of entry timestamps
+// cached to answer duplicate submissions. Each cached timestamp takes about a
+// hundred bytes.
+const DefaultTimestampCacheSize = 64 << 10
+
+// timestampCache is a bounded cache of entry timestamps, keyed by entry index.
+//
+// It saves reading and parsing entry bundles to find the timestamp of the
+// original entry when a duplicate is submitted.
+type timestampCache struct {
+	c *lru.Cache[uint64, uint64]
+}
+
+// newTimestampCache returns a cache holding up to size timestamps.
+func newTimestampCache(size int) (*timestampCache, error) {
+	c, err := lru.New[uint64, uint64](size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create timestamp cache: %v", err)
+	}
+	return &timestampCache{c: c}, nil
+}
+
+// get returns the timestamp of the entry at index idx, if cached.
+func (tc *timestampCache) get(ctx context.Context, idx uint64) (uint64, bool) {
+	t, ok := tc.c.Get(idx)
+	timestampCacheLookups.Add(ctx, 1, metric.WithAttributes(hitKey.Bool(ok)))
+	return t, ok
+}
+
+// add caches the timestamp t of the entry at index idx.
+func (tc *timestampCache) add(idx, t uint64) {
+	tc.c.Add(idx, t)
+}
+
+// addBundle caches the timestamps of all the entries in the entry bundle at
+// index eBIdx, since duplicates of entries sequenced together tend to be
+// submitted together.
+func (tc *timestampCache) addBundle(eBIdx uint64, eb *staticct.EntryBundle) {
+	for i, e := range eb.Entries {
+		t, err := staticct.UnmarshalTimestamp(e)
+		if err != nil {
+			klog.V(2).Infof("timestampCache: failed to extract timestamp from entry %d in entry bundle %d: %v", i, eBIdx, err)
+			continue
+		}
+		tc.add(eBIdx*layout.EntryBundleWidth+uint64(i), t)
+	}
+}
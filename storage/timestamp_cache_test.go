@@ -0,0 +1,78 @@
+// Copyright 2025 The Tessera authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tesseract/internal/types/staticct"
+)
+
+func TestTimestampCache(t *testing.T) {
+	ctx := t.Context()
+	tc, err := newTimestampCache(2)
+	if err != nil {
+		t.Fatalf("newTimestampCache(): %v", err)
+	}
+
+	if _, ok := tc.get(ctx, 1); ok {
+		t.Error("get(1) hit on an empty cache")
+	}
+	tc.add(1, 100)
+	tc.add(2, 200)
+	if got, ok := tc.get(ctx, 1); !ok || got != 100 {
+		t.Errorf("get(1)=%d, %t, want 100, true", got, ok)
+	}
+	// 2 is the least recently used entry, and gets evicted.
+	tc.add(3, 300)
+	if _, ok := tc.get(ctx, 2); ok {
+		t.Error("get(2) hit, want it evicted")
+	}
+	if got, ok := tc.get(ctx, 3); !ok || got != 300 {
+		t.Errorf("get(3)=%d, %t, want 300, true", got, ok)
+	}
+
+	if _, err := newTimestampCache(0); err == nil {
+		t.Error("newTimestampCache(0)=nil, want err")
+	}
+}
+
+func TestTimestampCacheAddBundle(t *testing.T) {
+	ctx := t.Context()
+	tc, err := newTimestampCache(10)
+	if err != nil {
+		t.Fatalf("newTimestampCache(): %v", err)
+	}
+
+	// Entries start with their timestamp, the rest doesn't matter here.
+	eb := &staticct.EntryBundle{}
+	for _, ts := range []uint64{10, 20, 30} {
+		eb.Entries = append(eb.Entries, binary.BigEndian.AppendUint64(nil, ts))
+	}
+	eb.Entries = append(eb.Entries, []byte("short"))
+	tc.addBundle(2, eb)
+
+	base := uint64(2 * layout.EntryBundleWidth)
+	for i, want := range []uint64{10, 20, 30} {
+		if got, ok := tc.get(ctx, base+uint64(i)); !ok || got != want {
+			t.Errorf("get(%d)=%d, %t, want %d, true", base+uint64(i), got, ok, want)
+		}
+	}
+	if _, ok := tc.get(ctx, base+3); ok {
+		t.Error("Entry with an invalid timestamp was cached")
+	}
+}